@@ -0,0 +1,156 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/book"
+	"github.com/infastin/gomoku2go/internal/gomoku/record"
+)
+
+func runBook(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: book build|merge [arguments]")
+	}
+
+	switch args[0] {
+	case "build":
+		return buildBook(args[1:])
+	case "merge":
+		return mergeBooks(args[1:])
+	}
+
+	return fmt.Errorf("unknown book command %q", args[0])
+}
+
+func defaultBookPath() string {
+	path, err := book.DefaultPath()
+	if err != nil {
+		return "book.json"
+	}
+
+	return path
+}
+
+// buildBook learns the openings of the recorded games. Games played on
+// another board than the first one and games seen before, maybe turned,
+// are skipped.
+func buildBook(args []string) error {
+	fs := flag.NewFlagSet("book build", flag.ExitOnError)
+	out := fs.String("o", defaultBookPath(), "write the book to `path`")
+	plies := fs.Int("plies", book.DefaultPlies, "learn the first `n` moves of every game")
+	add := fs.Bool("add", false, "add the games to the book at the output path")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gomoku2go-tools book build [flags] records...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if *plies <= 0 {
+		return fmt.Errorf("the number of moves to learn must be positive")
+	}
+
+	var b *book.Book
+
+	if *add {
+		var err error
+		if b, err = book.Load(*out); err != nil {
+			return err
+		}
+	}
+
+	games := 0
+	seen := make(map[string]string)
+
+	for _, path := range fs.Args() {
+		r, err := record.Load(path)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		if b == nil {
+			if b, err = book.New(r.Size, r.WinCond); err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+		}
+
+		if r.Size != b.Size() || r.WinCond != b.WinCond() {
+			fmt.Fprintf(os.Stderr, "%s: skipped, the game is played on a %dx%d board with %d in a row\n",
+				path, r.Size, r.Size, r.WinCond)
+			continue
+		}
+
+		key, err := r.Key()
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		if first, ok := seen[key]; ok {
+			fmt.Fprintf(os.Stderr, "%s: skipped, the same game as %s\n", path, first)
+			continue
+		}
+
+		seen[key] = path
+
+		if err := b.AddRecord(r, *plies); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		games++
+	}
+
+	if err := b.Save(*out); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d games, %d positions\n", *out, games, b.Len())
+
+	return nil
+}
+
+func mergeBooks(args []string) error {
+	fs := flag.NewFlagSet("book merge", flag.ExitOnError)
+	out := fs.String("o", defaultBookPath(), "write the book to `path`")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gomoku2go-tools book merge [flags] books...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	var b *book.Book
+
+	for _, path := range fs.Args() {
+		o, err := book.Load(path)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		if b == nil {
+			b = o
+			continue
+		}
+
+		if err := b.Merge(o); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+
+	if err := b.Save(*out); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d positions\n", *out, b.Len())
+
+	return nil
+}
@@ -0,0 +1,69 @@
+// Command gomoku2go-tools holds the tools working with gomoku2go files.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{
+		name:  "book",
+		usage: "build or merge opening books",
+		run:   runBook,
+	},
+	{
+		name:  "puzzle",
+		usage: "check or generate puzzles",
+		run:   runPuzzle,
+	},
+	{
+		name:  "selfplay",
+		usage: "play training games of the engine against itself",
+		run:   runSelfPlay,
+	},
+	{
+		name:  "tune",
+		usage: "tune the evaluation on training games",
+		run:   runTune,
+	},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: gomoku2go-tools <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != os.Args[1] {
+			continue
+		}
+
+		if err := cmd.run(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, "gomoku2go-tools:", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	usage()
+	os.Exit(2)
+}
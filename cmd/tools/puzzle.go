@@ -0,0 +1,277 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/engine"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/puzzle"
+	"github.com/infastin/gomoku2go/internal/gomoku/record"
+)
+
+func runPuzzle(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: puzzle check|generate [arguments]")
+	}
+
+	switch args[0] {
+	case "check":
+		return checkPuzzles(args[1:])
+	case "generate":
+		return generatePuzzles(args[1:])
+	}
+
+	return fmt.Errorf("unknown puzzle command %q", args[0])
+}
+
+// checkPuzzles validates every puzzle of the files with the solver, or the
+// bundled puzzles if no file is given.
+func checkPuzzles(args []string) error {
+	fs := flag.NewFlagSet("puzzle check", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gomoku2go-tools puzzle check [puzzle files...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	type source struct {
+		name    string
+		puzzles []puzzle.Puzzle
+	}
+
+	var sources []source
+
+	if fs.NArg() == 0 {
+		puzzles, err := puzzle.Bundled()
+		if err != nil {
+			return err
+		}
+
+		sources = append(sources, source{"bundled puzzles", puzzles})
+	}
+
+	for _, path := range fs.Args() {
+		puzzles, err := puzzle.Load(path)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		sources = append(sources, source{path, puzzles})
+	}
+
+	failed := 0
+
+	for _, src := range sources {
+		for i := range src.puzzles {
+			if err := src.puzzles[i].Validate(); err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", src.name, err)
+				failed++
+			}
+		}
+
+		fmt.Printf("%s: %d puzzles checked\n", src.name, len(src.puzzles))
+	}
+
+	if failed != 0 {
+		return fmt.Errorf("%d puzzles are invalid", failed)
+	}
+
+	return nil
+}
+
+// puzzleMiner collects the puzzles found in the positions of games, each
+// position once up to a symmetry of the board.
+type puzzleMiner struct {
+	puzzles []puzzle.Puzzle
+	seen    map[string]bool
+	max     int
+}
+
+func positionKey(g *game.Game) string {
+	return fmt.Sprintf("%d/%d/%016x", g.Size(), g.WinCond(), g.Bitboard().CanonicalHash())
+}
+
+func (m *puzzleMiner) full() bool {
+	return m.max > 0 && len(m.puzzles) >= m.max
+}
+
+// mine looks for puzzles in every position of the game, titling them after
+// the game.
+func (m *puzzleMiner) mine(g *game.Game, title string) error {
+	moves := g.Moves()
+
+	pos, err := game.NewGame(g.Player(game.FirstPlayer), g.Player(game.SecondPlayer), g.Size(), g.WinCond())
+	if err != nil {
+		return err
+	}
+
+	for i := range moves {
+		if m.full() {
+			break
+		}
+
+		if i != 0 {
+			if err := pos.MakeMove(moves[i-1].X, moves[i-1].Y); err != nil {
+				return err
+			}
+		}
+
+		key := positionKey(pos)
+		if m.seen[key] {
+			continue
+		}
+
+		m.seen[key] = true
+
+		p, ok := puzzle.Find(pos)
+		if !ok {
+			continue
+		}
+
+		p.ID = fmt.Sprintf("%s-%s", p.Goal, strings.ReplaceAll(key, "/", "-"))
+		p.Title = fmt.Sprintf("%s, move %d", title, i+1)
+
+		if err := p.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: skipped, %v\n", p.Title, err)
+			continue
+		}
+
+		m.puzzles = append(m.puzzles, p)
+		fmt.Fprintf(os.Stderr, "%s: %s, rated %d\n", p.Title, p.Description(), p.Rating)
+	}
+
+	return nil
+}
+
+// selfPlay plays a game of the engine against itself from a random opening
+// of a few moves around the center.
+func selfPlay(e *engine.Engine, rng *rand.Rand, size, winCond uint, lvl int) (*game.Game, error) {
+	g, err := game.NewGame(game.NewPlayer("Engine"), game.NewPlayer("Engine"), size, winCond)
+	if err != nil {
+		return nil, err
+	}
+
+	randomOpening(g, rng, 1+rng.Intn(4))
+
+	for g.State() == game.NotFinished {
+		m, err := e.Play(context.Background(), g, lvl, engine.TimeLeft{})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := g.MakeMove(m.X, m.Y); err != nil {
+			return nil, err
+		}
+	}
+
+	return g, nil
+}
+
+// generatePuzzles mines recorded games and games of the engine against
+// itself for positions with a single winning first move.
+func generatePuzzles(args []string) error {
+	fs := flag.NewFlagSet("puzzle generate", flag.ExitOnError)
+	out := fs.String("o", "puzzles.json", "write the puzzles to `path`")
+	add := fs.Bool("add", false, "add the puzzles to the puzzle file at the output path")
+	games := fs.Int("games", 0, "play `n` games of the engine against itself")
+	level := fs.Int("level", 6, "the difficulty `level` of the engine")
+	size := fs.Uint("size", 15, "the board `size` of the games of the engine")
+	winCond := fs.Uint("wincond", 5, "the number of stones in a row `n` winning the games of the engine")
+	max := fs.Int("max", 0, "stop after `n` puzzles, no limit if 0")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "the `seed` of the random openings")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gomoku2go-tools puzzle generate [flags] [records...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 && *games <= 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	if err := engine.CheckLevel(*level); err != nil {
+		return err
+	}
+
+	m := &puzzleMiner{seen: make(map[string]bool), max: *max}
+
+	var existing []puzzle.Puzzle
+
+	if *add {
+		var err error
+		if existing, err = puzzle.Load(*out); err != nil {
+			return err
+		}
+
+		for i := range existing {
+			g, err := existing[i].Game(game.NewPlayer("o"), game.NewPlayer("x"))
+			if err != nil {
+				return err
+			}
+
+			m.seen[positionKey(g)] = true
+		}
+	}
+
+	for _, path := range fs.Args() {
+		if m.full() {
+			break
+		}
+
+		r, err := record.Load(path)
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		g, err := r.Game()
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+
+		if err := m.mine(g, filepath.Base(path)); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+	}
+
+	if *games > 0 {
+		e, err := engine.New(engine.DefaultTableSize)
+		if err != nil {
+			return err
+		}
+
+		rng := rand.New(rand.NewSource(*seed))
+
+		for i := 0; i < *games && !m.full(); i++ {
+			g, err := selfPlay(e, rng, *size, *winCond, *level)
+			if err != nil {
+				return err
+			}
+
+			if err := m.mine(g, fmt.Sprintf("Self-play game %d", i+1)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The easiest puzzles come first.
+	sort.SliceStable(m.puzzles, func(i, j int) bool {
+		return m.puzzles[i].Rating < m.puzzles[j].Rating
+	})
+
+	if err := puzzle.Save(*out, append(existing, m.puzzles...)); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d new puzzles\n", *out, len(m.puzzles))
+
+	return nil
+}
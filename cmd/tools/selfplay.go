@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/engine"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// trainingGame is a line of a training file: a game of the engine against
+// itself with the search values of its moves.
+type trainingGame struct {
+	Size    uint `json:"size"`
+	WinCond uint `json:"wincond"`
+
+	// Opening is the number of random moves the game starts with.
+	Opening int `json:"opening"`
+
+	// Moves are the moves of the game as x and y.
+	Moves [][2]uint `json:"moves"`
+
+	// Scores are the search scores of the moves after the opening, for the
+	// player making the move.
+	Scores []int `json:"scores"`
+
+	// Result is 1 if the first player won, -1 if the second one did and 0
+	// for a draw.
+	Result int `json:"result"`
+}
+
+// randomOpening plays n random moves around the center of the board.
+func randomOpening(g *game.Game, rng *rand.Rand, n int) {
+	c := int(g.Size() / 2)
+
+	for len(g.Moves()) < n && g.State() == game.NotFinished {
+		x := c + rng.Intn(5) - 2
+		y := c + rng.Intn(5) - 2
+
+		if x < 0 || y < 0 || x >= int(g.Size()) || y >= int(g.Size()) {
+			continue
+		}
+
+		if ft, _ := g.Field(uint(x), uint(y)); ft == game.EmptyField {
+			g.MakeMove(uint(x), uint(y))
+		}
+	}
+}
+
+// playTrainingGame plays a game of the engine against itself, searching every
+// move after the random opening within the limits.
+func playTrainingGame(e *engine.Engine, rng *rand.Rand, size, winCond uint, opening int, limits engine.Limits) (*trainingGame, error) {
+	g, err := game.NewGame(game.NewPlayer("Engine"), game.NewPlayer("Engine"), size, winCond)
+	if err != nil {
+		return nil, err
+	}
+
+	randomOpening(g, rng, opening)
+
+	tg := &trainingGame{
+		Size:    size,
+		WinCond: winCond,
+		Opening: len(g.Moves()),
+	}
+
+	for g.State() == game.NotFinished {
+		res, err := e.Search(context.Background(), g, limits, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		best, ok := res.Best()
+		if !ok {
+			return nil, fmt.Errorf("there are no moves")
+		}
+
+		if err := g.MakeMove(best.Move.X, best.Move.Y); err != nil {
+			return nil, err
+		}
+
+		tg.Scores = append(tg.Scores, best.Score)
+	}
+
+	for _, f := range g.Moves() {
+		tg.Moves = append(tg.Moves, [2]uint{f.X, f.Y})
+	}
+
+	switch g.State() {
+	case game.FirstPlayerWin:
+		tg.Result = 1
+	case game.SecondPlayerWin:
+		tg.Result = -1
+	}
+
+	return tg, nil
+}
+
+// readTrainingGames calls fn with every game of the training file.
+func readTrainingGames(path string, fn func(tg *trainingGame) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+
+	for n := 1; ; n++ {
+		var tg trainingGame
+
+		if err := dec.Decode(&tg); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("%s: game %d: %v", path, n, err)
+		}
+
+		if len(tg.Scores) != len(tg.Moves)-tg.Opening {
+			return fmt.Errorf("%s: game %d: the scores do not match the moves", path, n)
+		}
+
+		if err := fn(&tg); err != nil {
+			return fmt.Errorf("%s: game %d: %v", path, n, err)
+		}
+	}
+}
+
+// runSelfPlay plays games of the engine against itself from random openings
+// and writes them, one JSON object per line, for tuning the evaluation.
+func runSelfPlay(args []string) error {
+	fs := flag.NewFlagSet("selfplay", flag.ExitOnError)
+	out := fs.String("o", "selfplay.jsonl", "write the games to `path`")
+	add := fs.Bool("add", false, "add the games to the file at the output path")
+	games := fs.Int("games", 100, "play `n` games")
+	depth := fs.Int("depth", 4, "search every move to `depth` plies")
+	nodes := fs.Int("nodes", 0, "search at most `n` nodes per move, no limit if 0")
+	size := fs.Uint("size", 15, "the board `size`")
+	winCond := fs.Uint("wincond", 5, "the number of stones in a row `n` winning the games")
+	opening := fs.Int("opening", 4, "start the games with `n` random moves")
+	threads := fs.Int("threads", 1, "search on `n` threads")
+	seed := fs.Int64("seed", time.Now().UnixNano(), "the `seed` of the random openings")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gomoku2go-tools selfplay [flags]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if *games <= 0 || *depth <= 0 || *depth > engine.MaxDepth {
+		return fmt.Errorf("the number of games and the depth must be positive, the depth at most %d", engine.MaxDepth)
+	}
+
+	if err := game.CheckSettings(game.NewPlayer("Engine"), game.NewPlayer("Engine"), *size, *winCond); err != nil {
+		return err
+	}
+
+	e, err := engine.New(engine.DefaultTableSize)
+	if err != nil {
+		return err
+	}
+
+	if err := e.SetThreads(*threads); err != nil {
+		return err
+	}
+
+	e.Seed(*seed)
+	rng := rand.New(rand.NewSource(*seed))
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if *add {
+		flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	f, err := os.OpenFile(*out, flags, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+
+	limits := engine.Limits{Depth: *depth, Nodes: *nodes}
+	positions := 0
+	start := time.Now()
+
+	for i := 0; i < *games; i++ {
+		tg, err := playTrainingGame(e, rng, *size, *winCond, *opening, limits)
+		if err != nil {
+			f.Close()
+			return err
+		}
+
+		if err := enc.Encode(tg); err != nil {
+			f.Close()
+			return err
+		}
+
+		positions += len(tg.Scores)
+		fmt.Fprintf(os.Stderr, "game %d: %d moves, result %+d\n", i+1, len(tg.Moves), tg.Result)
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: %d games, %d positions in %v\n", *out, *games, positions, time.Since(start).Round(time.Second))
+
+	return nil
+}
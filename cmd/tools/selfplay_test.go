@@ -0,0 +1,137 @@
+package main
+
+import (
+	"encoding/json"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/engine"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+func TestRandomOpening(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for _, size := range []uint{3, 15} {
+		g, _ := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), size, 3)
+		randomOpening(g, rng, 6)
+
+		if len(g.Moves()) != 6 && g.State() == game.NotFinished {
+			t.Errorf("%dx%d: %d random moves played, want 6", size, size, len(g.Moves()))
+		}
+
+		for _, f := range g.Moves() {
+			if c := size / 2; f.X+2 < c || f.X > c+2 || f.Y+2 < c || f.Y > c+2 {
+				t.Errorf("%dx%d: the random move %d,%d is far from the center", size, size, f.X, f.Y)
+			}
+		}
+	}
+}
+
+func TestSelfPlay(t *testing.T) {
+	e, _ := engine.New(1 << 20)
+	e.Seed(1)
+
+	rng := rand.New(rand.NewSource(1))
+	path := filepath.Join(t.TempDir(), "selfplay.jsonl")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enc := json.NewEncoder(f)
+
+	var played []*trainingGame
+
+	for i := 0; i < 2; i++ {
+		tg, err := playTrainingGame(e, rng, 7, 4, 2, engine.Limits{Depth: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tg.Opening != 2 || len(tg.Scores) != len(tg.Moves)-tg.Opening {
+			t.Fatalf("%d moves after an opening of %d with %d scores", len(tg.Moves), tg.Opening, len(tg.Scores))
+		}
+
+		// The game is over after the last move and the result is its
+		// outcome.
+		g, _ := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 7, 4)
+		for _, m := range tg.Moves {
+			if err := g.MakeMove(m[0], m[1]); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		want := map[game.GameState]int{game.FirstPlayerWin: 1, game.SecondPlayerWin: -1, game.NobodyWins: 0}
+		if r, ok := want[g.State()]; !ok || r != tg.Result {
+			t.Errorf("a game ending with %v has the result %d", g.State(), tg.Result)
+		}
+
+		enc.Encode(tg)
+		played = append(played, tg)
+	}
+
+	f.Close()
+
+	var read []*trainingGame
+
+	err = readTrainingGames(path, func(tg *trainingGame) error {
+		read = append(read, tg)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(read) != len(played) {
+		t.Fatalf("%d games read, %d written", len(read), len(played))
+	}
+
+	for i := range read {
+		if read[i].Result != played[i].Result || len(read[i].Moves) != len(played[i].Moves) {
+			t.Errorf("game %d read differs from the written one", i+1)
+		}
+	}
+
+	ts, err := loadTrainingSet([]string{path}, len(engine.WindowWeights()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ts.features) == 0 || len(ts.features) != len(ts.results) {
+		t.Errorf("%d positions with %d results loaded", len(ts.features), len(ts.results))
+	}
+}
+
+func TestReadTrainingGamesInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{"json", `{"size": 7,`, "game 1"},
+		{"scores", `{"size": 7, "wincond": 4, "opening": 1, "moves": [[3, 3], [2, 2]], "scores": [1, 2]}`, "the scores do not match the moves"},
+		{"moves", `{"size": 7, "wincond": 4, "opening": 0, "moves": [[3, 3], [3, 3]], "scores": [1, 2]}`, "move 2 is not valid"},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "selfplay.jsonl")
+		os.WriteFile(path, []byte(tt.in), 0644)
+
+		_, err := loadTrainingSet([]string{path}, 6)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: the error is %v, want an error with %q", tt.name, err, tt.err)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "empty.jsonl")
+	os.WriteFile(path, nil, 0644)
+
+	if _, err := loadTrainingSet([]string{path}, 6); err == nil {
+		t.Error("tuning on no positions")
+	}
+}
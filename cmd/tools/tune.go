@@ -0,0 +1,239 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/engine"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// trainingSet holds the positions of training games as the differences
+// between the numbers of live windows of the player to move and of the
+// opponent, by the number of stones missing to fill them.
+type trainingSet struct {
+	features [][]int
+
+	// results are the outcomes of the games for the player to move: 1 for a
+	// win, 0.5 for a draw and 0 for a loss.
+	results []float64
+}
+
+// add adds the positions of the game after its opening. Positions the search
+// found won or lost are left out: the evaluation does not decide them.
+func (ts *trainingSet) add(tg *trainingGame, nweights int) error {
+	b, err := game.NewBitboard(tg.Size, tg.WinCond)
+	if err != nil {
+		return err
+	}
+
+	for i, m := range tg.Moves {
+		if b.State() != game.NotFinished {
+			return fmt.Errorf("the game goes on after its end")
+		}
+
+		if m[0] >= tg.Size || m[1] >= tg.Size || !b.IsEmpty(b.Cell(m[0], m[1])) {
+			return fmt.Errorf("move %d is not valid", i+1)
+		}
+
+		if i >= tg.Opening {
+			score := tg.Scores[i-tg.Opening]
+
+			if !engine.IsWin(score) && !engine.IsLoss(score) {
+				ts.features = append(ts.features, windowFeatures(b, nweights))
+				ts.results = append(ts.results, gameResult(tg.Result, b.CurrentPlayer()))
+			}
+		}
+
+		b.Make(b.Cell(m[0], m[1]))
+	}
+
+	return nil
+}
+
+// windowFeatures counts the live windows of the position the way the engine
+// weighs them.
+func windowFeatures(b *game.Bitboard, nweights int) []int {
+	pt := b.CurrentPlayer()
+	res := make([]int, nweights)
+
+	for w := range b.Layout().Windows() {
+		for p := game.FirstPlayer; p <= game.SecondPlayer; p++ {
+			k := b.Count(p, w)
+
+			if k == 0 || b.Count((p+1)%2, w) != 0 {
+				continue
+			}
+
+			missing := int(b.WinCond() - k)
+			if missing >= nweights {
+				missing = nweights - 1
+			}
+
+			if p == pt {
+				res[missing]++
+			} else {
+				res[missing]--
+			}
+		}
+	}
+
+	return res
+}
+
+func gameResult(result int, pt game.PlayerType) float64 {
+	switch {
+	case result == 0:
+		return 0.5
+	case (result > 0) == (pt == game.FirstPlayer):
+		return 1
+	}
+
+	return 0
+}
+
+// loss returns the mean squared error of the predictions of the results by
+// the weights, the evaluation scaled by k turned into a winning chance.
+func (ts *trainingSet) loss(weights []int, k float64) float64 {
+	sum := 0.0
+
+	for i, f := range ts.features {
+		eval := 0
+
+		for j, n := range f {
+			eval += weights[j] * n
+		}
+
+		d := ts.results[i] - 1/(1+math.Exp(-float64(eval)/k))
+		sum += d * d
+	}
+
+	return sum / float64(len(ts.features))
+}
+
+// fitScale finds the scale of the evaluation at which the weights predict
+// the results best.
+func (ts *trainingSet) fitScale(weights []int) float64 {
+	lo, hi := 0.0, math.Log(1e7)
+
+	for hi-lo > 1e-3 {
+		m1 := lo + (hi-lo)/3
+		m2 := hi - (hi-lo)/3
+
+		if ts.loss(weights, math.Exp(m1)) < ts.loss(weights, math.Exp(m2)) {
+			hi = m2
+		} else {
+			lo = m1
+		}
+	}
+
+	return math.Exp((lo + hi) / 2)
+}
+
+// tune improves the weights one at a time, scaling them up and down by a
+// step that halves when none of them gets better.
+func (ts *trainingSet) tune(weights []int, k float64) float64 {
+	best := ts.loss(weights, k)
+
+	for step := 0.5; step >= 0.005; {
+		improved := false
+
+		// Windows that are full are wins, the weight of the first ones does
+		// not count.
+		for j := 1; j < len(weights); j++ {
+			old := weights[j]
+
+			for _, w := range []int{int(math.Round(float64(old) * (1 + step))), int(math.Round(float64(old) / (1 + step)))} {
+				if w < 1 || w == old {
+					continue
+				}
+
+				weights[j] = w
+
+				if l := ts.loss(weights, k); l < best {
+					best = l
+					old = w
+					improved = true
+				}
+			}
+
+			weights[j] = old
+		}
+
+		if !improved {
+			step /= 2
+		}
+	}
+
+	return best
+}
+
+// loadTrainingSet reads the positions of the games of the training files.
+func loadTrainingSet(paths []string, nweights int) (*trainingSet, error) {
+	ts := &trainingSet{}
+
+	for _, path := range paths {
+		err := readTrainingGames(path, func(tg *trainingGame) error {
+			return ts.add(tg, nweights)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(ts.features) == 0 {
+		return nil, fmt.Errorf("there are no positions to tune on")
+	}
+
+	return ts, nil
+}
+
+// weightsSource returns the weights as the Go declaration of the engine.
+func weightsSource(weights []int) string {
+	strs := make([]string, len(weights))
+	for i, w := range weights {
+		strs[i] = fmt.Sprint(w)
+	}
+
+	return fmt.Sprintf("var missingWeights = [...]int{%s}", strings.Join(strs, ", "))
+}
+
+// runTune fits the window weights of the evaluation to the results of
+// training games, like Texel tuning: the weights are changed while the
+// evaluations of the positions predict the results of the games better.
+func runTune(args []string) error {
+	fs := flag.NewFlagSet("tune", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: gomoku2go-tools tune training files...")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	weights := engine.WindowWeights()
+
+	ts, err := loadTrainingSet(fs.Args(), len(weights))
+	if err != nil {
+		return err
+	}
+
+	k := ts.fitScale(weights)
+	before := ts.loss(weights, k)
+
+	fmt.Fprintf(os.Stderr, "%d positions, scale %.1f, error %.6f\n", len(ts.features), k, before)
+
+	after := ts.tune(weights, k)
+
+	fmt.Fprintf(os.Stderr, "tuned error %.6f\n", after)
+
+	fmt.Println(weightsSource(weights))
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/engine"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+func TestLoss(t *testing.T) {
+	ts := &trainingSet{
+		features: [][]int{{0, 1}, {0, -1}},
+		results:  []float64{1, 0},
+	}
+
+	// Both evaluations are off by the same chance.
+	d := 1 - 1/(1+math.Exp(-1))
+
+	if l := ts.loss([]int{0, 100}, 100); math.Abs(l-d*d) > 1e-12 {
+		t.Errorf("the error is %v, want %v", l, d*d)
+	}
+
+	if l := ts.loss([]int{0, 0}, 100); l != 0.25 {
+		t.Errorf("the error without weights is %v, want 0.25", l)
+	}
+}
+
+func TestFitScale(t *testing.T) {
+	// Three of four games with the same position are won, a chance of 3/4
+	// is an evaluation of 100 at the scale of 100/ln(3).
+	ts := &trainingSet{
+		features: [][]int{{0, 1}, {0, 1}, {0, 1}, {0, 1}},
+		results:  []float64{1, 1, 1, 0},
+	}
+
+	want := 100 / math.Log(3)
+
+	if k := ts.fitScale([]int{0, 100}); math.Abs(k-want)/want > 0.01 {
+		t.Errorf("the scale is %v, want %v", k, want)
+	}
+}
+
+func TestTune(t *testing.T) {
+	// The player with the windows wins 9 of 10 games, which at the scale of
+	// 100 is an evaluation of 100 ln(9), 21 of it from the full windows.
+	ts := &trainingSet{
+		features: [][]int{{3, 1, 0}, {-3, -1, 0}},
+		results:  []float64{0.9, 0.1},
+	}
+
+	weights := []int{7, 10, 50}
+	before := ts.loss(weights, 100)
+	after := ts.tune(weights, 100)
+
+	if after >= before || math.Abs(after-ts.loss(weights, 100)) > 1e-12 {
+		t.Errorf("the error went from %v to %v, the weights give %v", before, after, ts.loss(weights, 100))
+	}
+
+	if want := 100*math.Log(9) - 21; math.Abs(float64(weights[1])-want)/want > 0.02 {
+		t.Errorf("the tuned weight is %d, want about %.0f", weights[1], want)
+	}
+
+	// The weight of full windows and weights of windows never seen stay.
+	if weights[0] != 7 || weights[2] != 50 {
+		t.Errorf("the weights %v changed without a reason", weights)
+	}
+}
+
+func TestWindowFeatures(t *testing.T) {
+	b, _ := game.NewBitboard(5, 5)
+
+	// The windows through the center are a row, a column and two
+	// diagonals.
+	b.Make(b.Cell(2, 2))
+
+	if f := windowFeatures(b, 6); f[4] != -4 || f[0]+f[1]+f[2]+f[3]+f[5] != 0 {
+		t.Errorf("the features after the first move are %v", f)
+	}
+
+	// The second player shares the diagonal with the first one.
+	b.Make(b.Cell(0, 0))
+
+	if f := windowFeatures(b, 6); f[4] != 1 {
+		t.Errorf("the features after the second move are %v", f)
+	}
+
+	// Windows missing more stones than there are weights count as the last
+	// weight.
+	if f := windowFeatures(b, 3); f[2] != 1 {
+		t.Errorf("the features with 3 weights are %v", f)
+	}
+}
+
+func TestGameResult(t *testing.T) {
+	tests := []struct {
+		result int
+		pt     game.PlayerType
+		want   float64
+	}{
+		{1, game.FirstPlayer, 1},
+		{1, game.SecondPlayer, 0},
+		{-1, game.FirstPlayer, 0},
+		{-1, game.SecondPlayer, 1},
+		{0, game.FirstPlayer, 0.5},
+		{0, game.SecondPlayer, 0.5},
+	}
+
+	for _, tt := range tests {
+		if r := gameResult(tt.result, tt.pt); r != tt.want {
+			t.Errorf("the result %+d for player %d is %v, want %v", tt.result, tt.pt, r, tt.want)
+		}
+	}
+}
+
+func TestTrainingSetAdd(t *testing.T) {
+	tg := &trainingGame{
+		Size:    5,
+		WinCond: 3,
+		Opening: 1,
+		Moves:   [][2]uint{{2, 2}, {0, 0}, {2, 3}, {0, 4}, {2, 1}},
+		// The first player makes three in a row with its third move, the
+		// search finds the last two positions decided.
+		Scores: []int{-50, 300, -engine.WinScore + 2, engine.WinScore - 1},
+		Result: 1,
+	}
+
+	ts := &trainingSet{}
+
+	if err := ts.add(tg, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ts.features) != 2 || len(ts.results) != 2 {
+		t.Fatalf("%d positions added, want 2", len(ts.features))
+	}
+
+	// The second player moves first after the opening.
+	if ts.results[0] != 0 || ts.results[1] != 1 {
+		t.Errorf("the results are %v", ts.results)
+	}
+
+	invalid := []struct {
+		name  string
+		moves [][2]uint
+	}{
+		{"out of bounds", [][2]uint{{2, 2}, {5, 0}, {2, 3}, {0, 4}, {2, 1}}},
+		{"taken", [][2]uint{{2, 2}, {2, 2}, {2, 3}, {0, 4}, {2, 1}}},
+		{"after the end", [][2]uint{{2, 2}, {0, 0}, {2, 3}, {0, 4}, {2, 1}, {4, 4}}},
+	}
+
+	for _, tt := range invalid {
+		bad := *tg
+		bad.Moves = tt.moves
+		bad.Scores = make([]int, len(tt.moves)-bad.Opening)
+
+		if err := ts.add(&bad, 4); err == nil {
+			t.Errorf("%s: the game is added", tt.name)
+		}
+	}
+}
+
+func TestWeightsSource(t *testing.T) {
+	s := weightsSource([]int{0, 5000, 120, 9})
+
+	if want := "var missingWeights = [...]int{0, 5000, 120, 9}"; s != want {
+		t.Errorf("the weights are written as %q, want %q", s, want)
+	}
+}
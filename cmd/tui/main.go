@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/settings"
+	"github.com/infastin/gomoku2go/internal/gomoku/tui"
+)
+
+func main() {
+	s := settings.New()
+
+	client := tui.NewClient(s.NewGame)
+	if err := client.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
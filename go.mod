@@ -5,6 +5,9 @@ go 1.17
 require (
 	github.com/diamondburned/gotk4/pkg v0.0.0-20211006035519-1a3c037dc2f8
 	github.com/imkira/go-observer v1.0.3
+	golang.org/x/net v0.0.0-20211015210444-4f30a5c0130f
+	golang.org/x/sys v0.0.0-20210615035016-665e8c7367d1
+	golang.org/x/term v0.0.0-20210927222741-03fcf44c2211
 )
 
 require (
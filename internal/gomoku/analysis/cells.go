@@ -0,0 +1,107 @@
+package analysis
+
+import "sort"
+
+type threatGroup struct {
+	stones []Cell
+	gains  []Cell
+}
+
+// threatGroups merges the lines made of the same stones.
+type threatGroups []*threatGroup
+
+func (gs *threatGroups) add(stones, gains []Cell) {
+	for _, g := range *gs {
+		if sameCells(g.stones, stones) {
+			g.gains = uniqueCells(append(g.gains, gains...))
+			return
+		}
+	}
+
+	*gs = append(*gs, &threatGroup{
+		stones: sortCells(stones),
+		gains:  uniqueCells(gains),
+	})
+}
+
+func sortCells(cells []Cell) []Cell {
+	res := append([]Cell(nil), cells...)
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].X != res[j].X {
+			return res[i].X < res[j].X
+		}
+
+		return res[i].Y < res[j].Y
+	})
+
+	return res
+}
+
+func uniqueCells(cells []Cell) []Cell {
+	sorted := sortCells(cells)
+	res := sorted[:0]
+
+	for i, c := range sorted {
+		if i == 0 || c != sorted[i-1] {
+			res = append(res, c)
+		}
+	}
+
+	return res
+}
+
+func sameCells(a, b []Cell) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	a, b = sortCells(a), sortCells(b)
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsCell(cells []Cell, c Cell) bool {
+	for _, cc := range cells {
+		if cc == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+func removeCell(cells []Cell, c Cell) []Cell {
+	res := cells[:0]
+
+	for _, cc := range cells {
+		if cc != c {
+			res = append(res, cc)
+		}
+	}
+
+	return res
+}
+
+// contiguous tells whether the cells of a line follow each other without
+// gaps.
+func contiguous(cells []Cell) bool {
+	sorted := sortCells(cells)
+
+	for i := 1; i < len(sorted); i++ {
+		dx := int(sorted[i].X) - int(sorted[i-1].X)
+		dy := int(sorted[i].Y) - int(sorted[i-1].Y)
+
+		if dx < -1 || dx > 1 || dy < -1 || dy > 1 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,100 @@
+// Package analysis finds threats in gomoku positions.
+//
+// Threats are described for any win condition n: a four is a line of n-1
+// stones that one more stone turns into a win, a three is a line of n-2
+// stones that one more stone turns into an open four.
+package analysis
+
+import (
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+type Cell struct {
+	X, Y uint
+}
+
+var directions = [4][2]int{{1, 0}, {0, 1}, {1, 1}, {1, -1}}
+
+// Position is a mutable copy of a board used to try moves out.
+type Position struct {
+	size    int
+	winCond int
+	fields  []game.FieldType
+}
+
+func NewPosition(g *game.Game) *Position {
+	p := newPosition(g.Size(), g.WinCond())
+
+	for _, f := range g.Moves() {
+		p.set(int(f.X), int(f.Y), f.Ft)
+	}
+
+	return p
+}
+
+func PositionFromBitboard(b *game.Bitboard) *Position {
+	p := newPosition(b.Size(), b.WinCond())
+
+	for cell := uint(0); cell < b.Size()*b.Size(); cell++ {
+		x, y := b.Coords(cell)
+		p.set(int(x), int(y), b.Field(cell))
+	}
+
+	return p
+}
+
+func newPosition(size, winCond uint) *Position {
+	return &Position{
+		size:    int(size),
+		winCond: int(winCond),
+		fields:  make([]game.FieldType, size*size),
+	}
+}
+
+func (p *Position) Size() uint {
+	return uint(p.size)
+}
+
+func (p *Position) WinCond() uint {
+	return uint(p.winCond)
+}
+
+func (p *Position) Field(x, y uint) game.FieldType {
+	return p.fields[int(x)*p.size+int(y)]
+}
+
+func (p *Position) inside(x, y int) bool {
+	return x >= 0 && y >= 0 && x < p.size && y < p.size
+}
+
+func (p *Position) get(x, y int) game.FieldType {
+	return p.fields[x*p.size+y]
+}
+
+func (p *Position) set(x, y int, ft game.FieldType) {
+	p.fields[x*p.size+y] = ft
+}
+
+// line returns the cells of the segment starting at (x, y) in the direction,
+// or false if it does not fit on the board.
+func (p *Position) line(x, y int, d [2]int, length int) ([]Cell, bool) {
+	if !p.inside(x, y) || !p.inside(x+d[0]*(length-1), y+d[1]*(length-1)) {
+		return nil, false
+	}
+
+	cells := make([]Cell, length)
+
+	for i := range cells {
+		cells[i] = Cell{X: uint(x + d[0]*i), Y: uint(y + d[1]*i)}
+	}
+
+	return cells, true
+}
+
+func (p *Position) at(c Cell) game.FieldType {
+	return p.get(int(c.X), int(c.Y))
+}
+
+func stone(pt game.PlayerType) game.FieldType {
+	return game.FieldType(pt) + 1
+}
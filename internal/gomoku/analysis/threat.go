@@ -0,0 +1,473 @@
+package analysis
+
+import (
+	"sort"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+type ThreatKind uint
+
+const (
+	// Five is a finished line of n stones.
+	Five ThreatKind = iota
+	// OpenFour is a four with two winning cells, it cannot be stopped.
+	OpenFour
+	// Four is a four with one winning cell.
+	Four
+	// OpenThree is an unbroken three that one more stone turns into an open
+	// four.
+	OpenThree
+	// BrokenThree is a three with a gap that one more stone turns into an
+	// open four.
+	BrokenThree
+	// DoubleFour is a move that makes two fours at once.
+	DoubleFour
+	// FourThree is a move that makes a four and an open three at once.
+	FourThree
+	// DoubleThree is a move that makes two open threes at once.
+	DoubleThree
+)
+
+var threatKindNames = [...]string{
+	Five:        "five",
+	OpenFour:    "open four",
+	Four:        "four",
+	OpenThree:   "open three",
+	BrokenThree: "broken three",
+	DoubleFour:  "double four",
+	FourThree:   "four-three",
+	DoubleThree: "double three",
+}
+
+func (k ThreatKind) String() string {
+	if int(k) < len(threatKindNames) {
+		return threatKindNames[k]
+	}
+
+	return "unknown"
+}
+
+// IsDouble tells whether the threat is a move that makes two threats at once.
+func (k ThreatKind) IsDouble() bool {
+	return k >= DoubleFour
+}
+
+type Threat struct {
+	Kind   ThreatKind
+	Player game.PlayerType
+
+	// Stones are the stones the threat is made of.
+	Stones []Cell
+	// Gains are the cells the player plays to carry the threat out: the
+	// winning cells of a four, the cells turning a three into an open four
+	// and the cell of a double threat.
+	Gains []Cell
+	// Defenses are the cells the opponent can play to stop the threat. They
+	// are empty for a five and an open four, which cannot be stopped.
+	Defenses []Cell
+}
+
+// Threats returns the threats of both players in the position of the game.
+func Threats(g *game.Game) []Threat {
+	p := NewPosition(g)
+
+	return append(p.Threats(game.FirstPlayer), p.Threats(game.SecondPlayer)...)
+}
+
+// Threats returns the threats of the player sorted by kind.
+func (p *Position) Threats(pt game.PlayerType) []Threat {
+	var res []Threat
+
+	res = append(res, p.fives(pt)...)
+	res = append(res, p.fours(pt)...)
+
+	if p.winCond >= 4 {
+		res = append(res, p.threes(pt)...)
+		res = append(res, p.doubles(pt)...)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Kind < res[j].Kind
+	})
+
+	return res
+}
+
+func (p *Position) fives(pt game.PlayerType) []Threat {
+	var res []Threat
+
+	p.windows(p.winCond, func(cells []Cell) {
+		for _, c := range cells {
+			if p.at(c) != stone(pt) {
+				return
+			}
+		}
+
+		res = append(res, Threat{
+			Kind:   Five,
+			Player: pt,
+			Stones: cells,
+		})
+	})
+
+	return res
+}
+
+func (p *Position) fours(pt game.PlayerType) []Threat {
+	var groups threatGroups
+
+	p.windows(p.winCond, func(cells []Cell) {
+		if stones, gains, ok := p.fill(cells, pt, 1); ok {
+			groups.add(stones, gains)
+		}
+	})
+
+	res := make([]Threat, 0, len(groups))
+
+	for _, g := range groups {
+		t := Threat{
+			Kind:   Four,
+			Player: pt,
+			Stones: g.stones,
+			Gains:  g.gains,
+		}
+
+		if len(g.gains) > 1 {
+			t.Kind = OpenFour
+		} else {
+			t.Defenses = g.gains
+		}
+
+		res = append(res, t)
+	}
+
+	return res
+}
+
+func (p *Position) threes(pt game.PlayerType) []Threat {
+	var groups threatGroups
+
+	for i, ft := range p.fields {
+		if ft != game.EmptyField {
+			continue
+		}
+
+		x, y := i/p.size, i%p.size
+
+		for _, d := range directions {
+			for _, stones := range p.openFoursThrough(x, y, d, pt) {
+				groups.add(stones, []Cell{{X: uint(x), Y: uint(y)}})
+			}
+		}
+	}
+
+	res := make([]Threat, 0, len(groups))
+
+	for _, g := range groups {
+		t := Threat{
+			Kind:   OpenThree,
+			Player: pt,
+			Stones: g.stones,
+			Gains:  g.gains,
+		}
+
+		if !contiguous(g.stones) {
+			t.Kind = BrokenThree
+		}
+
+		t.Defenses = p.defenses(pt, p.around(g.stones), func() bool {
+			return p.isThree(pt, g.stones, g.gains)
+		})
+
+		res = append(res, t)
+	}
+
+	return res
+}
+
+// openFoursThrough returns the threes that a stone on the empty cell turns
+// into open fours along the direction.
+func (p *Position) openFoursThrough(x, y int, d [2]int, pt game.PlayerType) [][]Cell {
+	var res [][]Cell
+
+	p.set(x, y, stone(pt))
+	defer p.set(x, y, game.EmptyField)
+
+	for k := 1; k < p.winCond; k++ {
+		cells, ok := p.line(x-d[0]*k, y-d[1]*k, d, p.winCond+1)
+		if !ok {
+			continue
+		}
+
+		if p.at(cells[0]) != game.EmptyField || p.at(cells[p.winCond]) != game.EmptyField {
+			continue
+		}
+
+		full := true
+
+		for _, c := range cells[1:p.winCond] {
+			if p.at(c) != stone(pt) {
+				full = false
+				break
+			}
+		}
+
+		if !full {
+			continue
+		}
+
+		var stones []Cell
+
+		for _, c := range cells[1:p.winCond] {
+			if c.X != uint(x) || c.Y != uint(y) {
+				stones = append(stones, c)
+			}
+		}
+
+		res = append(res, stones)
+	}
+
+	return res
+}
+
+// isThree tells whether one of the gains still turns the stones into an open
+// four.
+func (p *Position) isThree(pt game.PlayerType, stones, gains []Cell) bool {
+	for _, g := range gains {
+		if p.at(g) != game.EmptyField {
+			continue
+		}
+
+		for _, d := range directions {
+			for _, s := range p.openFoursThrough(int(g.X), int(g.Y), d, pt) {
+				if sameCells(s, stones) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+type created struct {
+	fours  int
+	threes int
+	stones []Cell
+}
+
+// creates counts the fours and threes a stone on the empty cell makes, one
+// per direction.
+func (p *Position) creates(x, y int, pt game.PlayerType) created {
+	var res created
+
+	p.set(x, y, stone(pt))
+	defer p.set(x, y, game.EmptyField)
+
+	for _, d := range directions {
+		if stones := p.fourThrough(x, y, d, pt); stones != nil {
+			res.fours++
+			res.stones = append(res.stones, stones...)
+		} else if stones := p.threeThrough(x, y, d, pt); stones != nil {
+			res.threes++
+			res.stones = append(res.stones, stones...)
+		}
+	}
+
+	return res
+}
+
+// fourThrough returns the stones of a four going through the stone on the
+// cell along the direction.
+func (p *Position) fourThrough(x, y int, d [2]int, pt game.PlayerType) []Cell {
+	for k := 0; k < p.winCond; k++ {
+		cells, ok := p.line(x-d[0]*k, y-d[1]*k, d, p.winCond)
+		if !ok {
+			continue
+		}
+
+		if stones, _, ok := p.fill(cells, pt, 1); ok {
+			return stones
+		}
+	}
+
+	return nil
+}
+
+// threeThrough returns the stones of a three going through the stone on the
+// cell along the direction.
+func (p *Position) threeThrough(x, y int, d [2]int, pt game.PlayerType) []Cell {
+	cell := Cell{X: uint(x), Y: uint(y)}
+
+	for k := -p.winCond; k <= p.winCond; k++ {
+		gx, gy := x+d[0]*k, y+d[1]*k
+		if k == 0 || !p.inside(gx, gy) || p.get(gx, gy) != game.EmptyField {
+			continue
+		}
+
+		for _, stones := range p.openFoursThrough(gx, gy, d, pt) {
+			if containsCell(stones, cell) {
+				return stones
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *Position) doubles(pt game.PlayerType) []Threat {
+	var res []Threat
+
+	for i, ft := range p.fields {
+		if ft != game.EmptyField {
+			continue
+		}
+
+		x, y := i/p.size, i%p.size
+		cell := Cell{X: uint(x), Y: uint(y)}
+
+		if p.wins(x, y, pt) {
+			continue
+		}
+
+		kind, ok := p.doubleKind(x, y, pt)
+		if !ok {
+			continue
+		}
+
+		c := p.creates(x, y, pt)
+
+		t := Threat{
+			Kind:   kind,
+			Player: pt,
+			Stones: removeCell(uniqueCells(c.stones), cell),
+			Gains:  []Cell{cell},
+		}
+
+		t.Defenses = p.defenses(pt, p.around([]Cell{cell}), func() bool {
+			if p.get(x, y) != game.EmptyField {
+				return false
+			}
+
+			_, ok := p.doubleKind(x, y, pt)
+			return ok
+		})
+
+		res = append(res, t)
+	}
+
+	return res
+}
+
+func (p *Position) doubleKind(x, y int, pt game.PlayerType) (ThreatKind, bool) {
+	c := p.creates(x, y, pt)
+
+	switch {
+	case c.fours >= 2:
+		return DoubleFour, true
+	case c.fours == 1 && c.threes >= 1:
+		return FourThree, true
+	case c.fours == 0 && c.threes >= 2:
+		return DoubleThree, true
+	}
+
+	return 0, false
+}
+
+func (p *Position) wins(x, y int, pt game.PlayerType) bool {
+	p.set(x, y, stone(pt))
+	defer p.set(x, y, game.EmptyField)
+
+	for _, d := range directions {
+		for k := 0; k < p.winCond; k++ {
+			cells, ok := p.line(x-d[0]*k, y-d[1]*k, d, p.winCond)
+			if !ok {
+				continue
+			}
+
+			if _, _, ok := p.fill(cells, pt, 0); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// defenses returns the empty candidate cells an opponent stone on which makes
+// active false.
+func (p *Position) defenses(pt game.PlayerType, candidates []Cell, active func() bool) []Cell {
+	var res []Cell
+
+	opponent := stone((pt + 1) % 2)
+
+	for _, c := range candidates {
+		if p.at(c) != game.EmptyField {
+			continue
+		}
+
+		p.set(int(c.X), int(c.Y), opponent)
+
+		if !active() {
+			res = append(res, c)
+		}
+
+		p.set(int(c.X), int(c.Y), game.EmptyField)
+	}
+
+	return res
+}
+
+// windows calls f for every line of the given length on the board.
+func (p *Position) windows(length int, f func(cells []Cell)) {
+	for _, d := range directions {
+		for x := 0; x < p.size; x++ {
+			for y := 0; y < p.size; y++ {
+				if cells, ok := p.line(x, y, d, length); ok {
+					f(cells)
+				}
+			}
+		}
+	}
+}
+
+// fill checks that the cells hold only stones of the player and exactly
+// missing empty cells.
+func (p *Position) fill(cells []Cell, pt game.PlayerType, missing int) (stones, empty []Cell, ok bool) {
+	for _, c := range cells {
+		switch p.at(c) {
+		case stone(pt):
+			stones = append(stones, c)
+		case game.EmptyField:
+			empty = append(empty, c)
+		default:
+			return nil, nil, false
+		}
+	}
+
+	return stones, empty, len(empty) == missing
+}
+
+// around returns the cells on the lines through the given cells that are
+// close enough to matter for a threat.
+func (p *Position) around(cells []Cell) []Cell {
+	var res []Cell
+
+	for _, c := range cells {
+		res = append(res, c)
+
+		for _, d := range directions {
+			for k := -p.winCond; k <= p.winCond; k++ {
+				x := int(c.X) + k*d[0]
+				y := int(c.Y) + k*d[1]
+
+				if k != 0 && p.inside(x, y) {
+					res = append(res, Cell{X: uint(x), Y: uint(y)})
+				}
+			}
+		}
+	}
+
+	return uniqueCells(res)
+}
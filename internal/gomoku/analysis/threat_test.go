@@ -0,0 +1,264 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// board builds a position from rows listed from the top: o is a stone of the
+// first player, x of the second one and . an empty cell.
+func board(winCond uint, rows ...string) *Position {
+	p := newPosition(uint(len(rows)), winCond)
+
+	for y, row := range rows {
+		for x, c := range row {
+			switch c {
+			case 'o':
+				p.set(x, y, game.FirstPlayerField)
+			case 'x':
+				p.set(x, y, game.SecondPlayerField)
+			}
+		}
+	}
+
+	return p
+}
+
+// row returns a 9x9 board with the row in the middle.
+func row(middle string) []string {
+	const empty = "........."
+
+	return []string{empty, empty, empty, empty, middle, empty, empty, empty, empty}
+}
+
+func cells(xy ...uint) []Cell {
+	var res []Cell
+
+	for i := 0; i+1 < len(xy); i += 2 {
+		res = append(res, Cell{X: xy[i], Y: xy[i+1]})
+	}
+
+	return res
+}
+
+func equalCells(a, b []Cell) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	a, b = sortCells(a), sortCells(b)
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestThreats(t *testing.T) {
+	e := "........."
+
+	tests := []struct {
+		name string
+		rows []string
+		kind ThreatKind
+
+		// want are all the threats of the kind of the first player.
+		want []Threat
+		// none are kinds of threats the first player must not have.
+		none []ThreatKind
+	}{
+		{
+			name: "five",
+			rows: row("..ooooo.."),
+			kind: Five,
+			want: []Threat{{Stones: cells(2, 4, 3, 4, 4, 4, 5, 4, 6, 4)}},
+		},
+		{
+			name: "open four",
+			rows: row("..oooo..."),
+			kind: OpenFour,
+			want: []Threat{{Stones: cells(2, 4, 3, 4, 4, 4, 5, 4), Gains: cells(1, 4, 6, 4)}},
+			none: []ThreatKind{Four},
+		},
+		{
+			name: "four at the edge",
+			rows: row("oooo....."),
+			kind: Four,
+			want: []Threat{{Stones: cells(0, 4, 1, 4, 2, 4, 3, 4), Gains: cells(4, 4), Defenses: cells(4, 4)}},
+			none: []ThreatKind{OpenFour},
+		},
+		{
+			name: "blocked four",
+			rows: row("xoooo...."),
+			kind: Four,
+			want: []Threat{{Stones: cells(1, 4, 2, 4, 3, 4, 4, 4), Gains: cells(5, 4), Defenses: cells(5, 4)}},
+			none: []ThreatKind{OpenFour},
+		},
+		{
+			name: "broken four",
+			rows: row("..oo.oo.."),
+			kind: Four,
+			want: []Threat{{Stones: cells(2, 4, 3, 4, 5, 4, 6, 4), Gains: cells(4, 4), Defenses: cells(4, 4)}},
+		},
+		{
+			name: "open three",
+			rows: row("..ooo...."),
+			kind: OpenThree,
+			want: []Threat{{Stones: cells(2, 4, 3, 4, 4, 4), Gains: cells(1, 4, 5, 4), Defenses: cells(1, 4, 5, 4)}},
+		},
+		{
+			name: "three next to the edge",
+			rows: row(".ooo....."),
+			kind: OpenThree,
+			want: []Threat{{Stones: cells(1, 4, 2, 4, 3, 4), Gains: cells(4, 4), Defenses: cells(0, 4, 4, 4, 5, 4)}},
+		},
+		{
+			name: "diagonal three",
+			rows: []string{e, e, "..o......", "...o.....", "....o....", e, e, e, e},
+			kind: OpenThree,
+			want: []Threat{{Stones: cells(2, 2, 3, 3, 4, 4), Gains: cells(1, 1, 5, 5), Defenses: cells(1, 1, 5, 5)}},
+		},
+		{
+			name: "blocked three",
+			rows: row("xooo....."),
+			kind: OpenThree,
+			none: []ThreatKind{OpenThree, BrokenThree},
+		},
+		{
+			name: "three at the edge",
+			rows: row("ooo......"),
+			kind: OpenThree,
+			none: []ThreatKind{OpenThree, BrokenThree},
+		},
+		{
+			name: "broken three",
+			rows: row(".oo.o...."),
+			kind: BrokenThree,
+			want: []Threat{{Stones: cells(1, 4, 2, 4, 4, 4), Gains: cells(3, 4), Defenses: cells(0, 4, 3, 4, 5, 4)}},
+			none: []ThreatKind{OpenThree},
+		},
+		{
+			name: "double four",
+			rows: []string{"....x....", "....o....", "....o....", "....o....", "xooo.....", e, e, e, e},
+			kind: DoubleFour,
+			want: []Threat{{
+				Stones:   cells(1, 4, 2, 4, 3, 4, 4, 1, 4, 2, 4, 3),
+				Gains:    cells(4, 4),
+				Defenses: cells(4, 4, 4, 5, 5, 4),
+			}},
+		},
+		{
+			name: "four-three",
+			rows: []string{e, e, "....o....", "....o....", "xooo.....", e, e, e, e},
+			kind: FourThree,
+			want: []Threat{{
+				Stones:   cells(1, 4, 2, 4, 3, 4, 4, 2, 4, 3),
+				Gains:    cells(4, 4),
+				Defenses: cells(4, 1, 4, 4, 4, 5, 5, 4),
+			}},
+		},
+		{
+			name: "double three",
+			rows: []string{e, e, "....o....", "....o....", "..oo.....", e, e, e, e},
+			kind: DoubleThree,
+			want: []Threat{{
+				Stones:   cells(2, 4, 3, 4, 4, 2, 4, 3),
+				Gains:    cells(4, 4),
+				Defenses: cells(1, 4, 4, 1, 4, 4, 4, 5, 5, 4),
+			}},
+		},
+		{
+			name: "double three blocked",
+			rows: []string{e, e, "....o....", "....o....", ".xoo.....", e, e, e, e},
+			kind: DoubleThree,
+			none: []ThreatKind{DoubleThree},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			threats := board(5, tt.rows...).Threats(game.FirstPlayer)
+
+			var got []Threat
+
+			for _, th := range threats {
+				if th.Player != game.FirstPlayer {
+					t.Errorf("threat %v is of player %v", th.Kind, th.Player)
+				}
+
+				for _, k := range tt.none {
+					if th.Kind == k {
+						t.Errorf("unexpected %v of %v", th.Kind, th.Stones)
+					}
+				}
+
+				if th.Kind == tt.kind {
+					got = append(got, th)
+				}
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d threats of kind %v, want %d: %+v", len(got), tt.kind, len(tt.want), got)
+			}
+
+			for i, want := range tt.want {
+				g := got[i]
+
+				if !equalCells(g.Stones, want.Stones) {
+					t.Errorf("stones are %v, want %v", g.Stones, want.Stones)
+				}
+
+				if !equalCells(g.Gains, want.Gains) {
+					t.Errorf("gains are %v, want %v", g.Gains, want.Gains)
+				}
+
+				if !equalCells(g.Defenses, want.Defenses) {
+					t.Errorf("defenses are %v, want %v", g.Defenses, want.Defenses)
+				}
+			}
+		})
+	}
+}
+
+func TestThreatsOfBothPlayers(t *testing.T) {
+	g, err := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 9, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An open three of the first player and a four of the second one at the
+	// bottom edge.
+	for _, m := range [][2]uint{{2, 4}, {0, 8}, {3, 4}, {1, 8}, {4, 4}, {2, 8}, {8, 0}, {3, 8}} {
+		if err := g.MakeMove(m[0], m[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var kinds [2][]ThreatKind
+
+	for _, th := range Threats(g) {
+		kinds[th.Player] = append(kinds[th.Player], th.Kind)
+	}
+
+	if len(kinds[game.FirstPlayer]) != 1 || kinds[game.FirstPlayer][0] != OpenThree {
+		t.Errorf("threats of the first player are %v, want an open three", kinds[game.FirstPlayer])
+	}
+
+	if len(kinds[game.SecondPlayer]) != 1 || kinds[game.SecondPlayer][0] != Four {
+		t.Errorf("threats of the second player are %v, want a four", kinds[game.SecondPlayer])
+	}
+}
+
+func TestNoThreesForThreeInARow(t *testing.T) {
+	p := board(3, "...", ".o.", "...")
+
+	for _, th := range p.Threats(game.FirstPlayer) {
+		if th.Kind >= OpenThree {
+			t.Errorf("unexpected %v with a win condition of 3", th.Kind)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package analysis
+
+import (
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/tt"
+)
+
+const (
+	maxVCFDepth = 40
+	maxVCTDepth = 16
+
+	solverTableSize = 4 << 20
+)
+
+type ForcedWin struct {
+	// Moves alternate between the attacker, who is the player to move, and
+	// the defender, starting and ending with an attacker move. The defender
+	// moves are the longest resistance found.
+	Moves []Cell
+	Found bool
+
+	// Nodes is the number of positions searched. Exhausted tells that the
+	// search ran out of its node budget before it could tell whether there
+	// is a forced win.
+	Nodes     int
+	Exhausted bool
+}
+
+// FindVCF searches for a victory by continuous fours: a win where every move
+// of the attacker makes a four.
+func FindVCF(g *game.Game, budget int) ForcedWin {
+	return solve(g, budget, false)
+}
+
+// FindVCT searches for a victory by continuous threats: a win where every
+// move of the attacker makes a four or a three.
+func FindVCT(g *game.Game, budget int) ForcedWin {
+	return solve(g, budget, true)
+}
+
+func solve(g *game.Game, budget int, threes bool) ForcedWin {
+	if g.State() != game.NotFinished {
+		return ForcedWin{}
+	}
+
+	table, _ := tt.New(solverTableSize, tt.DepthPreferred)
+
+	s := &solver{
+		b:        g.Bitboard(),
+		attacker: g.CurrentPlayer(),
+		budget:   budget,
+		threes:   threes,
+		table:    table,
+	}
+
+	depth := maxVCFDepth
+	if threes {
+		depth = maxVCTDepth
+	}
+
+	moves, ok := s.attack(depth)
+
+	res := ForcedWin{
+		Found:     ok,
+		Nodes:     s.nodes,
+		Exhausted: !ok && s.exhausted,
+	}
+
+	for _, cell := range moves {
+		x, y := s.b.Coords(cell)
+		res.Moves = append(res.Moves, Cell{X: x, Y: y})
+	}
+
+	return res
+}
+
+type solver struct {
+	b        *game.Bitboard
+	attacker game.PlayerType
+	budget   int
+	threes   bool
+	table    *tt.Table
+
+	nodes     int
+	exhausted bool
+}
+
+// attack returns a winning line for the attacker, who is to move.
+func (s *solver) attack(depth int) ([]uint, bool) {
+	a := s.attacker
+	d := (a + 1) % 2
+
+	if w := s.winCells(a); len(w) != 0 {
+		return w[:1], true
+	}
+
+	if depth <= 0 || s.b.State() != game.NotFinished {
+		return nil, false
+	}
+
+	if s.nodes >= s.budget {
+		s.exhausted = true
+		return nil, false
+	}
+
+	s.nodes++
+
+	// A failure holds for every position equivalent under a symmetry.
+	key := s.b.CanonicalHash()
+	if e, ok := s.table.Probe(key); ok && int(e.Depth) >= depth {
+		return nil, false
+	}
+
+	var candidates []uint
+
+	// A four of the defender has to be blocked whatever the block is.
+	if dw := s.winCells(d); len(dw) > 1 {
+		return nil, false
+	} else if len(dw) == 1 {
+		candidates = dw
+	} else {
+		candidates = s.fourMoves(a)
+
+		if s.threes {
+			candidates = append(candidates, s.threeMoves(a)...)
+		}
+	}
+
+	for _, cell := range candidates {
+		s.b.Make(cell)
+		line, ok := s.defend(depth - 1)
+		s.b.Unmake()
+
+		if ok {
+			return append([]uint{cell}, line...), true
+		}
+	}
+
+	if !s.exhausted {
+		s.table.Store(tt.Entry{Key: key, Depth: int8(depth), Bound: tt.Upper, Move: tt.NoMove})
+	}
+
+	return nil, false
+}
+
+// defend returns the longest line the defender, who is to move, can resist
+// with, or false if the defender escapes.
+func (s *solver) defend(depth int) ([]uint, bool) {
+	a := s.attacker
+	d := (a + 1) % 2
+
+	if s.b.State() != game.NotFinished || len(s.winCells(d)) != 0 {
+		return nil, false
+	}
+
+	var replies []uint
+
+	switch aw := s.winCells(a); {
+	case len(aw) > 1:
+		return []uint{aw[0], aw[1]}, true
+	case len(aw) == 1:
+		replies = aw
+	case s.threes:
+		threats := s.threatMoves(a)
+		if len(threats) == 0 {
+			return nil, false
+		}
+
+		replies = append(s.threatDefenses(a, threats), s.fourMoves(d)...)
+
+		// Nothing stops the threats, the defender might as well take one of
+		// them.
+		if len(replies) == 0 {
+			replies = threats[:1]
+		}
+	default:
+		return nil, false
+	}
+
+	var longest []uint
+
+	for _, cell := range replies {
+		s.b.Make(cell)
+		line, ok := s.attack(depth - 1)
+		s.b.Unmake()
+
+		if !ok {
+			return nil, false
+		}
+
+		if longest == nil || len(line)+1 > len(longest) {
+			longest = append([]uint{cell}, line...)
+		}
+	}
+
+	return longest, true
+}
+
+// winCells returns the empty cells on which the player wins.
+func (s *solver) winCells(pt game.PlayerType) []uint {
+	return s.cellsOf(pt, s.b.WinCond()-1, nil)
+}
+
+// fourMoves returns the empty cells on which the player makes a four.
+func (s *solver) fourMoves(pt game.PlayerType) []uint {
+	return s.cellsOf(pt, s.b.WinCond()-2, nil)
+}
+
+// threeMoves returns the empty cells that do not make a four, but on which
+// the player makes a threat to get an open four.
+func (s *solver) threeMoves(pt game.PlayerType) []uint {
+	if s.b.WinCond() < 4 {
+		return nil
+	}
+
+	var res []uint
+
+	fours := make(map[uint]bool)
+	for _, cell := range s.fourMoves(pt) {
+		fours[cell] = true
+	}
+
+	for _, cell := range s.cellsOf(pt, s.b.WinCond()-3, nil) {
+		if fours[cell] {
+			continue
+		}
+
+		s.b.Make(cell)
+		threat := len(s.threatMovesThrough(pt, cell)) != 0
+		s.b.Unmake()
+
+		if threat {
+			res = append(res, cell)
+		}
+	}
+
+	return res
+}
+
+// threatMoves returns the empty cells on which the player gets two winning
+// cells at once.
+func (s *solver) threatMoves(pt game.PlayerType) []uint {
+	var res []uint
+
+	for _, cell := range s.fourMoves(pt) {
+		if s.makesDouble(pt, cell) {
+			res = append(res, cell)
+		}
+	}
+
+	return res
+}
+
+func (s *solver) threatMovesThrough(pt game.PlayerType, through uint) []uint {
+	var res []uint
+
+	for _, cell := range s.cellsOf(pt, s.b.WinCond()-2, s.b.Layout().CellWindows(through)) {
+		if s.makesDouble(pt, cell) {
+			res = append(res, cell)
+		}
+	}
+
+	return res
+}
+
+// makesDouble tells whether a stone of the player on the cell makes two
+// winning cells.
+func (s *solver) makesDouble(pt game.PlayerType, cell uint) bool {
+	b := s.b
+	winCond := b.WinCond()
+	o := (pt + 1) % 2
+
+	first := -1
+
+	for _, w := range b.Layout().CellWindows(cell) {
+		if b.Count(pt, w) != winCond-2 || b.Count(o, w) != 0 {
+			continue
+		}
+
+		for _, c := range b.Layout().Windows()[w].Mask.Cells() {
+			if c == cell || !b.IsEmpty(c) {
+				continue
+			}
+
+			if first < 0 {
+				first = int(c)
+			} else if uint(first) != c {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// threatDefenses returns the empty cells on which an opponent stone leaves
+// the player without the threats.
+func (s *solver) threatDefenses(pt game.PlayerType, threats []uint) []uint {
+	o := (pt + 1) % 2
+
+	seen := make(map[uint]bool)
+	var candidates []uint
+
+	for _, t := range threats {
+		for _, w := range s.b.Layout().CellWindows(t) {
+			if s.b.Count(o, w) != 0 || s.b.Count(pt, w) < s.b.WinCond()-3 {
+				continue
+			}
+
+			for _, c := range s.b.Layout().Windows()[w].Mask.Cells() {
+				if !seen[c] && s.b.IsEmpty(c) {
+					seen[c] = true
+					candidates = append(candidates, c)
+				}
+			}
+		}
+	}
+
+	var res []uint
+
+	for _, c := range candidates {
+		s.b.Make(c)
+		defended := len(s.threatMoves(pt)) == 0
+		s.b.Unmake()
+
+		if defended {
+			res = append(res, c)
+		}
+	}
+
+	return res
+}
+
+// cellsOf returns the empty cells of the windows in which the player has n
+// stones and the opponent none. All windows are looked at if windows is nil.
+func (s *solver) cellsOf(pt game.PlayerType, n uint, windows []int) []uint {
+	b := s.b
+	o := (pt + 1) % 2
+	all := b.Layout().Windows()
+
+	seen := make(map[uint]bool)
+	var res []uint
+
+	visit := func(w int) {
+		if b.Count(pt, w) != n || b.Count(o, w) != 0 {
+			return
+		}
+
+		for _, c := range all[w].Mask.Cells() {
+			if !seen[c] && b.IsEmpty(c) {
+				seen[c] = true
+				res = append(res, c)
+			}
+		}
+	}
+
+	if windows == nil {
+		for w := range all {
+			visit(w)
+		}
+	} else {
+		for _, w := range windows {
+			visit(w)
+		}
+	}
+
+	return res
+}
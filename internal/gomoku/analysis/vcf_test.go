@@ -0,0 +1,225 @@
+package analysis
+
+import (
+	"testing"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// newGame builds a game from rows listed from the top, like board. The
+// stones of each player are placed in order, taking turns, so the first
+// player has as many stones as the second one or one more.
+func newGame(t *testing.T, winCond uint, rows ...string) *game.Game {
+	t.Helper()
+
+	g, err := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), uint(len(rows)), winCond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stones [2][][2]uint
+
+	for y, row := range rows {
+		for x, c := range row {
+			switch c {
+			case 'o':
+				stones[game.FirstPlayer] = append(stones[game.FirstPlayer], [2]uint{uint(x), uint(y)})
+			case 'x':
+				stones[game.SecondPlayer] = append(stones[game.SecondPlayer], [2]uint{uint(x), uint(y)})
+			}
+		}
+	}
+
+	first, second := stones[game.FirstPlayer], stones[game.SecondPlayer]
+
+	for i := range first {
+		if err := g.MakeMove(first[i][0], first[i][1]); err != nil {
+			t.Fatal(err)
+		}
+
+		if i < len(second) {
+			if err := g.MakeMove(second[i][0], second[i][1]); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if g.State() != game.NotFinished {
+		t.Fatal("the game is already over")
+	}
+
+	return g
+}
+
+var (
+	// vcfBoard is won by the first player with continuous fours only.
+	vcfBoard = []string{
+		"...............",
+		"...............",
+		"...............",
+		"...............",
+		"........o......",
+		"..o.....o.x....",
+		"....o..........",
+		".....xxo..x....",
+		"...o.....oo....",
+		".......xx.o....",
+		"...............",
+		"........xx.....",
+		"...............",
+		"...............",
+		"...............",
+	}
+
+	// vctBoard is won by the first player with a double three, there is
+	// not a single four to make.
+	vctBoard = []string{
+		"x.............x",
+		"...............",
+		"...............",
+		"...............",
+		"...............",
+		"........o......",
+		"........o......",
+		"......oo.......",
+		"...............",
+		"...............",
+		"...............",
+		"...............",
+		"...............",
+		"...............",
+		"x.............x",
+	}
+
+	// quietBoard has no forced win for anyone.
+	quietBoard = []string{
+		"...............",
+		"...............",
+		"...............",
+		"...............",
+		"...............",
+		"...............",
+		"........x......",
+		".......o.......",
+		"......xo.......",
+		"...............",
+		"...............",
+		"...............",
+		"...............",
+		"...............",
+		"...............",
+	}
+)
+
+// checkLine plays the line of the forced win and checks that the attacker
+// threatens with every move and wins with the last one.
+func checkLine(t *testing.T, g *game.Game, res ForcedWin, threes bool) {
+	t.Helper()
+
+	if len(res.Moves)%2 != 1 {
+		t.Fatalf("the line %v does not end with a move of the attacker", res.Moves)
+	}
+
+	g = g.Clone()
+	a := g.CurrentPlayer()
+
+	for i, m := range res.Moves {
+		if err := g.MakeMove(m.X, m.Y); err != nil {
+			t.Fatalf("move %d of %v: %v", i+1, res.Moves, err)
+		}
+
+		if i%2 == 1 || i == len(res.Moves)-1 {
+			continue
+		}
+
+		four, three := false, false
+
+		for _, th := range NewPosition(g).Threats(a) {
+			switch th.Kind {
+			case Four, OpenFour:
+				four = true
+			case OpenThree, BrokenThree:
+				three = true
+			}
+		}
+
+		if !four && !(threes && three) {
+			t.Errorf("move %d of %v, %v, is not a threat", i+1, res.Moves, m)
+		}
+	}
+
+	if g.State() != game.GameState(a)+1 {
+		t.Errorf("the line %v ends with state %v", res.Moves, g.State())
+	}
+}
+
+func TestFindVCF(t *testing.T) {
+	g := newGame(t, 5, vcfBoard...)
+
+	res := FindVCF(g, 200000)
+	if !res.Found {
+		t.Fatalf("no victory by continuous fours found in %d nodes", res.Nodes)
+	}
+
+	if res.Exhausted {
+		t.Error("a search that found a win is exhausted")
+	}
+
+	checkLine(t, g, res, false)
+}
+
+func TestFindVCFNone(t *testing.T) {
+	for name, rows := range map[string][]string{"threes": vctBoard, "quiet": quietBoard} {
+		res := FindVCF(newGame(t, 5, rows...), 200000)
+
+		if res.Found || res.Exhausted || len(res.Moves) != 0 {
+			t.Errorf("%s: found %v, exhausted %v with moves %v, want nothing", name, res.Found, res.Exhausted, res.Moves)
+		}
+	}
+}
+
+func TestFindVCT(t *testing.T) {
+	for name, rows := range map[string][]string{"fours": vcfBoard, "threes": vctBoard} {
+		g := newGame(t, 5, rows...)
+
+		res := FindVCT(g, 200000)
+		if !res.Found {
+			t.Errorf("%s: no victory by continuous threats found in %d nodes", name, res.Nodes)
+			continue
+		}
+
+		checkLine(t, g, res, true)
+	}
+}
+
+func TestFindVCTNone(t *testing.T) {
+	res := FindVCT(newGame(t, 5, quietBoard...), 200000)
+
+	if res.Found || res.Exhausted {
+		t.Errorf("found %v, exhausted %v with moves %v, want nothing", res.Found, res.Exhausted, res.Moves)
+	}
+}
+
+func TestForcedWinBudget(t *testing.T) {
+	g := newGame(t, 5, vctBoard...)
+
+	res := FindVCT(g, 1)
+	if res.Found || !res.Exhausted {
+		t.Errorf("with a budget of 1 found %v, exhausted %v, want an exhausted search", res.Found, res.Exhausted)
+	}
+
+	if res.Nodes > 1 {
+		t.Errorf("searched %d nodes with a budget of 1", res.Nodes)
+	}
+}
+
+func TestForcedWinInOne(t *testing.T) {
+	g := newGame(t, 5, "..........", "..........", "..........", "..........", ".oooo.....",
+		"..........", "..........", "..........", "x.x.x.x...", "..........")
+
+	for _, res := range []ForcedWin{FindVCF(g, 0), FindVCT(g, 0)} {
+		if !res.Found || len(res.Moves) != 1 {
+			t.Errorf("found %v with moves %v, want a win in one move", res.Found, res.Moves)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Package api implements a JSON API for controlling a running game.
+//
+// The API is meant for local scripts and bots, so it only listens on the
+// loopback interface. All requests and responses are JSON objects:
+//
+//	GET  /api/game      the current game
+//	POST /api/game      start a new game
+//	POST /api/moves     play a move, the body is {"x":3,"y":4}
+//	POST /api/undo      take back the last move
+//
+// POST requests must be sent with the application/json content type, even
+// when they have no body. Every successful request answers with the current
+// game, failed requests answer with {"error":"…"}. A game looks like this:
+//
+//	{"size":15,"wincond":5,"players":["Alice","Bob"],"current_player":1,
+//	 "state":0,"strike":null,"resigned":false,"timeout":false,
+//	 "moves":[{"x":7,"y":7,"player":0}],"board":[[0,0,…],…]}
+//
+// board holds size rows of size fields each, indexed as board[y][x]. A field
+// is 0 when empty, 1 for the first player and 2 for the second one. state is
+// 0 while the game goes on, 1 or 2 when the first or the second player has
+// won and 3 on a draw; strike is set when somebody has won by getting a row,
+// resigned when the loser has resigned and timeout when they have run out of
+// time.
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+const (
+	DefaultPort = 7657
+
+	maxBodySize = 4096
+)
+
+type Controller interface {
+	Game() *game.Game
+	Play(x, y uint) error
+	NewGame() error
+	Undo() error
+}
+
+type Move struct {
+	X      uint            `json:"x"`
+	Y      uint            `json:"y"`
+	Player game.PlayerType `json:"player"`
+}
+
+type Game struct {
+	Size          uint               `json:"size"`
+	WinCond       uint               `json:"wincond"`
+	Players       [2]string          `json:"players"`
+	CurrentPlayer game.PlayerType    `json:"current_player"`
+	State         game.GameState     `json:"state"`
+	Strike        *game.Strike       `json:"strike"`
+	Resigned      bool               `json:"resigned"`
+	Timeout       bool               `json:"timeout"`
+	Moves         []Move             `json:"moves"`
+	Board         [][]game.FieldType `json:"board"`
+}
+
+func NewGame(g *game.Game) *Game {
+	res := &Game{
+		Size:          g.Size(),
+		WinCond:       g.WinCond(),
+		CurrentPlayer: g.CurrentPlayer(),
+		State:         g.State(),
+		Resigned:      g.Resigned(),
+		Timeout:       g.TimedOut(),
+		Moves:         []Move{},
+		Board:         make([][]game.FieldType, g.Size()),
+	}
+
+	for i := range res.Players {
+		res.Players[i] = g.Player(game.PlayerType(i)).Name()
+	}
+
+	if s, err := g.Strike(); err == nil && g.State() != game.NobodyWins {
+		res.Strike = &s
+	}
+
+	for _, f := range g.Moves() {
+		res.Moves = append(res.Moves, Move{X: f.X, Y: f.Y, Player: game.PlayerType(f.Ft - 1)})
+	}
+
+	for y := range res.Board {
+		res.Board[y] = make([]game.FieldType, g.Size())
+
+		for x := range res.Board[y] {
+			res.Board[y][x], _ = g.Field(uint(x), uint(y))
+		}
+	}
+
+	return res
+}
+
+type Server struct {
+	port       uint
+	controller Controller
+	run        func(f func())
+
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewServer creates a server on the given port. The controller is only
+// accessed from functions passed to run, which lets the caller execute them
+// on its own thread.
+func NewServer(port uint, controller Controller, run func(f func())) *Server {
+	return &Server{
+		port:       port,
+		controller: controller,
+		run:        run,
+	}
+}
+
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+
+	return s.listener.Addr()
+}
+
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", s.port))
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/game", s.handleGame)
+	mux.HandleFunc("/api/moves", s.handleMoves)
+	mux.HandleFunc("/api/undo", s.handleUndo)
+
+	server := &http.Server{Handler: checkRequest(mux)}
+
+	s.mu.Lock()
+	s.server = server
+	s.listener = ln
+	s.mu.Unlock()
+
+	go server.Serve(ln)
+
+	return nil
+}
+
+func (s *Server) Stop() {
+	s.mu.Lock()
+	server := s.server
+	s.server = nil
+	s.mu.Unlock()
+
+	if server != nil {
+		server.Close()
+	}
+}
+
+func (s *Server) handleGame(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.respond(w, http.StatusOK, nil)
+	case http.MethodPost:
+		s.respond(w, http.StatusCreated, s.controller.NewGame)
+	default:
+		methodNotAllowed(w, "GET, POST")
+	}
+}
+
+func (s *Server) handleMoves(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, "POST")
+		return
+	}
+
+	var m struct {
+		X *uint `json:"x"`
+		Y *uint `json:"y"`
+	}
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&m); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("malformed move: %v", err))
+		return
+	}
+
+	if m.X == nil || m.Y == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("the move must have x and y"))
+		return
+	}
+
+	s.respond(w, http.StatusCreated, func() error {
+		return s.controller.Play(*m.X, *m.Y)
+	})
+}
+
+func (s *Server) handleUndo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, "POST")
+		return
+	}
+
+	s.respond(w, http.StatusOK, s.controller.Undo)
+}
+
+// respond runs action, if any, and answers with the resulting game.
+func (s *Server) respond(w http.ResponseWriter, status int, action func() error) {
+	var (
+		res *Game
+		err error
+	)
+
+	s.run(func() {
+		if action != nil {
+			if err = action(); err != nil {
+				return
+			}
+		}
+
+		g := s.controller.Game()
+		if g == nil {
+			err = fmt.Errorf("game is not started")
+			return
+		}
+
+		res = NewGame(g)
+	})
+
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+
+	writeJSON(w, status, res)
+}
+
+// checkRequest keeps web pages opened in a browser away from the API: it
+// rejects requests sent to a host name other than a loopback one, and POST
+// requests without a JSON body, which browsers do not send cross-origin
+// without asking first.
+func checkRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("unexpected host %q", r.Host))
+			return
+		}
+
+		if r.Method == http.MethodPost {
+			if ct, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); ct != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, fmt.Errorf("the request body must be JSON"))
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func methodNotAllowed(w http.ResponseWriter, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/presenter"
+)
+
+// quietView shows nothing.
+type quietView struct{}
+
+func (quietView) SetStatus(text string)                        {}
+func (quietView) SetButtonLabel(label string)                  {}
+func (quietView) InitBoard(size uint)                          {}
+func (quietView) SetInteractive(enabled bool)                  {}
+func (quietView) DrawStone(x, y uint, ft game.FieldType)       {}
+func (quietView) DrawStrike(s game.Strike)                     {}
+func (quietView) StartClock()                                  {}
+func (quietView) StopClock()                                   {}
+func (quietView) ResetClock()                                  {}
+func (quietView) SetClock(elapsed time.Duration)               {}
+func (quietView) ShowPlayerClocks(first, second time.Duration) {}
+func (quietView) HidePlayerClocks()                            {}
+func (quietView) AppendChat(t time.Time, from, text string)    {}
+func (quietView) ShowHints(hints []presenter.Hint)             {}
+func (quietView) ClearHints()                                  {}
+
+// puzzleController controls a puzzle the way the application does: moves
+// are only taken from the board and undo stops at the puzzle position.
+type puzzleController struct {
+	p *presenter.Presenter
+}
+
+func (c puzzleController) Game() *game.Game {
+	return c.p.Game()
+}
+
+func (c puzzleController) Play(x, y uint) error {
+	return fmt.Errorf("a puzzle is being solved")
+}
+
+func (c puzzleController) NewGame() error {
+	return c.p.StartGame()
+}
+
+func (c puzzleController) Undo() error {
+	return c.p.Undo()
+}
+
+func post(t *testing.T, s *Server, path, body string) (int, *Game, string) {
+	t.Helper()
+
+	resp, err := http.Post(fmt.Sprintf("http://%s%s", s.Addr(), path), "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer resp.Body.Close()
+
+	var res struct {
+		Game
+		Error string `json:"error"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.StatusCode, &res.Game, res.Error
+}
+
+func TestPuzzleUndo(t *testing.T) {
+	newGame := func() (*game.Game, error) {
+		return game.NewGame(game.NewPlayer("Alice"), game.NewPlayer("Bob"), 15, 5)
+	}
+
+	g, _ := newGame()
+	g.MakeMove(7, 7)
+	g.MakeMove(8, 8)
+
+	p := presenter.New(quietView{}, newGame)
+	p.StartGameWith(g)
+	p.SetFixedMoves(2)
+
+	s := NewServer(0, puzzleController{p}, func(f func()) { f() })
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer s.Stop()
+
+	if status, _, msg := post(t, s, "/api/undo", ""); status != http.StatusConflict || msg == "" {
+		t.Errorf("undo at the puzzle position answered %d %q, want a conflict", status, msg)
+	}
+
+	if status, _, msg := post(t, s, "/api/moves", `{"x":6,"y":6}`); status != http.StatusConflict || msg == "" {
+		t.Errorf("a move in a puzzle answered %d %q, want a conflict", status, msg)
+	}
+
+	if err := p.Click(6, 6); err != nil {
+		t.Fatal(err)
+	}
+
+	status, res, msg := post(t, s, "/api/undo", "")
+	if status != http.StatusOK {
+		t.Fatalf("undo answered %d %q", status, msg)
+	}
+
+	if len(res.Moves) != 2 || res.Moves[1].X != 8 || res.Moves[1].Y != 8 {
+		t.Errorf("the moves after undo are %v, want the puzzle position", res.Moves)
+	}
+
+	if status, _, _ := post(t, s, "/api/undo", ""); status != http.StatusConflict {
+		t.Errorf("a second undo answered %d, want a conflict", status)
+	}
+}
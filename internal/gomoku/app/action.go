@@ -16,3 +16,22 @@ func NewAction(name string, parameterType *glib.VariantType, f interface{}) *Act
 
 	return act
 }
+
+// NewToggleAction creates an action with a boolean state, which menus show as
+// a check box. Activating it flips the state and passes the new one to f.
+func NewToggleAction(name string, state bool, f func(state bool)) *Action {
+	act := &Action{}
+	act.SimpleAction = gio.NewSimpleActionStateful(name, nil, glib.NewVariantBoolean(state))
+	act.Connect("activate", func() {
+		state := !act.State().Boolean()
+		act.SetState(glib.NewVariantBoolean(state))
+		f(state)
+	})
+
+	return act
+}
+
+// SetChecked changes the state of a toggle action without activating it.
+func (act *Action) SetChecked(state bool) {
+	act.SetState(glib.NewVariantBoolean(state))
+}
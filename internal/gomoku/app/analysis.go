@@ -0,0 +1,218 @@
+package gomoku
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/core/glib"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/engine"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+const (
+	analysisLines = 3
+
+	// evalScale is the score at which the evaluation bar is three quarters
+	// full.
+	evalScale = 2000
+)
+
+// analysisMode runs one search at a time on the position shown on the board.
+type analysisMode struct {
+	active bool
+	engine *engine.Engine
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// search counts the searches started, so that results of stale searches
+	// can be told apart.
+	search int
+}
+
+// stop cancels the running search. It does not wait for the search to
+// return, the next search does that before using the engine.
+func (a *analysisMode) stop() {
+	if a.cancel != nil {
+		a.cancel()
+		a.cancel = nil
+	}
+}
+
+func (app *Application) toggleAnalysis(enabled bool) {
+	if !enabled {
+		app.stopAnalysis()
+		return
+	}
+
+	if app.peer != nil {
+		app.analysisAction.SetChecked(false)
+		app.wview.SetStatus("Error: analysis is not available in network games")
+		return
+	}
+
+	if app.analysis == nil {
+		e, err := engine.New(engine.DefaultTableSize)
+		if err != nil {
+			app.analysisAction.SetChecked(false)
+			app.reportError(err)
+			return
+		}
+
+		app.analysis = &analysisMode{engine: e}
+	}
+
+	app.analysis.active = true
+	app.gameView.Analysis().SetActive(true)
+	app.analyzePosition()
+}
+
+func (app *Application) stopAnalysis() {
+	if !app.analysisActive() {
+		return
+	}
+
+	app.analysis.active = false
+	app.analysis.stop()
+	app.analysis.search++
+
+	pane := app.gameView.Analysis()
+	pane.SetActive(false)
+	pane.Clear()
+
+	app.analysisAction.SetChecked(false)
+}
+
+func (app *Application) analysisActive() bool {
+	return app.analysis != nil && app.analysis.active
+}
+
+// analyzePosition restarts the analysis on the position shown on the board.
+func (app *Application) analyzePosition() {
+	if !app.analysisActive() {
+		return
+	}
+
+	a := app.analysis
+	a.stop()
+	a.search++
+
+	pane := app.gameView.Analysis()
+	pane.Clear()
+
+	g := app.presenter.Position()
+	if g == nil {
+		pane.SetInfo("There is no game to analyze")
+		return
+	}
+
+	pane.SetMove(fmt.Sprintf("Move %d of %d", len(g.Moves()), len(app.presenter.Game().Moves())))
+
+	if g.State() != game.NotFinished {
+		app.showFinishedAnalysis(g)
+		return
+	}
+
+	pane.SetInfo("Analyzing…")
+
+	threads := app.engineThreads()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	prev := a.done
+	done := make(chan struct{})
+	id := a.search
+
+	a.cancel = cancel
+	a.done = done
+
+	go func() {
+		defer close(done)
+
+		// The engine runs one search at a time.
+		if prev != nil {
+			<-prev
+		}
+
+		a.engine.SetThreads(threads)
+		a.engine.Search(ctx, g, engine.Limits{MultiPV: analysisLines}, func(res engine.Result) {
+			glib.IdleAdd(func() {
+				if app.analysis == a && a.search == id {
+					app.showAnalysis(g, res)
+				}
+			})
+		})
+	}()
+}
+
+func (app *Application) showAnalysis(g *game.Game, res engine.Result) {
+	best, ok := res.Best()
+	if !ok {
+		return
+	}
+
+	pane := app.gameView.Analysis()
+	pt := g.CurrentPlayer()
+
+	pane.Bar().SetFraction(evalFraction(engine.FirstPlayerScore(pt, best.Score)))
+	pane.SetInfo(fmt.Sprintf("%s: %s\nDepth %d, %d nodes",
+		g.Player(game.FirstPlayer).Name(),
+		engine.FormatScore(engine.FirstPlayerScore(pt, best.Score)),
+		res.Depth, res.Nodes))
+
+	lines := make([]string, 0, len(res.Lines))
+
+	for i, l := range res.Lines {
+		moves := make([]string, len(l.PV))
+		for j, m := range l.PV {
+			moves[j] = m.String()
+		}
+
+		lines = append(lines, fmt.Sprintf("%d. %s  %s", i+1,
+			engine.FormatScore(engine.FirstPlayerScore(pt, l.Score)),
+			strings.Join(moves, " ")))
+	}
+
+	pane.SetLines(lines)
+}
+
+func (app *Application) showFinishedAnalysis(g *game.Game) {
+	pane := app.gameView.Analysis()
+
+	switch state := g.State(); state {
+	case game.NobodyWins:
+		pane.SetInfo("Draw")
+	case game.FirstPlayerWin, game.SecondPlayerWin:
+		winner := game.PlayerType(state - 1)
+
+		pane.SetInfo(fmt.Sprintf("%s wins", g.Player(winner).Name()))
+
+		if winner == game.FirstPlayer {
+			pane.Bar().SetFraction(1)
+		} else {
+			pane.Bar().SetFraction(0)
+		}
+	}
+}
+
+// evalFraction maps a score for the first player to the share of the
+// evaluation bar the first player gets.
+func evalFraction(score int) float64 {
+	switch {
+	case engine.IsWin(score):
+		return 1
+	case engine.IsLoss(score):
+		return 0
+	}
+
+	return 1 / (1 + math.Exp(-float64(score)*math.Log(3)/evalScale))
+}
+
+func (app *Application) historyBack() {
+	app.reportError(app.presenter.Back())
+}
+
+func (app *Application) historyForward() {
+	app.reportError(app.presenter.Forward())
+}
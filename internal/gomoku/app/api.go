@@ -0,0 +1,58 @@
+package gomoku
+
+import (
+	"fmt"
+
+	"github.com/diamondburned/gotk4/pkg/core/glib"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/api"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+type apiController struct {
+	app *Application
+}
+
+func (c apiController) Game() *game.Game {
+	return c.app.presenter.Game()
+}
+
+func (c apiController) Play(x, y uint) error {
+	// The moves of a puzzle are checked in the background and cannot be
+	// answered here.
+	if c.app.puzzle != nil {
+		return fmt.Errorf("a puzzle is being solved")
+	}
+
+	return c.app.presenter.Click(x, y)
+}
+
+func (c apiController) NewGame() error {
+	return c.app.newGame()
+}
+
+func (c apiController) Undo() error {
+	return c.app.undoMove()
+}
+
+func (app *Application) serveAPI(port uint) error {
+	server := api.NewServer(port, apiController{app}, runOnMainLoop)
+	if err := server.Start(); err != nil {
+		return err
+	}
+
+	app.api = server
+
+	return nil
+}
+
+func runOnMainLoop(f func()) {
+	done := make(chan struct{})
+
+	glib.IdleAdd(func() {
+		defer close(done)
+		f()
+	})
+
+	<-done
+}
@@ -5,110 +5,77 @@ import (
 	"math"
 	"os"
 
+	"github.com/diamondburned/gotk4/pkg/core/glib"
 	"github.com/diamondburned/gotk4/pkg/gio/v2"
 	"github.com/diamondburned/gotk4/pkg/gtk/v4"
 
+	"github.com/infastin/gomoku2go/internal/gomoku/api"
+	"github.com/infastin/gomoku2go/internal/gomoku/engine"
 	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/netplay"
+	"github.com/infastin/gomoku2go/internal/gomoku/presenter"
+	"github.com/infastin/gomoku2go/internal/gomoku/puzzle"
+	"github.com/infastin/gomoku2go/internal/gomoku/settings"
 	"github.com/infastin/gomoku2go/internal/gomoku/view"
+	"github.com/infastin/gomoku2go/internal/gomoku/webplay"
 )
 
 const (
 	appID = "com.github.infastin.gomoku2go"
+
+	// clockInterval is how often the clocks of a timed game are updated, in
+	// milliseconds.
+	clockInterval = 100
 )
 
 type Application struct {
 	*gtk.Application
 
-	settings *gio.Settings
+	settings *settings.Settings
 
 	gameView  *view.MainWindow
-	gameLogic *game.Game
+	wview     *windowView
+	presenter *presenter.Presenter
+	peer      *netplay.Peer
+	announcer *netplay.Announcer
+	web       *webplay.Server
+	api       *api.Server
+	engine    *engine.Engine
+	searching bool
+
+	computer       *computerPlayer
+	analysis       *analysisMode
+	analysisAction *Action
+	outcomes       *outcomeOverlay
+	outcomesAction *Action
+	review         *gameReview
+	puzzles        []puzzle.Puzzle
+	puzzle         *puzzleMode
 }
 
 func NewApplication() *Application {
 	app := &Application{}
-	app.Application = gtk.NewApplication(appID, gio.ApplicationFlagsNone)
+	app.Application = gtk.NewApplication(appID, gio.ApplicationHandlesCommandLine)
 	return app
 }
 
 func (app *Application) handleClick(x, y uint) {
-	suc, err := app.gameLogic.SetField(x, y)
-
-	if err != nil {
+	if app.puzzle != nil {
+		app.puzzleMove(x, y)
 		return
 	}
 
-	board := app.gameView.Board()
-
-	if suc {
-		switch app.gameLogic.CurrentPlayer() {
-		case game.FirstPlayer:
-			board.DrawCircle(x, y)
-		case game.SecondPlayer:
-			board.DrawCross(x, y)
-		}
-
-		stopwatch := app.gameView.Stopwatch()
-		cplabel := app.gameView.CurrentPlayerLabel()
-		sgBtn := app.gameView.StartGameBtn()
-
-		if draw := app.gameLogic.CheckDraw(); draw {
-			stopwatch.Stop()
-			sgBtn.SetLabel("Start Game")
-			cplabel.SetText("Draw")
-			return
-		}
-
-		if s, win := app.gameLogic.CheckWinner(x, y); win {
-			stopwatch.Stop()
-			sgBtn.SetLabel("Start Game")
-			board.DrawStrike(view.Strike(s))
-
-			playerName := app.gameLogic.Player(app.gameLogic.CurrentPlayer()).Name()
-			cplabel.SetText(fmt.Sprintf("%s wins", playerName))
-			return
-		}
-
-		app.gameLogic.ChangePlayer()
-		playerName := app.gameLogic.Player(app.gameLogic.CurrentPlayer()).Name()
-		app.gameView.CurrentPlayerLabel().SetText(fmt.Sprintf("%s's turn", playerName))
-	}
+	app.presenter.Click(x, y)
 }
 
 func (app *Application) handleRedraw() {
-	lfields := app.gameLogic.NotEmptyFields()
-	board := app.gameView.Board()
-
-	var vfields []view.Field
-
-	for _, lf := range lfields {
-		var sh view.Shape
-
-		switch lf.Ft {
-		case game.FirstPlayerField:
-			sh = view.Circle
-		case game.SecondPlayerField:
-			sh = view.Cross
-		}
-
-		vfields = append(vfields, view.Field{
-			X:  lf.X,
-			Y:  lf.Y,
-			Sh: sh,
-		})
-	}
-
-	if app.gameLogic.State() != game.NotFinished {
-		strike, _ := app.gameLogic.Strike()
-		board.DrawShapesAndStrike(vfields, view.Strike(strike))
-	} else {
-		board.DrawShapes(vfields)
-	}
+	app.presenter.Redraw()
 }
 
 func (app *Application) Start() {
 	app.ConnectActivate(app.activate)
 	app.ConnectStartup(app.startup)
+	app.addMainOptions()
 
 	if code := app.Run(os.Args); code > 0 {
 		os.Exit(code)
@@ -116,45 +83,62 @@ func (app *Application) Start() {
 }
 
 func (app *Application) activate() {
+	if app.gameView != nil {
+		app.gameView.Present()
+		return
+	}
+
 	app.gameView = view.NewMainWindow(app.Application)
 	app.gameView.Show()
 	app.gameView.StartGameBtn().ConnectClicked(app.startGame)
-}
 
-func (app *Application) startGame() {
-	p1Str := app.settings.String("player1")
-	p2Str := app.settings.String("player2")
-	size := app.settings.Uint("size")
-	winCond := app.settings.Uint("wincond")
-
-	board := app.gameView.Board()
-	btn := app.gameView.StartGameBtn()
-	cplabel := app.gameView.CurrentPlayerLabel()
+	app.wview = newWindowView(app.gameView, app.handleClick, app.handleRedraw)
+	app.presenter = presenter.New(app.wview, app.settings.NewGame)
+	app.presenter.ConnectPositionChanged(app.positionChanged)
+	app.presenter.SetTimeControl(app.settings.TimeControl())
+	app.syncComputers()
 
-	p1 := game.NewPlayer(p1Str)
-	p2 := game.NewPlayer(p2Str)
+	glib.TimeoutAdd(clockInterval, func() bool {
+		app.presenter.CheckTime()
+		return true
+	})
 
-	if app.gameLogic != nil {
-		app.gameView.Stopwatch().Restart()
-		board.Clear()
-	}
+	app.gameView.Chat().ConnectSend(app.sendChat)
+}
 
-	app.gameLogic, _ = game.NewGame(p1, p2, size, winCond)
+func (app *Application) positionChanged() {
+	app.analyzePosition()
+	app.solvePosition()
+	app.updateReview()
+	app.computerMove()
+}
 
-	playerName := app.gameLogic.Player(app.gameLogic.CurrentPlayer()).Name()
-	cplabel.SetText(fmt.Sprintf("%s's turn", playerName))
+func (app *Application) startGame() {
+	if err := app.newGame(); err != nil && app.peer != nil {
+		app.wview.SetStatus(fmt.Sprint("Error: ", err.Error()))
+	}
+}
 
-	btn.SetLabel("Restart Game")
+func (app *Application) newGame() error {
+	if app.peer != nil {
+		return app.peer.NewGame()
+	}
 
-	board.Init(size)
-	board.Draw()
-	board.ConnectClick(app.handleClick)
-	board.ConnectRedraw(app.handleRedraw)
+	app.stopPuzzle()
 
-	app.gameView.Stopwatch().Start()
+	return app.presenter.StartGame()
 }
 
 func (app *Application) quit() {
+	app.leaveGame()
+	app.stopAnalysis()
+	app.stopOutcomes()
+
+	if app.api != nil {
+		app.api.Stop()
+		app.api = nil
+	}
+
 	app.Quit()
 }
 
@@ -166,12 +150,24 @@ func (app *Application) prefs() {
 	p2Entry := dialog.SecondPlayerEntry()
 	sizeSB := dialog.BoardSizeSpinButton()
 	wincondSB := dialog.WinCondSpinButton()
+	p1Level := dialog.FirstPlayerLevel()
+	p2Level := dialog.SecondPlayerLevel()
+	useBook := dialog.UseBookCheckButton()
+	timeSB := dialog.TimeSpinButton()
+	incrementSB := dialog.IncrementSpinButton()
+	ponder := dialog.PonderCheckButton()
 	errorLabel := dialog.ErrorLabel()
 
 	p1Entry.SetText(app.settings.String("player1"))
 	p2Entry.SetText(app.settings.String("player2"))
 	sizeSB.SetValue(float64(app.settings.Uint("size")))
 	wincondSB.SetValue(float64(app.settings.Uint("wincond")))
+	p1Level.SetSelected(app.settings.Uint("player1-level"))
+	p2Level.SetSelected(app.settings.Uint("player2-level"))
+	useBook.SetActive(app.settings.Boolean("use-book"))
+	timeSB.SetValue(float64(app.settings.Uint("time-minutes")))
+	incrementSB.SetValue(float64(app.settings.Uint("time-increment")))
+	ponder.SetActive(app.settings.Boolean("ponder"))
 
 	dialog.CancelButton().ConnectClicked(func() {
 		dialog.Close()
@@ -201,6 +197,12 @@ func (app *Application) prefs() {
 			app.settings.SetString("player2", p2Name)
 			app.settings.SetUint("size", usize)
 			app.settings.SetUint("wincond", uwincond)
+			app.settings.SetUint("player1-level", p1Level.Selected())
+			app.settings.SetUint("player2-level", p2Level.Selected())
+			app.settings.SetBoolean("use-book", useBook.Active())
+			app.settings.SetUint("time-minutes", uint(math.Floor(timeSB.Value())))
+			app.settings.SetUint("time-increment", uint(math.Floor(incrementSB.Value())))
+			app.settings.SetBoolean("ponder", ponder.Active())
 
 			dialog.Close()
 		} else {
@@ -211,21 +213,30 @@ func (app *Application) prefs() {
 }
 
 func (app *Application) startup() {
+	app.AddAction(NewAction("save", nil, app.saveGame))
+	app.addCommandActions()
+	app.AddAction(NewAction("find-win", nil, app.findForcedWin))
+	app.AddAction(NewAction("hint", nil, app.showHint))
+
+	app.analysisAction = NewToggleAction("analysis", false, app.toggleAnalysis)
+	app.AddAction(app.analysisAction)
+	app.outcomesAction = NewToggleAction("outcomes", false, app.toggleOutcomes)
+	app.AddAction(app.outcomesAction)
+	app.AddAction(NewAction("review", nil, app.reviewGame))
+	app.AddAction(NewAction("puzzles", nil, app.showPuzzles))
+	app.AddAction(NewAction("history-back", nil, app.historyBack))
+	app.AddAction(NewAction("history-forward", nil, app.historyForward))
+	app.SetAccelsForAction("app.history-back", []string{"<Alt>Left"})
+	app.SetAccelsForAction("app.history-forward", []string{"<Alt>Right"})
 	app.AddAction(NewAction("preferences", nil, app.prefs))
+	app.AddAction(NewAction("host", nil, app.hostGame))
+	app.AddAction(NewAction("join", nil, app.joinGame))
+	app.AddAction(NewAction("join-lan", nil, app.joinLANGame))
+	app.AddAction(NewAction("watch", nil, app.watchGame))
+	app.AddAction(NewAction("web", nil, app.webGame))
+	app.AddAction(NewAction("leave", nil, app.leaveGame))
 	app.AddAction(NewAction("quit", nil, app.quit))
 
-	app.settings = gio.NewSettings(appID)
-
-	p1Str := app.settings.String("player1")
-	p2Str := app.settings.String("player2")
-
-	if len(p1Str) > 16 {
-		p1Str = "Player 1"
-		app.settings.SetString("player1", p1Str)
-	}
-
-	if len(p2Str) > 16 {
-		p2Str = "Player 2"
-		app.settings.SetString("player2", p2Str)
-	}
+	app.settings = settings.New()
+	app.settings.ConnectChanged(app.settingsChanged)
 }
@@ -0,0 +1,88 @@
+package gomoku
+
+import (
+	"fmt"
+
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// The actions below are exported over D-Bus together with the rest of the
+// application actions, e.g.:
+//
+//	gdbus call --session --dest com.github.infastin.gomoku2go \
+//		--object-path /com/github/infastin/gomoku2go \
+//		--method org.gtk.Actions.Activate play-move "[<(uint32 7, uint32 7)>]" {}
+func (app *Application) addCommandActions() {
+	app.AddAction(NewAction("new-game", nil, app.startGame))
+	app.AddAction(NewAction("play-move", glib.NewVariantType("(uu)"), app.playMove))
+	app.AddAction(NewAction("undo", nil, app.undo))
+	app.AddAction(NewAction("open", nil, app.openGame))
+	app.AddAction(NewAction("open-file", glib.NewVariantType("s"), app.openFileAction))
+	app.AddAction(NewAction("resign", nil, app.resign))
+}
+
+func (app *Application) playMove(param *glib.Variant) {
+	x := param.ChildValue(0).Uint32()
+	y := param.ChildValue(1).Uint32()
+
+	if app.puzzle != nil {
+		app.puzzleMove(uint(x), uint(y))
+		return
+	}
+
+	app.reportError(app.presenter.Click(uint(x), uint(y)))
+}
+
+func (app *Application) undo() {
+	app.reportError(app.undoMove())
+}
+
+// undoMove takes back the last move, in a puzzle down to its position only.
+func (app *Application) undoMove() error {
+	if err := app.presenter.Undo(); err != nil {
+		return err
+	}
+
+	if app.puzzle != nil {
+		app.puzzleUndone()
+	}
+
+	return nil
+}
+
+func (app *Application) openFileAction(param *glib.Variant) {
+	_, path := param.String()
+
+	app.reportError(app.openFile(path))
+}
+
+func (app *Application) resign() {
+	app.reportError(app.presenter.Resign())
+}
+
+func (app *Application) newGameWith(size, wincond uint) error {
+	if app.peer != nil {
+		return fmt.Errorf("leave the network game first")
+	}
+
+	p1 := game.NewPlayer(app.settings.FirstPlayerName())
+	p2 := game.NewPlayer(app.settings.SecondPlayerName())
+
+	g, err := game.NewGame(p1, p2, size, wincond)
+	if err != nil {
+		return err
+	}
+
+	app.stopPuzzle()
+	app.presenter.StartGameWith(g)
+
+	return nil
+}
+
+func (app *Application) reportError(err error) {
+	if err != nil {
+		app.wview.SetStatus(fmt.Sprint("Error: ", err.Error()))
+	}
+}
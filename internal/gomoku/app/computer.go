@@ -0,0 +1,243 @@
+package gomoku
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/diamondburned/gotk4/pkg/core/glib"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/book"
+	"github.com/infastin/gomoku2go/internal/gomoku/engine"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// computerPlayer searches for the moves of the players the computer plays
+// for, one search at a time.
+type computerPlayer struct {
+	engine *engine.Engine
+	cancel context.CancelFunc
+
+	// The opening book is loaded on the first move made with it.
+	book       *book.Book
+	bookLoaded bool
+
+	done   chan struct{}
+	search int
+}
+
+func (c *computerPlayer) stop() {
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+
+	c.search++
+}
+
+// next prepares a new search: it returns the context of the search, the
+// channel closed when the previous search is done and the channel to close
+// when this one is done.
+func (c *computerPlayer) next() (context.Context, <-chan struct{}, chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	prev := c.done
+	done := make(chan struct{})
+
+	c.cancel = cancel
+	c.done = done
+
+	return ctx, prev, done
+}
+
+func (c *computerPlayer) openingBook() (*book.Book, error) {
+	if c.bookLoaded {
+		return c.book, nil
+	}
+
+	c.bookLoaded = true
+
+	path, err := book.DefaultPath()
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the opening book: %v", err)
+	}
+
+	c.book, err = book.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the opening book: %v", err)
+	}
+
+	return c.book, nil
+}
+
+func (app *Application) syncComputers() {
+	// The opponent in a puzzle moves through the puzzle.
+	if app.puzzle != nil {
+		return
+	}
+
+	for pt := game.FirstPlayer; pt <= game.SecondPlayer; pt++ {
+		app.presenter.SetComputer(pt, app.settings.PlayerLevel(pt) != 0)
+	}
+}
+
+// engineThreads returns the number of threads engine searches run on.
+func (app *Application) engineThreads() int {
+	if n := app.settings.Threads(); n < engine.MaxThreads {
+		return n
+	}
+
+	return engine.MaxThreads
+}
+
+func (app *Application) settingsChanged(key string) {
+	if app.presenter == nil {
+		return
+	}
+
+	switch key {
+	case "player1-level", "player2-level":
+		app.syncComputers()
+		app.computerMove()
+	case "time-minutes", "time-increment":
+		app.presenter.SetTimeControl(app.settings.TimeControl())
+	case "ponder":
+		app.computerMove()
+	case "use-book":
+		// Load the book again, it may have been rebuilt since.
+		if app.computer != nil {
+			app.computer.book = nil
+			app.computer.bookLoaded = false
+		}
+
+		app.computerMove()
+	}
+}
+
+// computerMove starts looking for a move if the computer plays for the
+// player to move. A search for an earlier position is cancelled.
+func (app *Application) computerMove() {
+	if app.computer != nil {
+		app.computer.stop()
+	}
+
+	if app.puzzle != nil {
+		app.puzzle.search++
+	}
+
+	g := app.presenter.Game()
+
+	if g == nil || g.State() != game.NotFinished || app.peer != nil {
+		return
+	}
+
+	// Wait until the board shows the game as it is.
+	if app.presenter.ShownMoves() != len(g.Moves()) {
+		return
+	}
+
+	if app.puzzle != nil {
+		app.puzzleReply(g)
+		return
+	}
+
+	pt := g.CurrentPlayer()
+	if !app.presenter.IsComputer(pt) {
+		app.ponder(g)
+		return
+	}
+
+	if !app.loadComputer() {
+		return
+	}
+
+	c := app.computer
+	lvl := int(app.settings.PlayerLevel(pt))
+	threads := app.engineThreads()
+	position := g.Clone()
+
+	var tl engine.TimeLeft
+
+	if remaining, increment, ok := app.presenter.TimeLeft(pt); ok {
+		tl = engine.TimeLeft{Remaining: remaining, Increment: increment}
+	}
+
+	var bk *book.Book
+
+	if app.settings.UseBook() {
+		var err error
+		bk, err = c.openingBook()
+		app.reportError(err)
+	}
+
+	ctx, prev, done := c.next()
+	id := c.search
+
+	go func() {
+		defer close(done)
+
+		// The engine runs one search at a time.
+		if prev != nil {
+			<-prev
+		}
+
+		c.engine.SetBook(bk)
+		c.engine.SetThreads(threads)
+		m, err := c.engine.Play(ctx, position, lvl, tl)
+
+		glib.IdleAdd(func() {
+			if c.search != id {
+				return
+			}
+
+			if err != nil {
+				app.reportError(err)
+				return
+			}
+
+			app.reportError(app.presenter.Play(m.X, m.Y))
+		})
+	}()
+}
+
+func (app *Application) loadComputer() bool {
+	if app.computer != nil {
+		return true
+	}
+
+	e, err := engine.New(engine.DefaultTableSize)
+	if err != nil {
+		app.reportError(err)
+		return false
+	}
+
+	app.computer = &computerPlayer{engine: e}
+
+	return true
+}
+
+// ponder lets the computer think while its human opponent is to move. The
+// search only fills the table of the engine and is cancelled as soon as the
+// position changes.
+func (app *Application) ponder(g *game.Game) {
+	opponent := (g.CurrentPlayer() + 1) % 2
+
+	if !app.settings.Ponder() || !app.presenter.IsComputer(opponent) || !app.loadComputer() {
+		return
+	}
+
+	c := app.computer
+	threads := app.engineThreads()
+	position := g.Clone()
+
+	ctx, prev, done := c.next()
+
+	go func() {
+		defer close(done)
+
+		if prev != nil {
+			<-prev
+		}
+
+		c.engine.SetThreads(threads)
+		c.engine.Ponder(ctx, position)
+	}()
+}
@@ -0,0 +1,105 @@
+package gomoku
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/diamondburned/gotk4/pkg/core/glib"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/engine"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/presenter"
+)
+
+const (
+	hintCount = 3
+	hintTime  = 2 * time.Second
+)
+
+func (app *Application) showHint() {
+	g := app.presenter.Game()
+
+	if g == nil || g.State() != game.NotFinished {
+		app.wview.SetStatus("Error: there is no game in progress")
+		return
+	}
+
+	if app.peer != nil {
+		app.wview.SetStatus("Error: hints are not available in network games")
+		return
+	}
+
+	if app.searching {
+		return
+	}
+
+	if app.engine == nil {
+		e, err := engine.New(engine.DefaultTableSize)
+		if err != nil {
+			app.reportError(err)
+			return
+		}
+
+		app.engine = e
+	}
+
+	app.searching = true
+	app.wview.SetStatus("Looking for the best moves…")
+
+	position := g.Clone()
+	eng := app.engine
+	threads := app.engineThreads()
+
+	go func() {
+		eng.SetThreads(threads)
+		res, err := eng.Search(context.Background(), position, engine.Limits{
+			Time:    hintTime,
+			MultiPV: hintCount,
+		}, nil)
+
+		glib.IdleAdd(func() {
+			app.searching = false
+
+			// The game has moved on while searching.
+			if cur := app.presenter.Game(); cur == nil || cur.Hash() != position.Hash() || cur.Size() != position.Size() {
+				return
+			}
+
+			if err != nil {
+				app.reportError(err)
+				return
+			}
+
+			app.showHints(position, res)
+		})
+	}()
+}
+
+func (app *Application) showHints(position *game.Game, res engine.Result) {
+	best, ok := res.Best()
+	if !ok {
+		app.wview.SetStatus("Error: no moves found")
+		return
+	}
+
+	hints := make([]presenter.Hint, 0, len(res.Lines))
+	for _, l := range res.Lines {
+		hints = append(hints, presenter.Hint{
+			X:     l.Move.X,
+			Y:     l.Move.Y,
+			Label: engine.FormatScore(l.Score),
+		})
+	}
+
+	if err := app.presenter.ShowHints(hints); err != nil {
+		app.reportError(err)
+		return
+	}
+
+	name := position.Player(position.CurrentPlayer()).Name()
+	used := app.presenter.HintsUsed()[position.CurrentPlayer()]
+
+	app.wview.SetStatus(fmt.Sprintf("Hint for %s (%d used): best move scores %s",
+		name, used, engine.FormatScore(best.Score)))
+}
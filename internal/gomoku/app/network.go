@@ -0,0 +1,343 @@
+package gomoku
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/gotk4/pkg/core/glib"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/netplay"
+	"github.com/infastin/gomoku2go/internal/gomoku/view"
+)
+
+func (app *Application) hostGame() {
+	dialog := view.NewHostDialog(app.gameView)
+	dialog.Show()
+
+	portSB := dialog.PortSpinButton()
+	errorLabel := dialog.ErrorLabel()
+
+	portSB.SetValue(netplay.DefaultPort)
+
+	dialog.CancelButton().ConnectClicked(func() {
+		dialog.Close()
+	})
+
+	dialog.ConfirmButton().ConnectClicked(func() {
+		port := uint(math.Floor(portSB.Value()))
+
+		settings := netplay.Settings{
+			Size:    app.settings.BoardSize(),
+			WinCond: app.settings.WinCond(),
+			Names:   [2]string{app.settings.FirstPlayerName(), ""},
+		}
+
+		app.leaveGame()
+
+		peer := netplay.NewHost(fmt.Sprintf(":%d", port), settings)
+		app.connectPeer(peer)
+
+		if err := peer.Start(); err != nil {
+			errorLabel.SetText(fmt.Sprint("Error: ", err.Error()))
+			errorLabel.SetVisible(true)
+			return
+		}
+
+		app.peer = peer
+		app.announce(peer, port)
+		app.wview.SetStatus(fmt.Sprintf("Waiting for an opponent on port %d", port))
+
+		dialog.Close()
+	})
+}
+
+func (app *Application) announce(peer *netplay.Peer, port uint) {
+	name := app.settings.FirstPlayerName()
+
+	announcer, err := netplay.NewAnnouncer("", func() netplay.Announcement {
+		s := peer.Settings()
+
+		return netplay.Announcement{
+			Name:    name,
+			Port:    port,
+			Size:    s.Size,
+			WinCond: s.WinCond,
+			Open:    !peer.HasOpponent(),
+		}
+	})
+	if err != nil {
+		return
+	}
+
+	app.announcer = announcer
+	app.announcer.Start()
+}
+
+func (app *Application) joinGame() {
+	app.showJoinDialog(false)
+}
+
+func (app *Application) watchGame() {
+	app.showJoinDialog(true)
+}
+
+func (app *Application) showJoinDialog(watch bool) {
+	dialog := view.NewJoinDialog(app.gameView)
+
+	if watch {
+		dialog.SetTitle("Watch Network Game")
+		dialog.ConfirmButton().SetLabel("Watch")
+	}
+
+	dialog.Show()
+
+	addrEntry := dialog.AddressEntry()
+	errorLabel := dialog.ErrorLabel()
+
+	addrEntry.SetText(fmt.Sprintf("localhost:%d", netplay.DefaultPort))
+
+	dialog.CancelButton().ConnectClicked(func() {
+		dialog.Close()
+	})
+
+	dialog.ConfirmButton().ConnectClicked(func() {
+		addr := strings.TrimSpace(addrEntry.Text())
+
+		if !strings.Contains(addr, ":") {
+			errorLabel.SetText("Error: the address must be in the host:port form")
+			errorLabel.SetVisible(true)
+			return
+		}
+
+		app.connectTo(addr, watch)
+		dialog.Close()
+	})
+}
+
+func (app *Application) joinLANGame() {
+	dialog := view.NewLANDialog(app.gameView)
+	dialog.Show()
+
+	errorLabel := dialog.ErrorLabel()
+
+	var games []netplay.LANGame
+
+	browser := netplay.NewBrowser("")
+	browser.ConnectUpdate(func(update []netplay.LANGame) {
+		glib.IdleAdd(func() {
+			games = update
+
+			var rows []string
+			for _, g := range games {
+				status := "waiting for an opponent"
+				if !g.Open {
+					status = "in progress"
+				}
+
+				rows = append(rows, fmt.Sprintf("%s — %dx%d, %d in a row, %s (%s)",
+					g.Name, g.Size, g.Size, g.WinCond, status, g.Addr))
+			}
+
+			dialog.SetGames(rows)
+		})
+	})
+
+	if err := browser.Start(); err != nil {
+		errorLabel.SetText(fmt.Sprint("Error: ", err.Error()))
+		errorLabel.SetVisible(true)
+	}
+
+	dialog.ConnectCloseRequest(func() bool {
+		browser.Stop()
+		return false
+	})
+
+	dialog.CancelButton().ConnectClicked(func() {
+		dialog.Close()
+	})
+
+	join := func(watch bool) {
+		idx := dialog.SelectedIndex()
+
+		if idx < 0 || idx >= len(games) {
+			errorLabel.SetText("Error: select a game")
+			errorLabel.SetVisible(true)
+			return
+		}
+
+		app.connectTo(games[idx].Addr, watch)
+		dialog.Close()
+	}
+
+	dialog.WatchButton().ConnectClicked(func() {
+		join(true)
+	})
+
+	dialog.ConfirmButton().ConnectClicked(func() {
+		join(false)
+	})
+}
+
+func (app *Application) connectTo(addr string, watch bool) {
+	app.leaveGame()
+
+	var peer *netplay.Peer
+
+	if watch {
+		peer = netplay.NewSpectator(addr, app.settings.SecondPlayerName())
+	} else {
+		peer = netplay.NewGuest(addr, app.settings.SecondPlayerName())
+	}
+
+	app.connectPeer(peer)
+	peer.Start()
+
+	app.peer = peer
+	app.wview.SetStatus(fmt.Sprintf("Connecting to %s", addr))
+}
+
+func (app *Application) leaveGame() {
+	if app.announcer != nil {
+		app.announcer.Stop()
+		app.announcer = nil
+	}
+
+	if app.web != nil {
+		app.web.Stop()
+		app.web = nil
+	}
+
+	if app.peer == nil {
+		return
+	}
+
+	app.peer.Close()
+	app.peer = nil
+	app.presenter.ClearRemote()
+
+	chat := app.gameView.Chat()
+	chat.SetVisible(false)
+	chat.Clear()
+}
+
+func (app *Application) connectPeer(peer *netplay.Peer) {
+	peer.ConnectStart(func(s netplay.Settings, moves []netplay.Move) {
+		glib.IdleAdd(func() {
+			if app.peer == peer {
+				app.startNetworkGame(peer, s, moves)
+			}
+		})
+	})
+
+	peer.ConnectMove(func(x, y uint) {
+		glib.IdleAdd(func() {
+			if app.peer == peer {
+				app.presenter.Play(x, y)
+			}
+		})
+	})
+
+	peer.ConnectPause(func(err error) {
+		glib.IdleAdd(func() {
+			if app.peer == peer {
+				app.presenter.Pause("Connection lost, waiting for the opponent")
+			}
+		})
+	})
+
+	peer.ConnectResume(func(moves []netplay.Move) {
+		glib.IdleAdd(func() {
+			if app.peer == peer {
+				app.syncMoves(moves)
+				app.presenter.Resume()
+			}
+		})
+	})
+
+	peer.ConnectClock(func(elapsed time.Duration, running bool) {
+		glib.IdleAdd(func() {
+			if app.peer == peer {
+				app.presenter.SyncClock(elapsed, running)
+			}
+		})
+	})
+
+	peer.ConnectChat(func(c netplay.Chat) {
+		glib.IdleAdd(func() {
+			if app.peer == peer {
+				app.presenter.AddChat(c.Timestamp(), c.From, c.Text)
+			}
+		})
+	})
+
+	peer.ConnectClose(func(err error) {
+		glib.IdleAdd(func() {
+			if app.peer == peer {
+				app.leaveGame()
+				app.presenter.Pause(fmt.Sprintf("Network game is over: %s", err.Error()))
+			}
+		})
+	})
+}
+
+func (app *Application) startNetworkGame(peer *netplay.Peer, s netplay.Settings, moves []netplay.Move) {
+	app.stopAnalysis()
+
+	p1 := game.NewPlayer(s.Names[0])
+	p2 := game.NewPlayer(s.Names[1])
+
+	g, err := game.NewGame(p1, p2, s.Size, s.WinCond)
+	if err != nil {
+		return
+	}
+
+	for _, m := range moves {
+		if err := g.MakeMove(m.X, m.Y); err != nil {
+			return
+		}
+	}
+
+	if peer.Role() == netplay.SpectatorRole {
+		app.presenter.SetSpectator()
+	} else {
+		app.presenter.SetRemote(peer.LocalPlayer(), peer.SendMove)
+	}
+
+	app.stopPuzzle()
+	app.presenter.StartGameWith(g)
+
+	chat := app.gameView.Chat()
+	chat.SetSendable(peer.Role() != netplay.SpectatorRole)
+	chat.SetVisible(true)
+}
+
+func (app *Application) sendChat(text string) {
+	if app.peer == nil {
+		return
+	}
+
+	chat := app.gameView.Chat()
+
+	c, err := app.peer.SendChat(text)
+	if err != nil {
+		chat.Append(time.Now(), "Error", err.Error())
+		return
+	}
+
+	chat.ClearEntry()
+	app.presenter.AddChat(c.Timestamp(), c.From, c.Text)
+}
+
+func (app *Application) syncMoves(moves []netplay.Move) {
+	g := app.presenter.Game()
+	if g == nil {
+		return
+	}
+
+	for i := len(g.Moves()); i < len(moves); i++ {
+		app.presenter.Play(moves[i].X, moves[i].Y)
+	}
+}
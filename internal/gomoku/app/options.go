@@ -0,0 +1,163 @@
+package gomoku
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+	"github.com/diamondburned/gotk4/pkg/glib/v2"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+func (app *Application) addMainOptions() {
+	app.AddMainOption("new-game", 0, glib.OptionFlagNone, glib.OptionArgNone,
+		"Start a new game", "")
+	app.AddMainOption("size", 0, glib.OptionFlagNone, glib.OptionArgInt,
+		"Board size of the new game", "SIZE")
+	app.AddMainOption("wincond", 0, glib.OptionFlagNone, glib.OptionArgInt,
+		"Number of marks in a row needed to win the new game", "N")
+	app.AddMainOption("open", 0, glib.OptionFlagNone, glib.OptionArgString,
+		"Open a saved game", "FILE")
+	app.AddMainOption("web-port", 0, glib.OptionFlagNone, glib.OptionArgInt,
+		"Serve the game to web browsers on PORT", "PORT")
+	app.AddMainOption("api-port", 0, glib.OptionFlagNone, glib.OptionArgInt,
+		"Enable the JSON API on localhost:PORT", "PORT")
+
+	app.ConnectHandleLocalOptions(app.handleLocalOptions)
+	app.ConnectCommandLine(app.commandLine)
+}
+
+// handleLocalOptions checks the options in the invoking process, so that
+// errors are reported to the terminal they came from, and passes them on to
+// the primary instance.
+func (app *Application) handleLocalOptions(options *glib.VariantDict) int {
+	if err := checkOptions(options); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// The primary instance may run in another directory.
+	if v := options.LookupValue("open", glib.NewVariantType("s")); v != nil {
+		_, path := v.String()
+
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		options.InsertValue("open", glib.NewVariantString(abs))
+	}
+
+	return -1
+}
+
+func checkOptions(options *glib.VariantDict) error {
+	for _, name := range []string{"web-port", "api-port"} {
+		if _, err := lookupPort(options, name); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range []string{"size", "wincond"} {
+		n, ok := lookupInt(options, name)
+		if !ok {
+			continue
+		}
+
+		if !options.Contains("new-game") {
+			return fmt.Errorf("--%s can only be used with --new-game", name)
+		}
+
+		if n < game.MinSize || n > game.MaxSize {
+			return fmt.Errorf("invalid --%s value: %d", name, n)
+		}
+	}
+
+	if options.Contains("new-game") && options.Contains("open") {
+		return fmt.Errorf("--new-game and --open cannot be used together")
+	}
+
+	return nil
+}
+
+func (app *Application) commandLine(cmdline gio.ApplicationCommandLine) int {
+	options := cmdline.OptionsDict()
+
+	app.Activate()
+
+	var errs []error
+
+	if port, _ := lookupPort(options, "web-port"); port != 0 {
+		if err := app.serveWeb(port); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if port, _ := lookupPort(options, "api-port"); port != 0 && app.api == nil {
+		if err := app.serveAPI(port); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if v := options.LookupValue("open", glib.NewVariantType("s")); v != nil {
+		_, path := v.String()
+
+		if err := app.openFile(path); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if options.Contains("new-game") {
+		size := app.settings.BoardSize()
+		if n, ok := lookupInt(options, "size"); ok {
+			size = uint(n)
+		}
+
+		wincond := app.settings.WinCond()
+		if n, ok := lookupInt(options, "wincond"); ok {
+			wincond = uint(n)
+		}
+
+		if err := app.newGameWith(size, wincond); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) != 0 {
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+		}
+
+		app.wview.SetStatus(fmt.Sprint("Error: ", errs[0].Error()))
+
+		return 1
+	}
+
+	return 0
+}
+
+func lookupInt(options *glib.VariantDict, name string) (int32, bool) {
+	v := options.LookupValue(name, glib.NewVariantType("i"))
+	if v == nil {
+		return 0, false
+	}
+
+	return v.Int32(), true
+}
+
+func lookupPort(options *glib.VariantDict, name string) (uint, error) {
+	port, ok := lookupInt(options, name)
+	if !ok {
+		return 0, nil
+	}
+
+	if port <= 0 || port > math.MaxUint16 {
+		return 0, fmt.Errorf("invalid --%s value: %d", name, port)
+	}
+
+	return uint(port), nil
+}
@@ -0,0 +1,146 @@
+package gomoku
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/diamondburned/gotk4/pkg/core/glib"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/solver"
+	"github.com/infastin/gomoku2go/internal/gomoku/view"
+)
+
+// outcomeOverlay shows on every empty cell of a small board whether a move
+// there wins, draws or loses with perfect play, solving one position at a
+// time.
+type outcomeOverlay struct {
+	active bool
+	solver *solver.Solver
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	// search counts the solves started, so that results of stale solves can
+	// be told apart.
+	search int
+}
+
+func (o *outcomeOverlay) stop() {
+	if o.cancel != nil {
+		o.cancel()
+		o.cancel = nil
+	}
+}
+
+func (app *Application) toggleOutcomes(enabled bool) {
+	if !enabled {
+		app.stopOutcomes()
+		return
+	}
+
+	if app.outcomes == nil {
+		app.outcomes = &outcomeOverlay{}
+	}
+
+	app.outcomes.active = true
+	app.solvePosition()
+}
+
+func (app *Application) stopOutcomes() {
+	if app.outcomes == nil || !app.outcomes.active {
+		return
+	}
+
+	app.outcomes.active = false
+	app.outcomes.stop()
+	app.outcomes.search++
+
+	app.gameView.Board().ClearOutcomes()
+	app.outcomesAction.SetChecked(false)
+}
+
+// solvePosition restarts solving the position shown on the board.
+func (app *Application) solvePosition() {
+	if app.outcomes == nil || !app.outcomes.active {
+		return
+	}
+
+	o := app.outcomes
+	o.stop()
+	o.search++
+
+	board := app.gameView.Board()
+	board.ClearOutcomes()
+
+	g := app.presenter.Position()
+	if g == nil || g.State() != game.NotFinished {
+		return
+	}
+
+	if !solver.CanSolve(g.Size(), g.WinCond()) {
+		app.wview.SetStatus(fmt.Sprintf("Error: only boards of at most %d cells can be solved", solver.MaxCells))
+		return
+	}
+
+	s := o.solver
+	if s == nil || s.Size() != g.Size() || s.WinCond() != g.WinCond() {
+		var err error
+		if s, err = solver.New(g.Size(), g.WinCond()); err != nil {
+			app.reportError(err)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	prev := o.done
+	done := make(chan struct{})
+	id := o.search
+
+	o.solver = s
+	o.cancel = cancel
+	o.done = done
+
+	go func() {
+		defer close(done)
+
+		// A solver runs one solve at a time.
+		if prev != nil {
+			<-prev
+		}
+
+		moves, err := s.Moves(ctx, g)
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+
+		glib.IdleAdd(func() {
+			if app.outcomes != o || o.search != id {
+				return
+			}
+
+			if err != nil {
+				app.wview.SetStatus(fmt.Sprint("Error: ", err.Error()))
+				return
+			}
+
+			res := make([]view.CellOutcome, len(moves))
+			for i, m := range moves {
+				res[i] = view.CellOutcome{X: m.X, Y: m.Y, Outcome: cellOutcome(m.Value)}
+			}
+
+			board.SetOutcomes(res)
+		})
+	}()
+}
+
+func cellOutcome(v solver.Value) view.Outcome {
+	switch v {
+	case solver.Win:
+		return view.OutcomeWin
+	case solver.Loss:
+		return view.OutcomeLoss
+	}
+
+	return view.OutcomeDraw
+}
@@ -0,0 +1,259 @@
+package gomoku
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/core/glib"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/clock"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/puzzle"
+	"github.com/infastin/gomoku2go/internal/gomoku/view"
+)
+
+// puzzleMode plays a puzzle: the board only takes the moves that keep the
+// goal within reach and the opponent answers on its own.
+type puzzleMode struct {
+	puzzle *puzzle.Puzzle
+	solved bool
+
+	// checking tells that a move is being checked, clicks are ignored
+	// meanwhile.
+	checking bool
+
+	// search counts the checks and replies started, so that results for
+	// earlier positions can be told apart.
+	search int
+}
+
+func (app *Application) showPuzzles() {
+	if app.peer != nil {
+		app.wview.SetStatus("Error: puzzles are not available in network games")
+		return
+	}
+
+	if app.puzzles == nil {
+		puzzles, err := puzzle.Bundled()
+		if err != nil {
+			app.reportError(err)
+			return
+		}
+
+		app.puzzles = puzzles
+	}
+
+	done := make(map[string]bool)
+	for _, id := range app.settings.SolvedPuzzles() {
+		done[id] = true
+	}
+
+	titles := make([]string, len(app.puzzles))
+	solved := make([]bool, len(app.puzzles))
+	count := 0
+
+	for i := range app.puzzles {
+		p := &app.puzzles[i]
+
+		titles[i] = fmt.Sprintf("%s — %s", p.Title, p.Description())
+		if p.Rating != 0 {
+			titles[i] += fmt.Sprintf(" %s%s",
+				strings.Repeat("★", p.Rating),
+				strings.Repeat("☆", puzzle.MaxRating-p.Rating))
+		}
+		solved[i] = done[p.ID]
+
+		if solved[i] {
+			count++
+		}
+	}
+
+	dialog := view.NewPuzzlesDialog(app.gameView)
+	dialog.ProgressLabel().SetText(fmt.Sprintf("Solved %d of %d", count, len(app.puzzles)))
+	dialog.SetPuzzles(titles, solved)
+	dialog.Show()
+
+	errorLabel := dialog.ErrorLabel()
+
+	dialog.CancelButton().ConnectClicked(func() {
+		dialog.Close()
+	})
+
+	dialog.ConfirmButton().ConnectClicked(func() {
+		idx := dialog.SelectedIndex()
+
+		if idx < 0 || idx >= len(app.puzzles) {
+			errorLabel.SetText("Error: select a puzzle")
+			errorLabel.SetVisible(true)
+			return
+		}
+
+		if err := app.startPuzzle(&app.puzzles[idx]); err != nil {
+			errorLabel.SetText(fmt.Sprint("Error: ", err.Error()))
+			errorLabel.SetVisible(true)
+			return
+		}
+
+		dialog.Close()
+	})
+}
+
+func (app *Application) startPuzzle(p *puzzle.Puzzle) error {
+	if app.peer != nil {
+		return fmt.Errorf("leave the network game first")
+	}
+
+	var players [2]*game.Player
+
+	pt := p.Player()
+	players[pt] = game.NewPlayer("You")
+	players[(pt+1)%2] = game.NewPlayer("Opponent")
+
+	g, err := p.Game(players[game.FirstPlayer], players[game.SecondPlayer])
+	if err != nil {
+		return err
+	}
+
+	app.stopPuzzle()
+	app.puzzle = &puzzleMode{puzzle: p}
+
+	// Puzzles are not timed, and the opponent moves through the puzzle.
+	app.presenter.SetTimeControl(clock.TimeControl{})
+	app.presenter.SetComputer(pt, false)
+	app.presenter.SetComputer((pt+1)%2, true)
+	app.presenter.StartGameWith(g)
+	app.presenter.SetFixedMoves(len(g.Moves()))
+
+	app.wview.SetStatus(fmt.Sprintf("%s: %s", p.Title, p.Description()))
+
+	return nil
+}
+
+// stopPuzzle leaves the puzzle mode, before another game starts.
+func (app *Application) stopPuzzle() {
+	if app.puzzle == nil {
+		return
+	}
+
+	app.puzzle.search++
+	app.puzzle = nil
+
+	app.presenter.SetTimeControl(app.settings.TimeControl())
+	app.syncComputers()
+}
+
+// puzzleUndone lets the puzzle go on after moves are taken back: the checks
+// and replies under way are dropped and a solved puzzle is open again.
+func (app *Application) puzzleUndone() {
+	pm := app.puzzle
+	pm.search++
+	pm.checking = false
+
+	if pm.solved {
+		pm.solved = false
+		app.presenter.Resume()
+	}
+
+	app.wview.SetStatus(fmt.Sprintf("%s: %s", pm.puzzle.Title, pm.puzzle.Description()))
+}
+
+// puzzleMove plays the move on the board if it is correct.
+func (app *Application) puzzleMove(x, y uint) {
+	pm := app.puzzle
+	g := app.presenter.Game()
+
+	if pm.solved || pm.checking || g.State() != game.NotFinished {
+		return
+	}
+
+	if g.CurrentPlayer() != pm.puzzle.Player() || app.presenter.ShownMoves() != len(g.Moves()) {
+		// Let the presenter tell what is wrong.
+		app.reportError(app.presenter.Click(x, y))
+		return
+	}
+
+	if ft, err := g.Field(x, y); err != nil || ft != game.EmptyField {
+		return
+	}
+
+	pm.checking = true
+	pm.search++
+
+	id := pm.search
+	position := g.Clone()
+
+	go func() {
+		ok, err := pm.puzzle.Check(position, x, y)
+
+		glib.IdleAdd(func() {
+			pm.checking = false
+
+			if app.puzzle != pm || pm.search != id {
+				return
+			}
+
+			if err != nil {
+				app.reportError(err)
+				return
+			}
+
+			if !ok {
+				app.wview.SetStatus("That is not it, try another move")
+				return
+			}
+
+			if err := app.presenter.Play(x, y); err != nil {
+				app.reportError(err)
+				return
+			}
+
+			if pm.puzzle.Solved(app.presenter.Game()) {
+				app.puzzleSolved()
+			}
+		})
+	}()
+}
+
+// puzzleReply makes the move of the opponent if it is their turn.
+func (app *Application) puzzleReply(g *game.Game) {
+	pm := app.puzzle
+
+	if pm.solved || pm.puzzle.Solved(g) || g.CurrentPlayer() == pm.puzzle.Player() {
+		return
+	}
+
+	id := pm.search
+	position := g.Clone()
+
+	go func() {
+		x, y, err := pm.puzzle.Reply(position)
+
+		glib.IdleAdd(func() {
+			if app.puzzle != pm || pm.search != id {
+				return
+			}
+
+			if err != nil {
+				app.reportError(err)
+				return
+			}
+
+			app.reportError(app.presenter.Play(x, y))
+		})
+	}()
+}
+
+func (app *Application) puzzleSolved() {
+	pm := app.puzzle
+	pm.solved = true
+
+	app.settings.SetPuzzleSolved(pm.puzzle.ID)
+
+	status := fmt.Sprintf("Puzzle solved: %s", pm.puzzle.Title)
+
+	if app.presenter.Game().State() == game.NotFinished {
+		app.presenter.Pause(status)
+	} else {
+		app.wview.SetStatus(status)
+	}
+}
@@ -0,0 +1,74 @@
+package gomoku
+
+import (
+	"fmt"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/record"
+)
+
+func (app *Application) saveGame() {
+	rec, err := app.presenter.Record()
+	if err != nil {
+		app.wview.SetStatus(fmt.Sprint("Error: ", err.Error()))
+		return
+	}
+
+	chooser := gtk.NewFileChooserNative("Save Game", &app.gameView.Window,
+		gtk.FileChooserActionSave, "_Save", "_Cancel")
+	chooser.SetCurrentName("game" + record.FileExtension)
+
+	chooser.ConnectResponse(func(response int) {
+		defer chooser.Destroy()
+
+		if response != int(gtk.ResponseAccept) {
+			return
+		}
+
+		if err := rec.Save(chooser.File().Path()); err != nil {
+			app.wview.SetStatus(fmt.Sprint("Error: ", err.Error()))
+		}
+	})
+
+	chooser.Show()
+}
+
+func (app *Application) openGame() {
+	chooser := gtk.NewFileChooserNative("Open Game", &app.gameView.Window,
+		gtk.FileChooserActionOpen, "_Open", "_Cancel")
+
+	chooser.ConnectResponse(func(response int) {
+		defer chooser.Destroy()
+
+		if response != int(gtk.ResponseAccept) {
+			return
+		}
+
+		app.reportError(app.openFile(chooser.File().Path()))
+	})
+
+	chooser.Show()
+}
+
+func (app *Application) openFile(path string) error {
+	if app.peer != nil {
+		return fmt.Errorf("leave the network game first")
+	}
+
+	rec, err := record.Load(path)
+	if err != nil {
+		return err
+	}
+
+	g, err := rec.Game()
+	if err != nil {
+		return err
+	}
+
+	app.stopPuzzle()
+	app.presenter.StartGameWith(g)
+	app.presenter.SetHintsUsed(rec.Hints)
+
+	return nil
+}
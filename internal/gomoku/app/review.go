@@ -0,0 +1,177 @@
+package gomoku
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/gotk4/pkg/core/glib"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/engine"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/view"
+)
+
+const reviewTime = 300 * time.Millisecond
+
+// gameReview is the analysis of every position of a game shown in a graph.
+type gameReview struct {
+	window *view.GraphWindow
+	cancel context.CancelFunc
+
+	// The reviewed game, to tell whether the board still shows it.
+	hash  uint64
+	moves int
+}
+
+func (app *Application) reviewGame() {
+	g := app.presenter.Game()
+
+	if g == nil || len(g.Moves()) == 0 {
+		app.wview.SetStatus("Error: there are no moves to analyze")
+		return
+	}
+
+	if app.peer != nil && g.State() == game.NotFinished {
+		app.wview.SetStatus("Error: the network game is not over yet")
+		return
+	}
+
+	if app.review != nil {
+		app.review.window.Close()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	win := view.NewGraphWindow(app.gameView)
+
+	r := &gameReview{
+		window: win,
+		cancel: cancel,
+		hash:   g.Hash(),
+		moves:  len(g.Moves()),
+	}
+
+	app.review = r
+
+	win.ConnectCloseRequest(func() bool {
+		cancel()
+
+		if app.review == r {
+			app.review = nil
+		}
+
+		return false
+	})
+
+	win.Graph().ConnectSelect(func(n int) {
+		if !app.reviewShown(r) {
+			win.StatusLabel().SetText("The game on the board has changed")
+			return
+		}
+
+		app.reportError(app.presenter.ShowMove(n))
+	})
+
+	win.StatusLabel().SetText("Analyzing…")
+	win.Show()
+
+	position := g.Clone()
+	threads := app.engineThreads()
+
+	go func() {
+		res, err := reviewPosition(ctx, position, threads, func(done, total int) {
+			glib.IdleAdd(func() {
+				if app.review == r {
+					win.StatusLabel().SetText(fmt.Sprintf("Analyzing position %d of %d…", done, total))
+				}
+			})
+		})
+
+		glib.IdleAdd(func() {
+			if app.review != r {
+				return
+			}
+
+			if err != nil {
+				win.StatusLabel().SetText(fmt.Sprint("Error: ", err.Error()))
+				return
+			}
+
+			app.showReview(r, position, res)
+		})
+	}()
+}
+
+func reviewPosition(ctx context.Context, g *game.Game, threads int, progress func(done, total int)) (engine.Review, error) {
+	e, err := engine.New(engine.DefaultTableSize)
+	if err != nil {
+		return engine.Review{}, err
+	}
+
+	if err := e.SetThreads(threads); err != nil {
+		return engine.Review{}, err
+	}
+
+	return e.Review(ctx, g, engine.Limits{Time: reviewTime}, progress)
+}
+
+func (app *Application) showReview(r *gameReview, g *game.Game, res engine.Review) {
+	values := make([]float64, len(res.Scores))
+	for i, s := range res.Scores {
+		values[i] = evalFraction(s)
+	}
+
+	var marks []view.GraphMark
+	var summary []string
+
+	for i, m := range res.Moves {
+		var kind view.GraphMarkKind
+
+		switch m.Kind {
+		case engine.Blunder:
+			kind = view.BlunderMark
+		case engine.MissedWin:
+			kind = view.MissedWinMark
+		default:
+			continue
+		}
+
+		marks = append(marks, view.GraphMark{Move: i + 1, Kind: kind})
+		summary = append(summary, fmt.Sprintf("Move %d, %s by %s: %s, %s was better",
+			i+1, m.Move, g.Player(m.Player).Name(), m.Kind, m.Best))
+	}
+
+	if len(summary) == 0 {
+		summary = append(summary, "No blunders or missed wins found")
+	}
+
+	graph := r.window.Graph()
+	graph.SetValues(values)
+	graph.SetMarks(marks)
+
+	if app.reviewShown(r) {
+		graph.SetCurrent(app.presenter.ShownMoves())
+	}
+
+	r.window.StatusLabel().SetText(fmt.Sprintf("%d positions analyzed, click the graph to go to a move", len(values)))
+	r.window.SummaryLabel().SetText(strings.Join(summary, "\n"))
+}
+
+// reviewShown tells whether the board shows the reviewed game.
+func (app *Application) reviewShown(r *gameReview) bool {
+	g := app.presenter.Game()
+	return g != nil && g.Hash() == r.hash && len(g.Moves()) == r.moves
+}
+
+func (app *Application) updateReview() {
+	if app.review == nil {
+		return
+	}
+
+	if app.reviewShown(app.review) {
+		app.review.window.Graph().SetCurrent(app.presenter.ShownMoves())
+	} else {
+		app.review.window.Graph().SetCurrent(-1)
+	}
+}
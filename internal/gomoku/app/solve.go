@@ -0,0 +1,100 @@
+package gomoku
+
+import (
+	"fmt"
+
+	"github.com/diamondburned/gotk4/pkg/core/glib"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/analysis"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+const (
+	forcedWinBudget = 200000
+	lineStepDelay   = 700
+	lineHoldSteps   = 4
+)
+
+func (app *Application) findForcedWin() {
+	g := app.presenter.Game()
+
+	if g == nil || g.State() != game.NotFinished {
+		app.wview.SetStatus("Error: there is no game in progress")
+		return
+	}
+
+	if app.searching {
+		return
+	}
+
+	app.searching = true
+	app.wview.SetStatus("Searching for a forced win…")
+
+	position := g.Clone()
+
+	go func() {
+		res := analysis.FindVCF(position, forcedWinBudget)
+		if !res.Found {
+			res = analysis.FindVCT(position, forcedWinBudget)
+		}
+
+		glib.IdleAdd(func() {
+			app.searching = false
+
+			// The game has moved on while searching.
+			if cur := app.presenter.Game(); cur == nil || cur.Hash() != position.Hash() || cur.Size() != position.Size() {
+				return
+			}
+
+			app.showForcedWin(position, res)
+		})
+	}()
+}
+
+func (app *Application) showForcedWin(position *game.Game, res analysis.ForcedWin) {
+	attacker := position.Player(position.CurrentPlayer()).Name()
+
+	if !res.Found {
+		if res.Exhausted {
+			app.wview.SetStatus(fmt.Sprintf("No forced win for %s found within the search limit", attacker))
+		} else {
+			app.wview.SetStatus(fmt.Sprintf("%s has no forced win", attacker))
+		}
+
+		return
+	}
+
+	app.presenter.StartPreview()
+
+	step := 0
+	pt := position.CurrentPlayer()
+
+	glib.TimeoutAdd(lineStepDelay, func() bool {
+		if app.presenter.Game() == nil || app.presenter.Game().Hash() != position.Hash() {
+			app.presenter.StopPreview()
+			return false
+		}
+
+		if step >= len(res.Moves) {
+			// Keep the final position on the board for a while.
+			step++
+
+			if step == len(res.Moves)+lineHoldSteps {
+				app.presenter.StopPreview()
+				return false
+			}
+
+			return true
+		}
+
+		m := res.Moves[step]
+		app.presenter.PreviewStone(m.X, m.Y, pt)
+
+		step++
+		pt = (pt + 1) % 2
+
+		app.wview.SetStatus(fmt.Sprintf("Forced win for %s: move %d of %d", attacker, step, len(res.Moves)))
+
+		return true
+	})
+}
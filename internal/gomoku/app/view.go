@@ -0,0 +1,111 @@
+package gomoku
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/clock"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/presenter"
+	"github.com/infastin/gomoku2go/internal/gomoku/view"
+)
+
+type windowView struct {
+	*view.MainWindow
+
+	click  func(x, y uint)
+	redraw func()
+
+	initialized bool
+}
+
+func newWindowView(mwin *view.MainWindow, click func(x, y uint), redraw func()) *windowView {
+	return &windowView{
+		MainWindow: mwin,
+		click:      click,
+		redraw:     redraw,
+	}
+}
+
+func (v *windowView) SetStatus(text string) {
+	v.CurrentPlayerLabel().SetText(text)
+}
+
+func (v *windowView) SetButtonLabel(label string) {
+	v.StartGameBtn().SetLabel(label)
+}
+
+func (v *windowView) InitBoard(size uint) {
+	board := v.Board()
+
+	if v.initialized {
+		board.Clear()
+	}
+
+	board.Init(size)
+	board.Draw()
+	board.ConnectClick(v.click)
+	board.ConnectRedraw(v.redraw)
+
+	v.initialized = true
+}
+
+func (v *windowView) SetInteractive(enabled bool) {
+	v.Board().SetClickable(enabled)
+}
+
+func (v *windowView) DrawStone(x, y uint, ft game.FieldType) {
+	switch ft {
+	case game.FirstPlayerField:
+		v.Board().DrawCircle(x, y)
+	case game.SecondPlayerField:
+		v.Board().DrawCross(x, y)
+	}
+}
+
+func (v *windowView) DrawStrike(s game.Strike) {
+	v.Board().DrawStrike(view.Strike(s))
+}
+
+func (v *windowView) StartClock() {
+	v.Stopwatch().Start()
+}
+
+func (v *windowView) StopClock() {
+	v.Stopwatch().Stop()
+}
+
+func (v *windowView) ResetClock() {
+	v.Stopwatch().Reset()
+}
+
+func (v *windowView) SetClock(elapsed time.Duration) {
+	v.Stopwatch().Set(elapsed)
+}
+
+func (v *windowView) ShowPlayerClocks(first, second time.Duration) {
+	label := v.PlayerClocksLabel()
+	label.SetText(fmt.Sprintf("○ %s  × %s", clock.FormatRemaining(first), clock.FormatRemaining(second)))
+	label.SetVisible(true)
+}
+
+func (v *windowView) HidePlayerClocks() {
+	v.PlayerClocksLabel().SetVisible(false)
+}
+
+func (v *windowView) AppendChat(t time.Time, from, text string) {
+	v.Chat().Append(t, from, text)
+}
+
+func (v *windowView) ShowHints(hints []presenter.Hint) {
+	res := make([]view.Hint, len(hints))
+	for i, h := range hints {
+		res[i] = view.Hint(h)
+	}
+
+	v.Board().SetHints(res)
+}
+
+func (v *windowView) ClearHints() {
+	v.Board().ClearHints()
+}
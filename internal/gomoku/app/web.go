@@ -0,0 +1,62 @@
+package gomoku
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/netplay"
+	"github.com/infastin/gomoku2go/internal/gomoku/view"
+	"github.com/infastin/gomoku2go/internal/gomoku/webplay"
+)
+
+func (app *Application) webGame() {
+	dialog := view.NewHostDialog(app.gameView)
+	dialog.SetTitle("Start Web Server")
+	dialog.ConfirmButton().SetLabel("Start")
+	dialog.Show()
+
+	portSB := dialog.PortSpinButton()
+	errorLabel := dialog.ErrorLabel()
+
+	portSB.SetValue(webplay.DefaultPort)
+
+	dialog.CancelButton().ConnectClicked(func() {
+		dialog.Close()
+	})
+
+	dialog.ConfirmButton().ConnectClicked(func() {
+		port := uint(math.Floor(portSB.Value()))
+
+		if err := app.serveWeb(port); err != nil {
+			errorLabel.SetText(fmt.Sprint("Error: ", err.Error()))
+			errorLabel.SetVisible(true)
+			return
+		}
+
+		dialog.Close()
+	})
+}
+
+func (app *Application) serveWeb(port uint) error {
+	settings := netplay.Settings{
+		Size:    app.settings.BoardSize(),
+		WinCond: app.settings.WinCond(),
+		Names:   [2]string{app.settings.FirstPlayerName(), ""},
+	}
+
+	app.leaveGame()
+
+	peer := netplay.NewHost("", settings)
+	app.connectPeer(peer)
+
+	server := webplay.NewServer(fmt.Sprintf(":%d", port), peer)
+	if err := server.Start(); err != nil {
+		return err
+	}
+
+	app.peer = peer
+	app.web = server
+	app.wview.SetStatus(fmt.Sprintf("Waiting for an opponent at http://localhost:%d", port))
+
+	return nil
+}
@@ -0,0 +1,353 @@
+// Package book implements opening books: weighted moves to play in the first
+// positions of a game.
+//
+// Positions are keyed by their smallest Zobrist hash over the 8 rotations and
+// reflections of the board, so a move learned in one position is also
+// played in all the positions symmetric to it. Moves are kept in the
+// orientation giving that smallest hash.
+package book
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/record"
+)
+
+const (
+	FormatVersion = 1
+
+	// DefaultPlies is how many moves of every game are learned when building
+	// a book.
+	DefaultPlies = 12
+)
+
+// Move weights learned from a game by the result for the player who made
+// the move.
+const (
+	winWeight  = 2
+	drawWeight = 1
+)
+
+type Move struct {
+	X      uint   `json:"x"`
+	Y      uint   `json:"y"`
+	Weight uint32 `json:"weight"`
+}
+
+type Book struct {
+	size      uint
+	winCond   uint
+	positions map[uint64][]Move
+}
+
+func New(size, winCond uint) (*Book, error) {
+	if err := game.CheckSettings(nil, nil, size, winCond); err != nil {
+		return nil, err
+	}
+
+	return &Book{
+		size:      size,
+		winCond:   winCond,
+		positions: make(map[uint64][]Move),
+	}, nil
+}
+
+func (b *Book) Size() uint {
+	return b.size
+}
+
+func (b *Book) WinCond() uint {
+	return b.winCond
+}
+
+// Len returns the number of positions in the book.
+func (b *Book) Len() int {
+	return len(b.positions)
+}
+
+func (b *Book) fits(g *game.Game) bool {
+	return g.Size() == b.size && g.WinCond() == b.winCond
+}
+
+// Add adds weight to the move (x, y) in the position of the game.
+func (b *Book) Add(g *game.Game, x, y uint, weight uint32) error {
+	if !b.fits(g) {
+		return fmt.Errorf("the book is for %dx%d boards with %d in a row", b.size, b.size, b.winCond)
+	}
+
+	bb := g.Bitboard()
+	key := bb.CanonicalHash()
+
+	// Symmetric moves in a symmetric position are the same move, keep the
+	// first of them.
+	m := Move{Weight: weight}
+
+	for i, t := range bb.Symmetries() {
+		tx, ty := t.Apply(x, y, b.size)
+
+		if i == 0 || ty < m.Y || ty == m.Y && tx < m.X {
+			m.X, m.Y = tx, ty
+		}
+	}
+
+	b.add(key, m)
+
+	return nil
+}
+
+func (b *Book) add(key uint64, m Move) {
+	moves := b.positions[key]
+
+	for i := range moves {
+		if moves[i].X == m.X && moves[i].Y == m.Y {
+			moves[i].Weight += m.Weight
+			return
+		}
+	}
+
+	b.positions[key] = append(moves, m)
+}
+
+// AddRecord learns the first plies moves of a recorded game. Moves of the
+// winner weigh more than moves of a draw, moves of the loser are left out.
+func (b *Book) AddRecord(r *record.Record, plies int) error {
+	if r.Size != b.size || r.WinCond != b.winCond {
+		return fmt.Errorf("the game is played on a %dx%d board with %d in a row, the book is for %dx%d boards with %d in a row",
+			r.Size, r.Size, r.WinCond, b.size, b.size, b.winCond)
+	}
+
+	// Checks that the moves and the result are valid.
+	if _, err := r.Game(); err != nil {
+		return err
+	}
+
+	g, err := game.NewGame(game.NewPlayer(r.Players[0]), game.NewPlayer(r.Players[1]), r.Size, r.WinCond)
+	if err != nil {
+		return err
+	}
+
+	for i, m := range r.Moves {
+		if i >= plies || g.State() != game.NotFinished {
+			break
+		}
+
+		var weight uint32
+
+		switch r.Result {
+		case game.GameState(g.CurrentPlayer()) + 1:
+			weight = winWeight
+		case game.NobodyWins, game.NotFinished:
+			weight = drawWeight
+		}
+
+		if weight != 0 {
+			b.Add(g, m.X, m.Y, weight)
+		}
+
+		if err := g.MakeMove(m.X, m.Y); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Merge adds the weights of the other book to this one.
+func (b *Book) Merge(o *Book) error {
+	if o.size != b.size || o.winCond != b.winCond {
+		return fmt.Errorf("cannot merge a book for %dx%d boards with %d in a row into a book for %dx%d boards with %d in a row",
+			o.size, o.size, o.winCond, b.size, b.size, b.winCond)
+	}
+
+	for key, moves := range o.positions {
+		for _, m := range moves {
+			b.add(key, m)
+		}
+	}
+
+	return nil
+}
+
+// Moves returns the book moves in the position of the game, the heaviest
+// first.
+func (b *Book) Moves(g *game.Game) []Move {
+	if !b.fits(g) || g.State() != game.NotFinished {
+		return nil
+	}
+
+	key, t := g.Canonical()
+	inv := t.Inverse()
+
+	var res []Move
+
+	for _, m := range b.positions[key] {
+		x, y := inv.Apply(m.X, m.Y, b.size)
+
+		// A broken book must not make an illegal move.
+		if ft, err := g.Field(x, y); err != nil || ft != game.EmptyField {
+			continue
+		}
+
+		res = append(res, Move{X: x, Y: y, Weight: m.Weight})
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Weight > res[j].Weight
+	})
+
+	return res
+}
+
+// Pick chooses one of the book moves in the position of the game at random,
+// in proportion to their weights.
+func (b *Book) Pick(g *game.Game, rng *rand.Rand) (Move, bool) {
+	moves := b.Moves(g)
+
+	var total uint64
+	for _, m := range moves {
+		total += uint64(m.Weight)
+	}
+
+	if total == 0 {
+		return Move{}, false
+	}
+
+	n := uint64(rng.Int63n(int64(total)))
+
+	for _, m := range moves {
+		if n < uint64(m.Weight) {
+			return m, true
+		}
+
+		n -= uint64(m.Weight)
+	}
+
+	return Move{}, false
+}
+
+type fileBook struct {
+	Version   int            `json:"version"`
+	Size      uint           `json:"size"`
+	WinCond   uint           `json:"wincond"`
+	Positions []filePosition `json:"positions"`
+}
+
+type filePosition struct {
+	// Key is the hexadecimal hash of the position, JSON numbers cannot hold
+	// all 64 bits.
+	Key   string `json:"key"`
+	Moves []Move `json:"moves"`
+}
+
+func Read(rd io.Reader) (*Book, error) {
+	var f fileBook
+
+	if err := json.NewDecoder(rd).Decode(&f); err != nil {
+		return nil, fmt.Errorf("malformed opening book: %v", err)
+	}
+
+	if f.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported opening book version %d", f.Version)
+	}
+
+	b, err := New(f.Size, f.WinCond)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range f.Positions {
+		key, err := strconv.ParseUint(p.Key, 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed opening book: invalid position key %q", p.Key)
+		}
+
+		for _, m := range p.Moves {
+			if m.X >= b.size || m.Y >= b.size {
+				return nil, fmt.Errorf("malformed opening book: move (%d, %d) is out of board bounds", m.X, m.Y)
+			}
+
+			b.add(key, m)
+		}
+	}
+
+	return b, nil
+}
+
+func Load(path string) (*Book, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+func (b *Book) Write(w io.Writer) error {
+	f := fileBook{
+		Version:   FormatVersion,
+		Size:      b.size,
+		WinCond:   b.winCond,
+		Positions: make([]filePosition, 0, len(b.positions)),
+	}
+
+	keys := make([]uint64, 0, len(b.positions))
+	for key := range b.positions {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		f.Positions = append(f.Positions, filePosition{
+			Key:   strconv.FormatUint(key, 16),
+			Moves: b.positions[key],
+		})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+
+	return enc.Encode(&f)
+}
+
+func (b *Book) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := b.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// DefaultPath returns where the application looks for its opening book:
+// book.json in the gomoku2go directory of the user data directory.
+func DefaultPath() (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+
+		dir = filepath.Join(home, ".local", "share")
+	}
+
+	return filepath.Join(dir, "gomoku2go", "book.json"), nil
+}
@@ -0,0 +1,150 @@
+package book
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+func TestSymmetry(t *testing.T) {
+	g, _ := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 9, 5)
+	g.MakeMove(2, 3)
+	g.MakeMove(5, 1)
+
+	b, _ := New(9, 5)
+
+	if err := b.Add(g, 6, 6, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	for tr := game.Identity; tr < game.TransformCount; tr++ {
+		gt := g.Transform(tr)
+		x, y := tr.Apply(6, 6, 9)
+
+		want := []Move{{X: x, Y: y, Weight: 3}}
+		if got := b.Moves(gt); !reflect.DeepEqual(got, want) {
+			t.Errorf("%v: book moves are %v, want %v", tr, got, want)
+		}
+	}
+
+	// The same move learned in a symmetric position adds up.
+	for tr := game.Identity; tr < game.TransformCount; tr++ {
+		x, y := tr.Apply(6, 6, 9)
+		b.Add(g.Transform(tr), x, y, 1)
+	}
+
+	want := []Move{{X: 6, Y: 6, Weight: 11}}
+	if got := b.Moves(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("book moves are %v after adding every symmetric move, want %v", got, want)
+	}
+
+	if b.Len() != 1 {
+		t.Errorf("the book has %d positions, want 1", b.Len())
+	}
+}
+
+func TestSymmetricMoves(t *testing.T) {
+	g, _ := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 9, 5)
+	b, _ := New(9, 5)
+
+	// All corners of the empty board are the same move.
+	for _, m := range [][2]uint{{0, 0}, {8, 0}, {0, 8}, {8, 8}} {
+		b.Add(g, m[0], m[1], 1)
+	}
+
+	moves := b.Moves(g)
+	if len(moves) != 1 || moves[0].Weight != 4 {
+		t.Fatalf("book moves are %v, want one corner with weight 4", moves)
+	}
+
+	if x, y := moves[0].X, moves[0].Y; (x != 0 && x != 8) || (y != 0 && y != 8) {
+		t.Errorf("book move %d,%d is not a corner", x, y)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	g, _ := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 9, 5)
+	// No symmetry keeps this position, so the moves keep their squares.
+	g.MakeMove(2, 3)
+
+	a, _ := New(9, 5)
+	a.Add(g, 3, 3, 1)
+	a.Add(g, 4, 5, 2)
+
+	b, _ := New(9, 5)
+	b.Add(g, 3, 3, 4)
+	b.Add(g, 2, 7, 1)
+
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Move{{X: 3, Y: 3, Weight: 5}, {X: 4, Y: 5, Weight: 2}, {X: 2, Y: 7, Weight: 1}}
+	if got := a.Moves(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("merged book moves are %v, want %v", got, want)
+	}
+
+	// The other book is left alone.
+	want = []Move{{X: 3, Y: 3, Weight: 4}, {X: 2, Y: 7, Weight: 1}}
+	if got := b.Moves(g); !reflect.DeepEqual(got, want) {
+		t.Errorf("book moves are %v after merging them into another book, want %v", got, want)
+	}
+
+	c, _ := New(15, 5)
+	if err := a.Merge(c); err == nil {
+		t.Error("merged a book for another board size")
+	}
+}
+
+func TestReadWrite(t *testing.T) {
+	g, _ := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 9, 5)
+	b, _ := New(9, 5)
+
+	b.Add(g, 4, 4, 7)
+	g.MakeMove(4, 4)
+	b.Add(g, 3, 3, 2)
+	b.Add(g, 5, 4, 1)
+	g.MakeMove(5, 4)
+	b.Add(g, 6, 6, 1)
+
+	var buf bytes.Buffer
+
+	if err := b.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Size() != 9 || r.WinCond() != 5 || !reflect.DeepEqual(r.positions, b.positions) {
+		t.Errorf("read book differs from the written one")
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		err  string
+	}{
+		{"version", `{"version": 2, "size": 9, "wincond": 5}`, "unsupported opening book version 2"},
+		{"json", `{"version": 1,`, "malformed opening book"},
+		{"settings", `{"version": 1, "size": 9, "wincond": 10}`, ""},
+		{"key", `{"version": 1, "size": 9, "wincond": 5, "positions": [{"key": "xyz", "moves": []}]}`, "invalid position key"},
+		{"move", `{"version": 1, "size": 9, "wincond": 5, "positions": [{"key": "1f", "moves": [{"x": 9, "y": 0, "weight": 1}]}]}`,
+			"out of board bounds"},
+	}
+
+	for _, tt := range tests {
+		_, err := Read(strings.NewReader(tt.in))
+
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: read error is %v, want an error with %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package clock
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// TimeControl gives every player Base time for the game, and adds Increment
+// after each of their moves. A zero Base means the game is not timed.
+type TimeControl struct {
+	Base      time.Duration
+	Increment time.Duration
+}
+
+func (tc TimeControl) Timed() bool {
+	return tc.Base > 0
+}
+
+func (tc TimeControl) String() string {
+	if !tc.Timed() {
+		return "untimed"
+	}
+
+	return fmt.Sprintf("%v+%v", tc.Base, tc.Increment)
+}
+
+// ChessClock counts down the time left to each player, running for one of
+// them at a time.
+type ChessClock struct {
+	tc        TimeControl
+	remaining [2]time.Duration
+	turn      game.PlayerType
+	since     time.Time
+	running   bool
+
+	// now tells the time, tests set their own.
+	now func() time.Time
+}
+
+func NewChessClock(tc TimeControl) *ChessClock {
+	return &ChessClock{
+		tc:        tc,
+		remaining: [2]time.Duration{tc.Base, tc.Base},
+		now:       time.Now,
+	}
+}
+
+func (c *ChessClock) Control() TimeControl {
+	return c.tc
+}
+
+// Start runs the clock of the player, stopping the other one.
+func (c *ChessClock) Start(pt game.PlayerType) {
+	c.Stop()
+
+	c.turn = pt
+	c.since = c.now()
+	c.running = true
+}
+
+func (c *ChessClock) Stop() {
+	if c.running {
+		c.running = false
+		c.remaining[c.turn] -= c.now().Sub(c.since)
+	}
+}
+
+// Press ends the move of the player whose clock runs: the increment is
+// added to their time and the clock of the opponent starts.
+func (c *ChessClock) Press() {
+	if !c.running {
+		return
+	}
+
+	pt := c.turn
+
+	c.Stop()
+	c.remaining[pt] += c.tc.Increment
+	c.Start((pt + 1) % 2)
+}
+
+func (c *ChessClock) Running() bool {
+	return c.running
+}
+
+// Turn returns the player whose clock runs or ran last.
+func (c *ChessClock) Turn() game.PlayerType {
+	return c.turn
+}
+
+// Remaining returns the time left to the player, which is negative once
+// they have run out of time.
+func (c *ChessClock) Remaining(pt game.PlayerType) time.Duration {
+	if c.running && pt == c.turn {
+		return c.remaining[pt] - c.now().Sub(c.since)
+	}
+
+	return c.remaining[pt]
+}
+
+// Expired tells whether the player has run out of time.
+func (c *ChessClock) Expired(pt game.PlayerType) bool {
+	return c.Remaining(pt) <= 0
+}
+
+// FormatRemaining formats the time left like a clock face, with tenths of a
+// second when less than ten seconds are left.
+func FormatRemaining(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+
+	if d < 10*time.Second {
+		return fmt.Sprintf("%d.%d", d/time.Second, d%time.Second/(100*time.Millisecond))
+	}
+
+	secs := int(d / time.Second)
+	if secs >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+	}
+
+	return fmt.Sprintf("%02d:%02d", secs/60, secs%60)
+}
@@ -0,0 +1,116 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// fakeTime is a clock moved by hand.
+type fakeTime struct {
+	t time.Time
+}
+
+func (f *fakeTime) now() time.Time {
+	return f.t
+}
+
+func (f *fakeTime) advance(d time.Duration) {
+	f.t = f.t.Add(d)
+}
+
+func TestChessClock(t *testing.T) {
+	ft := &fakeTime{t: time.Unix(1000, 0)}
+	c := NewChessClock(TimeControl{Base: time.Minute, Increment: 2 * time.Second})
+	c.now = ft.now
+
+	if c.Running() || c.Remaining(game.FirstPlayer) != time.Minute {
+		t.Fatalf("a new clock runs or has %v left", c.Remaining(game.FirstPlayer))
+	}
+
+	c.Start(game.FirstPlayer)
+	ft.advance(10 * time.Second)
+
+	if r := c.Remaining(game.FirstPlayer); r != 50*time.Second {
+		t.Errorf("the first player has %v left, want 50s", r)
+	}
+
+	if r := c.Remaining(game.SecondPlayer); r != time.Minute {
+		t.Errorf("the second player has %v left while waiting, want 1m", r)
+	}
+
+	// Pressing adds the increment and starts the clock of the opponent.
+	c.Press()
+	ft.advance(5 * time.Second)
+
+	if c.Turn() != game.SecondPlayer {
+		t.Errorf("the clock runs for %v after the press", c.Turn())
+	}
+
+	if r := c.Remaining(game.FirstPlayer); r != 52*time.Second {
+		t.Errorf("the first player has %v left, want 52s", r)
+	}
+
+	if r := c.Remaining(game.SecondPlayer); r != 55*time.Second {
+		t.Errorf("the second player has %v left, want 55s", r)
+	}
+
+	// A stopped clock keeps its time and a press does nothing.
+	c.Stop()
+	ft.advance(time.Hour)
+	c.Press()
+
+	if c.Running() || c.Remaining(game.SecondPlayer) != 55*time.Second || c.Remaining(game.FirstPlayer) != 52*time.Second {
+		t.Errorf("the stopped clock shows %v and %v", c.Remaining(game.FirstPlayer), c.Remaining(game.SecondPlayer))
+	}
+}
+
+func TestChessClockExpiry(t *testing.T) {
+	ft := &fakeTime{t: time.Unix(1000, 0)}
+	c := NewChessClock(TimeControl{Base: 10 * time.Second})
+	c.now = ft.now
+
+	c.Start(game.SecondPlayer)
+	ft.advance(10*time.Second - time.Millisecond)
+
+	if c.Expired(game.SecondPlayer) {
+		t.Error("the time ran out early")
+	}
+
+	ft.advance(time.Millisecond)
+
+	if !c.Expired(game.SecondPlayer) {
+		t.Error("the time did not run out with nothing left")
+	}
+
+	if c.Expired(game.FirstPlayer) {
+		t.Error("the time of the waiting player ran out")
+	}
+
+	ft.advance(time.Second)
+	c.Stop()
+
+	if r := c.Remaining(game.SecondPlayer); r != -time.Second {
+		t.Errorf("the second player has %v left, want -1s", r)
+	}
+}
+
+func TestFormatRemaining(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{-time.Second, "0.0"},
+		{9*time.Second + 950*time.Millisecond, "9.9"},
+		{10 * time.Second, "00:10"},
+		{5*time.Minute + 3*time.Second, "05:03"},
+		{time.Hour + 2*time.Minute + 3*time.Second, "1:02:03"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatRemaining(tt.d); got != tt.want {
+			t.Errorf("FormatRemaining(%v) is %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
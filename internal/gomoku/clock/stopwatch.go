@@ -0,0 +1,56 @@
+package clock
+
+import (
+	"fmt"
+	"time"
+)
+
+type Stopwatch struct {
+	elapsed time.Duration
+	since   time.Time
+	running bool
+}
+
+func (s *Stopwatch) Start() {
+	if !s.running {
+		s.running = true
+		s.since = time.Now()
+	}
+}
+
+func (s *Stopwatch) Stop() {
+	if s.running {
+		s.running = false
+		s.elapsed += time.Since(s.since)
+	}
+}
+
+func (s *Stopwatch) Reset() {
+	s.running = false
+	s.elapsed = 0
+}
+
+func (s *Stopwatch) Set(elapsed time.Duration) {
+	s.elapsed = elapsed
+
+	if s.running {
+		s.since = time.Now()
+	}
+}
+
+func (s *Stopwatch) Running() bool {
+	return s.running
+}
+
+func (s *Stopwatch) Elapsed() time.Duration {
+	if s.running {
+		return s.elapsed + time.Since(s.since)
+	}
+
+	return s.elapsed
+}
+
+func (s *Stopwatch) String() string {
+	secs := int(s.Elapsed() / time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", secs/3600, secs/60%60, secs%60)
+}
@@ -0,0 +1,194 @@
+// Package engine searches gomoku positions for the best moves.
+//
+// The search is an iterative deepening alpha-beta search over a bitboard.
+// Positions are scored by the windows each player can still fill: the fewer
+// stones a window is missing, the more it is worth.
+//
+// Searches can run on several threads sharing the transposition table (Lazy
+// SMP): helper threads search the same position and only fill the table, the
+// main thread finds the moves sooner thanks to them.
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/book"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/solver"
+	"github.com/infastin/gomoku2go/internal/gomoku/tt"
+)
+
+const (
+	DefaultTableSize = 16 << 20
+
+	// MaxDepth is the deepest iteration the search goes to.
+	MaxDepth = 32
+
+	// MaxThreads is the largest number of threads a search runs on.
+	MaxThreads = 64
+)
+
+type Move struct {
+	X, Y uint
+}
+
+// String formats the move as a column letter followed by a row number.
+func (m Move) String() string {
+	return fmt.Sprintf("%c%d", 'a'+rune(m.X), m.Y+1)
+}
+
+// Line is a root move with its score and the principal variation starting
+// with it.
+type Line struct {
+	Move  Move
+	Score int
+	PV    []Move
+}
+
+type Result struct {
+	// Lines are sorted from the best move down.
+	Lines   []Line
+	Depth   int
+	Nodes   int
+	Elapsed time.Duration
+}
+
+// Best returns the best line of the result.
+func (r Result) Best() (Line, bool) {
+	if len(r.Lines) == 0 {
+		return Line{}, false
+	}
+
+	return r.Lines[0], true
+}
+
+// Limits bound a search. A zero limit means there is no such limit, though
+// the search never goes deeper than MaxDepth.
+type Limits struct {
+	Depth int
+	Time  time.Duration
+	Nodes int
+
+	// SoftTime is the time after which no new iteration is started, as it
+	// would likely not finish in time. While the best move keeps changing
+	// the search goes on, though never beyond Time.
+	SoftTime time.Duration
+
+	// MultiPV is the number of best moves to find scores for. It is 1 when
+	// zero.
+	MultiPV int
+}
+
+// Engine keeps a transposition table between searches. An engine runs one
+// search at a time.
+type Engine struct {
+	table   *tt.Table
+	rng     *rand.Rand
+	book    *book.Book
+	threads int
+
+	// solver plays PerfectLevel on small boards.
+	solver *solver.Solver
+}
+
+// New creates an engine with a transposition table of at most tableSize
+// bytes.
+func New(tableSize int) (*Engine, error) {
+	table, err := tt.New(tableSize, tt.TwoTier)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Engine{
+		table:   table,
+		rng:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		threads: 1,
+	}, nil
+}
+
+// SetThreads sets the number of threads searches run on, from 1 to
+// MaxThreads.
+func (e *Engine) SetThreads(n int) error {
+	if n < 1 || n > MaxThreads {
+		return fmt.Errorf("the number of threads must be between 1 and %d", MaxThreads)
+	}
+
+	e.threads = n
+
+	return nil
+}
+
+func (e *Engine) Threads() int {
+	return e.threads
+}
+
+// Seed makes the random choices of the engine repeatable.
+func (e *Engine) Seed(seed int64) {
+	e.rng.Seed(seed)
+}
+
+// SetBook sets the opening book Play consults before searching, or no book
+// if b is nil.
+func (e *Engine) SetBook(b *book.Book) {
+	e.book = b
+}
+
+// Clear forgets everything learned in earlier searches.
+func (e *Engine) Clear() {
+	e.table.Clear()
+}
+
+// Search looks for the best moves of the player to move. After every
+// finished iteration info, if not nil, is called with the result so far.
+//
+// The search stops when a limit is reached or the context is done, and
+// returns the result of the last finished iteration. The first iteration is
+// always finished.
+func (e *Engine) Search(ctx context.Context, g *game.Game, limits Limits, info func(Result)) (Result, error) {
+	if g.State() != game.NotFinished {
+		return Result{}, fmt.Errorf("the game is over")
+	}
+
+	return e.search(ctx, g, limits, info, true), nil
+}
+
+func (e *Engine) search(ctx context.Context, g *game.Game, limits Limits, info func(Result), finishFirst bool) Result {
+	e.table.NewSearch()
+
+	var total int64
+
+	main := newSearcher(ctx, e.table, g.Bitboard(), limits, 0, &total)
+	main.finishFirst = finishFirst
+
+	// Helpers stop when the main thread is done.
+	hctx, cancel := context.WithCancel(ctx)
+	helpers := make([]*searcher, e.threads-1)
+
+	var wg sync.WaitGroup
+
+	for i := range helpers {
+		h := newSearcher(hctx, e.table, g.Bitboard(), limits, i+1, &total)
+		helpers[i] = h
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h.run(nil)
+		}()
+	}
+
+	res := main.run(info)
+
+	cancel()
+	wg.Wait()
+
+	for _, h := range helpers {
+		res.Nodes += h.nodes
+	}
+
+	return res
+}
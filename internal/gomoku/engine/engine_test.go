@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// testGame plays the moves on a 15x15 board with five in a row.
+func testGame(t testing.TB, moves ...[2]uint) *game.Game {
+	t.Helper()
+
+	g, err := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 15, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range moves {
+		if err := g.MakeMove(m[0], m[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return g
+}
+
+// middlegame is a position with no forced win close at hand.
+var middlegame = [][2]uint{{7, 7}, {8, 8}, {8, 6}, {6, 8}, {7, 8}, {7, 6}, {9, 7}, {6, 7}}
+
+func TestSetThreads(t *testing.T) {
+	e, _ := New(1 << 20)
+
+	for _, n := range []int{0, -1, MaxThreads + 1} {
+		if err := e.SetThreads(n); err == nil {
+			t.Errorf("%d threads were accepted", n)
+		}
+	}
+
+	if err := e.SetThreads(MaxThreads); err != nil || e.Threads() != MaxThreads {
+		t.Errorf("setting %d threads: %v, the engine has %d", MaxThreads, err, e.Threads())
+	}
+}
+
+// TestParallelSearch searches on several threads sharing the table, for the
+// race detector to look at.
+func TestParallelSearch(t *testing.T) {
+	g := testGame(t, middlegame...)
+
+	for _, threads := range []int{1, 4} {
+		e, _ := New(1 << 20)
+		e.SetThreads(threads)
+
+		res, err := e.Search(context.Background(), g, Limits{Depth: 4, MultiPV: 3}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Depth != 4 || len(res.Lines) != 3 {
+			t.Errorf("%d threads: depth %d with %d lines, want 4 with 3", threads, res.Depth, len(res.Lines))
+		}
+
+		for i := 1; i < len(res.Lines); i++ {
+			if res.Lines[i].Score > res.Lines[i-1].Score {
+				t.Errorf("%d threads: the lines are not sorted: %v", threads, res.Lines)
+			}
+		}
+
+		if ft, _ := g.Field(res.Lines[0].Move.X, res.Lines[0].Move.Y); ft != game.EmptyField {
+			t.Errorf("%d threads: the best move %v is taken", threads, res.Lines[0].Move)
+		}
+	}
+}
+
+func TestParallelSearchFindsWins(t *testing.T) {
+	// The first player has an open four to complete.
+	g := testGame(t, [2]uint{7, 7}, [2]uint{0, 0}, [2]uint{8, 7}, [2]uint{0, 2}, [2]uint{9, 7}, [2]uint{0, 4}, [2]uint{10, 7}, [2]uint{0, 6})
+
+	for _, threads := range []int{1, 2, 8} {
+		e, _ := New(1 << 20)
+		e.SetThreads(threads)
+
+		res, err := e.Search(context.Background(), g, Limits{Depth: 6}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		best, ok := res.Best()
+		if !ok || !IsWin(best.Score) || MovesToEnd(best.Score) != 1 {
+			t.Fatalf("%d threads: best line %+v, want a win in 1", threads, best)
+		}
+
+		if m := best.Move; m.Y != 7 || (m.X != 6 && m.X != 11) {
+			t.Errorf("%d threads: the winning move is %v", threads, m)
+		}
+	}
+}
+
+func TestSearchCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	e, _ := New(1 << 20)
+	e.SetThreads(4)
+
+	// The first iteration is finished even when the search is cancelled.
+	res, err := e.Search(ctx, testGame(t, middlegame...), Limits{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := res.Best(); !ok || res.Depth < 1 {
+		t.Errorf("a cancelled search returned depth %d with %d lines", res.Depth, len(res.Lines))
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	g := testGame(b, middlegame...)
+
+	for _, threads := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("threads=%d", threads), func(b *testing.B) {
+			e, _ := New(1 << 20)
+			e.SetThreads(threads)
+			nodes := 0
+
+			for i := 0; i < b.N; i++ {
+				e.Clear()
+
+				res, err := e.Search(context.Background(), g, Limits{Depth: 5}, nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				nodes += res.Nodes
+			}
+
+			b.ReportMetric(float64(nodes)/float64(b.N), "nodes/op")
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/solver"
+)
+
+const (
+	MinLevel = 1
+	MaxLevel = 10
+
+	// PerfectLevel plays perfectly on boards small enough to be solved and
+	// like MaxLevel on the others.
+	PerfectLevel = MaxLevel + 1
+)
+
+// level describes how well the engine plays at a difficulty level.
+type level struct {
+	depth int
+	time  time.Duration
+
+	// The engine picks at random among the moves scoring within noise of the
+	// best one, out of the lines best moves.
+	lines int
+	noise int
+
+	// mistake is the chance, in percent, of playing any of the lines that
+	// does not lose right away instead.
+	mistake int
+}
+
+var levels = [MaxLevel + 1]level{
+	1:  {depth: 1, time: 100 * time.Millisecond, lines: 8, noise: 3000, mistake: 35},
+	2:  {depth: 1, time: 100 * time.Millisecond, lines: 6, noise: 2000, mistake: 25},
+	3:  {depth: 2, time: 200 * time.Millisecond, lines: 5, noise: 1500, mistake: 20},
+	4:  {depth: 2, time: 300 * time.Millisecond, lines: 4, noise: 1000, mistake: 15},
+	5:  {depth: 3, time: 400 * time.Millisecond, lines: 4, noise: 600, mistake: 10},
+	6:  {depth: 4, time: 500 * time.Millisecond, lines: 3, noise: 400, mistake: 7},
+	7:  {depth: 5, time: 700 * time.Millisecond, lines: 3, noise: 200, mistake: 4},
+	8:  {depth: 6, time: time.Second, lines: 2, noise: 100, mistake: 2},
+	9:  {depth: 8, time: 1500 * time.Millisecond, lines: 1},
+	10: {time: 2500 * time.Millisecond, lines: 1},
+}
+
+func CheckLevel(lvl int) error {
+	if lvl < MinLevel || lvl > PerfectLevel {
+		return fmt.Errorf("difficulty level must be between %d and %d, or %d for perfect play", MinLevel, MaxLevel, PerfectLevel)
+	}
+
+	return nil
+}
+
+// LevelLimits returns the limits of a search at the difficulty level.
+func LevelLimits(lvl int) (Limits, error) {
+	if err := CheckLevel(lvl); err != nil {
+		return Limits{}, err
+	}
+
+	if lvl == PerfectLevel {
+		lvl = MaxLevel
+	}
+
+	l := levels[lvl]
+
+	return Limits{
+		Depth:   l.depth,
+		Time:    l.time,
+		MultiPV: l.lines,
+	}, nil
+}
+
+// Play chooses a move for the player to move at the difficulty level. Lower
+// levels search less, pick among good moves at random and now and then make
+// a mistake on purpose, but never miss a win in one move. Positions found in
+// the opening book are not searched at all.
+//
+// In a timed game tl is the clock of the player to move, the engine then
+// never thinks longer than the clock allows.
+func (e *Engine) Play(ctx context.Context, g *game.Game, lvl int, tl TimeLeft) (Move, error) {
+	if err := CheckLevel(lvl); err != nil {
+		return Move{}, err
+	}
+
+	if lvl == PerfectLevel {
+		if m, err := e.solve(ctx, g); err == nil || ctx.Err() != nil {
+			return m, err
+		}
+
+		// The board is too large to solve, play as well as searching allows.
+		lvl = MaxLevel
+	}
+
+	limits, err := LevelLimits(lvl)
+	if err != nil {
+		return Move{}, err
+	}
+
+	if tl.timed() {
+		soft, hard := Allocate(tl, g, Critical(g))
+
+		// The top level uses the clock in full, lower levels think briefly
+		// unless the clock is even shorter.
+		if lvl == MaxLevel || limits.Time > soft {
+			limits.Time = hard
+			limits.SoftTime = soft
+		}
+	}
+
+	if e.book != nil {
+		if m, ok := e.book.Pick(g, e.rng); ok {
+			return Move{X: m.X, Y: m.Y}, nil
+		}
+	}
+
+	res, err := e.Search(ctx, g, limits, nil)
+	if err != nil {
+		return Move{}, err
+	}
+
+	best, ok := res.Best()
+	if !ok {
+		return Move{}, fmt.Errorf("there are no moves")
+	}
+
+	if IsWin(best.Score) && MovesToEnd(best.Score) == 1 {
+		return best.Move, nil
+	}
+
+	l := levels[lvl]
+
+	var candidates []Line
+
+	if l.mistake > 0 && e.rng.Intn(100) < l.mistake {
+		for _, line := range res.Lines[1:] {
+			if !IsLoss(line.Score) {
+				candidates = append(candidates, line)
+			}
+		}
+	}
+
+	if len(candidates) == 0 {
+		for _, line := range res.Lines {
+			if best.Score-line.Score <= l.noise && IsLoss(line.Score) == IsLoss(best.Score) {
+				candidates = append(candidates, line)
+			}
+		}
+	}
+
+	return candidates[e.rng.Intn(len(candidates))].Move, nil
+}
+
+// solve returns a move keeping the value of the position with perfect play.
+func (e *Engine) solve(ctx context.Context, g *game.Game) (Move, error) {
+	if !solver.CanSolve(g.Size(), g.WinCond()) {
+		return Move{}, fmt.Errorf("the board is too large to solve")
+	}
+
+	if e.solver == nil || e.solver.Size() != g.Size() || e.solver.WinCond() != g.WinCond() {
+		s, err := solver.New(g.Size(), g.WinCond())
+		if err != nil {
+			return Move{}, err
+		}
+
+		e.solver = s
+	}
+
+	m, err := e.solver.Best(ctx, g)
+	if err != nil {
+		return Move{}, err
+	}
+
+	return Move{X: m.X, Y: m.Y}, nil
+}
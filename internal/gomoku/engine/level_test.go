@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"context"
+	"testing"
+)
+
+func TestCheckLevel(t *testing.T) {
+	for _, lvl := range []int{MinLevel - 1, -1, PerfectLevel + 1, 100} {
+		if err := CheckLevel(lvl); err == nil {
+			t.Errorf("level %d is accepted", lvl)
+		}
+
+		if _, err := LevelLimits(lvl); err == nil {
+			t.Errorf("level %d has limits", lvl)
+		}
+	}
+
+	for lvl := MinLevel; lvl <= PerfectLevel; lvl++ {
+		if err := CheckLevel(lvl); err != nil {
+			t.Errorf("level %d: %v", lvl, err)
+		}
+	}
+
+	perfect, _ := LevelLimits(PerfectLevel)
+	top, _ := LevelLimits(MaxLevel)
+
+	if perfect != top {
+		t.Errorf("perfect level limits are %+v, want the limits of the top level %+v", perfect, top)
+	}
+}
+
+func TestLevelsScale(t *testing.T) {
+	// A depth of zero does not limit the search.
+	depth := func(l level) int {
+		if l.depth == 0 {
+			return int(^uint(0) >> 1)
+		}
+
+		return l.depth
+	}
+
+	for lvl := MinLevel + 1; lvl <= MaxLevel; lvl++ {
+		prev, l := levels[lvl-1], levels[lvl]
+
+		if depth(l) < depth(prev) || l.time < prev.time {
+			t.Errorf("level %d searches less than level %d", lvl, lvl-1)
+		}
+
+		if l.lines > prev.lines || l.noise > prev.noise || l.mistake > prev.mistake {
+			t.Errorf("level %d plays more at random than level %d", lvl, lvl-1)
+		}
+	}
+
+	if l := levels[MaxLevel]; l.lines != 1 || l.noise != 0 || l.mistake != 0 {
+		t.Errorf("the top level plays at random")
+	}
+}
+
+func TestPlayTakesWin(t *testing.T) {
+	// The first player has an open four to complete.
+	g := testGame(t, [2]uint{7, 7}, [2]uint{0, 0}, [2]uint{8, 7}, [2]uint{0, 2}, [2]uint{9, 7}, [2]uint{0, 4}, [2]uint{10, 7}, [2]uint{0, 6})
+
+	for lvl := MinLevel; lvl <= PerfectLevel; lvl++ {
+		for seed := int64(0); seed < 5; seed++ {
+			e, _ := New(1 << 20)
+			e.Seed(seed)
+
+			m, err := e.Play(context.Background(), g, lvl, TimeLeft{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if m.Y != 7 || (m.X != 6 && m.X != 11) {
+				t.Errorf("level %d, seed %d: played %v instead of the winning move", lvl, seed, m)
+			}
+		}
+	}
+}
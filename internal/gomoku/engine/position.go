@@ -0,0 +1,208 @@
+package engine
+
+import (
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// Window weights by the number of stones missing to fill the window. A window
+// with stones of both players is dead and weighs nothing.
+var missingWeights = [...]int{0, 4096, 512, 64, 8, 1}
+
+// WindowWeights returns the weights of the live windows by the number of
+// stones missing to fill them. Windows missing more stones than there are
+// weights weigh as much as the last one.
+func WindowWeights() []int {
+	return append([]int(nil), missingWeights[:]...)
+}
+
+// position wraps a bitboard and keeps its static evaluation up to date as
+// moves are made and taken back.
+type position struct {
+	b *game.Bitboard
+
+	weights []int
+	// eval is the sum of the weights of the live windows of each player.
+	eval [2]int
+	// fours is the number of live windows each player is one stone short of
+	// filling.
+	fours [2]int
+
+	// near counts the stones close to each cell. Only empty cells near
+	// stones are worth searching.
+	near      []uint8
+	neighbors [][]uint
+}
+
+func newPosition(b *game.Bitboard) *position {
+	size := b.Size()
+	winCond := b.WinCond()
+
+	p := &position{
+		b:         b,
+		weights:   make([]int, winCond+1),
+		near:      make([]uint8, size*size),
+		neighbors: make([][]uint, size*size),
+	}
+
+	for k := uint(1); k <= winCond; k++ {
+		missing := winCond - k
+		if missing >= uint(len(missingWeights)) {
+			missing = uint(len(missingWeights)) - 1
+		}
+
+		p.weights[k] = missingWeights[missing]
+	}
+
+	// A filled window is a win, which the search scores by itself.
+	p.weights[winCond] = p.weights[winCond-1]
+
+	for x := 0; x < int(size); x++ {
+		for y := 0; y < int(size); y++ {
+			cell := b.Cell(uint(x), uint(y))
+
+			for dx := -2; dx <= 2; dx++ {
+				for dy := -2; dy <= 2; dy++ {
+					nx, ny := x+dx, y+dy
+
+					if (dx != 0 || dy != 0) && nx >= 0 && ny >= 0 && nx < int(size) && ny < int(size) {
+						p.neighbors[cell] = append(p.neighbors[cell], b.Cell(uint(nx), uint(ny)))
+					}
+				}
+			}
+		}
+	}
+
+	layout := b.Layout()
+
+	for w := range layout.Windows() {
+		for pt := game.FirstPlayer; pt <= game.SecondPlayer; pt++ {
+			k := b.Count(pt, w)
+
+			if k == 0 || b.Count((pt+1)%2, w) != 0 {
+				continue
+			}
+
+			p.eval[pt] += p.weights[k]
+
+			if k == winCond-1 {
+				p.fours[pt]++
+			}
+		}
+	}
+
+	for _, cell := range b.Moves() {
+		for _, n := range p.neighbors[cell] {
+			p.near[n]++
+		}
+	}
+
+	return p
+}
+
+func (p *position) make(cell uint) {
+	p.update(cell, p.b.CurrentPlayer(), 1)
+	p.b.Make(cell)
+}
+
+func (p *position) unmake() {
+	cell := p.b.Moves()[len(p.b.Moves())-1]
+
+	p.b.Unmake()
+	p.update(cell, p.b.CurrentPlayer(), -1)
+}
+
+// update adds (sign 1) or removes (sign -1) the effect of a stone of the
+// player on the cell. It must be called while the cell is empty.
+func (p *position) update(cell uint, pt game.PlayerType, sign int) {
+	b := p.b
+	o := (pt + 1) % 2
+	four := b.WinCond() - 1
+
+	for _, w := range b.Layout().CellWindows(cell) {
+		kp, ko := b.Count(pt, w), b.Count(o, w)
+
+		switch {
+		case ko == 0:
+			p.eval[pt] += sign * (p.weights[kp+1] - p.weights[kp])
+
+			if kp+1 == four {
+				p.fours[pt] += sign
+			} else if kp == four {
+				p.fours[pt] -= sign
+			}
+		case kp == 0:
+			p.eval[o] -= sign * p.weights[ko]
+
+			if ko == four {
+				p.fours[o] -= sign
+			}
+		}
+	}
+
+	for _, n := range p.neighbors[cell] {
+		p.near[n] = uint8(int(p.near[n]) + sign)
+	}
+}
+
+// evaluate scores the position for the player to move.
+func (p *position) evaluate() int {
+	pt := p.b.CurrentPlayer()
+	return p.eval[pt] - p.eval[(pt+1)%2]
+}
+
+// gain tells how much a stone on the empty cell is worth to the player to
+// move: what it adds to their windows and what it takes from the opponent's.
+func (p *position) gain(cell uint) int {
+	b := p.b
+	pt := b.CurrentPlayer()
+	o := (pt + 1) % 2
+
+	res := 0
+
+	for _, w := range b.Layout().CellWindows(cell) {
+		kp, ko := b.Count(pt, w), b.Count(o, w)
+
+		switch {
+		case ko == 0:
+			res += p.weights[kp+1] - p.weights[kp]
+		case kp == 0:
+			res += p.weights[ko+1] - p.weights[ko]
+		}
+	}
+
+	return res
+}
+
+// winCells returns the empty cells on which the player fills a window.
+func (p *position) winCells(pt game.PlayerType) []uint {
+	b := p.b
+	o := (pt + 1) % 2
+	four := b.WinCond() - 1
+	windows := b.Layout().Windows()
+
+	var res []uint
+
+	for w := range windows {
+		if b.Count(pt, w) != four || b.Count(o, w) != 0 {
+			continue
+		}
+
+		for _, c := range windows[w].Mask.Cells() {
+			if b.IsEmpty(c) && !containsUint(res, c) {
+				res = append(res, c)
+			}
+		}
+	}
+
+	return res
+}
+
+func containsUint(s []uint, v uint) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// BlunderLoss is how much worse a move has to leave the position for the
+// player who made it to be a blunder. It is about the worth of a four.
+const BlunderLoss = 4000
+
+type MoveKind uint
+
+const (
+	GoodMove MoveKind = iota
+	// Blunder is a move that throws away a large part of the advantage or
+	// walks into a forced loss.
+	Blunder
+	// MissedWin is a move that lets a forced win go.
+	MissedWin
+)
+
+var moveKindNames = [...]string{
+	GoodMove:  "good move",
+	Blunder:   "blunder",
+	MissedWin: "missed win",
+}
+
+func (k MoveKind) String() string {
+	if int(k) < len(moveKindNames) {
+		return moveKindNames[k]
+	}
+
+	return "unknown"
+}
+
+type MoveReview struct {
+	Move   Move
+	Player game.PlayerType
+	Kind   MoveKind
+
+	// Best is the move the engine prefers in the position the move was played
+	// in.
+	Best Move
+	// Loss is how much worse the move left the position for the player.
+	Loss int
+}
+
+type Review struct {
+	// Scores holds the score of the position after each number of moves, from
+	// none to all of them, for the first player.
+	Scores []int
+	Moves  []MoveReview
+}
+
+// Review evaluates every position of the game within the limits and judges
+// every move by how it changed the score for the player who made it.
+// progress, if not nil, is called after every evaluated position.
+func (e *Engine) Review(ctx context.Context, g *game.Game, limits Limits, progress func(done, total int)) (Review, error) {
+	moves := g.Moves()
+	total := len(moves) + 1
+
+	replay, err := game.NewGame(g.Player(game.FirstPlayer), g.Player(game.SecondPlayer), g.Size(), g.WinCond())
+	if err != nil {
+		return Review{}, err
+	}
+
+	limits.MultiPV = 1
+
+	res := Review{
+		Scores: make([]int, total),
+		Moves:  make([]MoveReview, len(moves)),
+	}
+
+	for i := 0; i < total; i++ {
+		if err := ctx.Err(); err != nil {
+			return Review{}, err
+		}
+
+		if replay.State() == game.NotFinished {
+			sr, err := e.Search(ctx, replay, limits, nil)
+			if err != nil {
+				return Review{}, err
+			}
+
+			best, _ := sr.Best()
+			res.Scores[i] = FirstPlayerScore(replay.CurrentPlayer(), best.Score)
+
+			if i < len(moves) {
+				res.Moves[i].Best = best.Move
+			}
+		} else {
+			res.Scores[i] = finalScore(replay.State())
+		}
+
+		if progress != nil {
+			progress(i+1, total)
+		}
+
+		if i < len(moves) {
+			m := moves[i]
+			res.Moves[i].Move = Move{X: m.X, Y: m.Y}
+			res.Moves[i].Player = replay.CurrentPlayer()
+
+			if err := replay.MakeMove(m.X, m.Y); err != nil {
+				return Review{}, err
+			}
+		}
+	}
+
+	for i := range res.Moves {
+		judge(&res.Moves[i], res.Scores[i], res.Scores[i+1])
+	}
+
+	return res, nil
+}
+
+// judge sets the kind of the move from the scores for the first player
+// before and after it.
+func judge(m *MoveReview, before, after int) {
+	before = FirstPlayerScore(m.Player, before)
+	after = FirstPlayerScore(m.Player, after)
+
+	m.Loss = clampScore(before) - clampScore(after)
+
+	switch {
+	case IsWin(before) && !IsWin(after):
+		m.Kind = MissedWin
+	case m.Loss >= BlunderLoss, !IsLoss(before) && IsLoss(after):
+		m.Kind = Blunder
+	}
+}
+
+func finalScore(state game.GameState) int {
+	switch state {
+	case game.FirstPlayerWin:
+		return WinScore
+	case game.SecondPlayerWin:
+		return -WinScore
+	}
+
+	return 0
+}
+
+// clampScore keeps win and loss scores from dwarfing the others when scores
+// are subtracted.
+func clampScore(score int) int {
+	const limit = 4 * BlunderLoss
+
+	switch {
+	case score > limit:
+		return limit
+	case score < -limit:
+		return -limit
+	}
+
+	return score
+}
@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+func TestReview(t *testing.T) {
+	// The second player lets the first one make a four and does not block
+	// it, the first player then does not complete it.
+	g := testGame(t, [2]uint{7, 7}, [2]uint{6, 7}, [2]uint{8, 7}, [2]uint{0, 0}, [2]uint{9, 7}, [2]uint{0, 2},
+		[2]uint{10, 7}, [2]uint{0, 4}, [2]uint{14, 14}, [2]uint{11, 7})
+
+	e, _ := New(1 << 20)
+
+	var calls int
+
+	r, err := e.Review(context.Background(), g, Limits{Depth: 4}, func(done, total int) {
+		calls++
+
+		if done != calls || total != 11 {
+			t.Errorf("progress is %d of %d after %d evaluated positions", done, total, calls)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Scores) != 11 || len(r.Moves) != 10 || calls != 11 {
+		t.Fatalf("%d scores and %d moves reviewed with %d progress calls", len(r.Scores), len(r.Moves), calls)
+	}
+
+	for i, m := range r.Moves {
+		want := GoodMove
+
+		switch i {
+		case 7:
+			want = Blunder
+		case 8:
+			want = MissedWin
+		}
+
+		if m.Kind != want {
+			t.Errorf("move %d %v is a %v, want a %v", i+1, m.Move, m.Kind, want)
+		}
+
+		if m.Player != game.PlayerType(i%2) {
+			t.Errorf("move %d is made by player %d", i+1, m.Player)
+		}
+	}
+
+	if best := r.Moves[8].Best; best != (Move{X: 11, Y: 7}) {
+		t.Errorf("the best move instead of the missed win is %v", best)
+	}
+
+	if !IsWin(r.Scores[8]) {
+		t.Errorf("the score with a four to complete is %d", r.Scores[8])
+	}
+}
+
+func TestReviewEndedGame(t *testing.T) {
+	tests := []struct {
+		name string
+		end  func(g *game.Game)
+	}{
+		{"resign", func(g *game.Game) { g.Resign(game.SecondPlayer) }},
+		{"timeout", func(g *game.Game) { g.LoseOnTime(game.SecondPlayer) }},
+	}
+
+	e, _ := New(1 << 20)
+
+	want, err := e.Review(context.Background(), testGame(t, middlegame...), Limits{Depth: 3}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		g := testGame(t, middlegame...)
+		tt.end(g)
+
+		e.Clear()
+
+		r, err := e.Review(context.Background(), g, Limits{Depth: 3}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The player who made the last move lost the game without it being
+		// a mistake.
+		last := len(r.Scores) - 1
+
+		if r.Scores[last] != want.Scores[last] {
+			t.Errorf("%s: the last score is %d, want %d as if the game went on", tt.name, r.Scores[last], want.Scores[last])
+		}
+
+		if m := r.Moves[last-1]; m.Kind != want.Moves[last-1].Kind {
+			t.Errorf("%s: the last move is a %v, want a %v", tt.name, m.Kind, want.Moves[last-1].Kind)
+		}
+	}
+}
+
+func TestJudge(t *testing.T) {
+	tests := []struct {
+		name          string
+		player        game.PlayerType
+		before, after int
+		kind          MoveKind
+	}{
+		{"even", game.FirstPlayer, 100, 50, GoodMove},
+		{"small loss", game.FirstPlayer, 2000, 0, GoodMove},
+		{"large loss", game.FirstPlayer, 3000, -1000, Blunder},
+		{"into a loss", game.FirstPlayer, 0, -WinScore + 5, Blunder},
+		{"lost anyway", game.FirstPlayer, -WinScore + 7, -WinScore + 5, GoodMove},
+		{"missed win", game.FirstPlayer, WinScore - 1, 500, MissedWin},
+		{"kept win", game.FirstPlayer, WinScore - 3, WinScore - 5, GoodMove},
+		{"second player blunder", game.SecondPlayer, 0, 5000, Blunder},
+		{"second player missed win", game.SecondPlayer, -WinScore + 1, 0, MissedWin},
+		{"second player gain", game.SecondPlayer, 0, -5000, GoodMove},
+	}
+
+	for _, tt := range tests {
+		m := MoveReview{Player: tt.player}
+		judge(&m, tt.before, tt.after)
+
+		if m.Kind != tt.kind {
+			t.Errorf("%s: judged a %v with a loss of %d, want a %v", tt.name, m.Kind, m.Loss, tt.kind)
+		}
+	}
+}
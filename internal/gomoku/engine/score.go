@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+const (
+	infinity = 1 << 30
+
+	// WinScore is the score of a won position. A win n plies away scores
+	// WinScore - n, so that quicker wins are preferred.
+	WinScore = 1 << 28
+
+	winBound = WinScore - 1000
+)
+
+// IsWin tells whether the score is a forced win.
+func IsWin(score int) bool {
+	return score >= winBound
+}
+
+// IsLoss tells whether the score is a forced loss.
+func IsLoss(score int) bool {
+	return score <= -winBound
+}
+
+// MovesToEnd returns the number of moves of the winning player left until
+// the end of the game for a win or a loss score.
+func MovesToEnd(score int) int {
+	if score < 0 {
+		score = -score
+	}
+
+	return (WinScore - score + 1) / 2
+}
+
+// FormatScore formats a score in hundredths of a point, or as a win or a loss
+// in a number of moves.
+func FormatScore(score int) string {
+	switch {
+	case IsWin(score):
+		return fmt.Sprintf("W%d", MovesToEnd(score))
+	case IsLoss(score):
+		return fmt.Sprintf("L%d", MovesToEnd(score))
+	}
+
+	return fmt.Sprintf("%+.2f", float64(score)/100)
+}
+
+// FirstPlayerScore turns a score for the player to move into a score for the
+// first player, and back.
+func FirstPlayerScore(toMove game.PlayerType, score int) int {
+	if toMove == game.SecondPlayer {
+		return -score
+	}
+
+	return score
+}
+
+// The table keeps win scores relative to the position they are stored for,
+// not to the root.
+func toTable(score, ply int) int32 {
+	switch {
+	case IsWin(score):
+		score += ply
+	case IsLoss(score):
+		score -= ply
+	}
+
+	return int32(score)
+}
+
+func fromTable(score int32, ply int) int {
+	s := int(score)
+
+	switch {
+	case IsWin(s):
+		s -= ply
+	case IsLoss(s):
+		s += ply
+	}
+
+	return s
+}
@@ -0,0 +1,467 @@
+package engine
+
+import (
+	"context"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/tt"
+)
+
+const (
+	maxPly = 64
+
+	// Inner nodes only search the most promising moves.
+	maxBranch = 12
+
+	// checkEvery is how many nodes are searched between looking at the clock
+	// and the context.
+	checkEvery = 1024
+
+	// A best score falling by more than scoreDrop from one iteration to the
+	// next makes the search go on past its soft time.
+	scoreDrop = 256
+)
+
+type searcher struct {
+	ctx    context.Context
+	table  *tt.Table
+	pos    *position
+	limits Limits
+
+	// helper is the number of the helper thread, 0 for the main one. Helpers
+	// search the same position only to fill the shared table.
+	helper int
+	// total counts the nodes of all the threads of the search, updated every
+	// checkEvery nodes.
+	total *int64
+
+	start    time.Time
+	deadline time.Time
+	nodes    int
+	stopped  bool
+	// mustFinish keeps the first iteration of the main thread from being
+	// stopped, if finishFirst is set.
+	mustFinish  bool
+	finishFirst bool
+
+	pv    [maxPly + 1][maxPly + 1]uint
+	pvLen [maxPly + 1]int
+}
+
+type scoredCell struct {
+	cell  uint
+	score int
+}
+
+func newSearcher(ctx context.Context, table *tt.Table, b *game.Bitboard, limits Limits, helper int, total *int64) *searcher {
+	if limits.Depth <= 0 || limits.Depth > MaxDepth {
+		limits.Depth = MaxDepth
+	}
+
+	if limits.MultiPV <= 0 {
+		limits.MultiPV = 1
+	}
+
+	s := &searcher{
+		ctx:    ctx,
+		table:  table,
+		pos:    newPosition(b),
+		limits: limits,
+		helper: helper,
+		total:  total,
+		start:  time.Now(),
+	}
+
+	if limits.Time > 0 {
+		s.deadline = s.start.Add(limits.Time)
+	}
+
+	return s
+}
+
+func (s *searcher) run(info func(Result)) Result {
+	var res Result
+
+	rootMoves := s.rootMoves()
+
+	// Helpers start deeper and with the root moves in another order, so that
+	// the threads do not all search the same nodes at the same time.
+	first := 1
+
+	if s.helper > 0 {
+		first += s.helper % 2
+
+		if n := len(rootMoves); n > 1 {
+			k := s.helper % n
+			rootMoves = append(rootMoves[k:len(rootMoves):len(rootMoves)], rootMoves[:k]...)
+		}
+	}
+
+	var prev Line
+
+	for depth := first; depth <= s.limits.Depth; depth++ {
+		s.mustFinish = depth == 1 && s.helper == 0 && s.finishFirst
+
+		lines, ok := s.searchRoot(depth, rootMoves)
+		if !ok {
+			break
+		}
+
+		unstable := depth > first && (lines[0].Move != prev.Move || lines[0].Score < prev.Score-scoreDrop)
+		prev = lines[0]
+
+		res = Result{
+			Lines:   lines,
+			Depth:   depth,
+			Nodes:   s.totalNodes(),
+			Elapsed: time.Since(s.start),
+		}
+
+		if info != nil {
+			info(res)
+		}
+
+		if best := lines[0]; IsWin(best.Score) || IsLoss(best.Score) {
+			break
+		}
+
+		// The next iteration takes a few times longer than this one, do not
+		// start it if it would end long after the soft time.
+		if soft := s.limits.SoftTime; soft > 0 {
+			if unstable {
+				soft *= 2
+			}
+
+			if time.Since(s.start) > soft/2 {
+				break
+			}
+		}
+
+		// The next iteration starts with the best moves of this one.
+		order := make([]uint, 0, len(rootMoves))
+		for _, l := range lines {
+			order = append(order, s.pos.b.Cell(l.Move.X, l.Move.Y))
+		}
+
+		for _, cell := range rootMoves {
+			if !containsUint(order, cell) {
+				order = append(order, cell)
+			}
+		}
+
+		rootMoves = order
+
+		if len(rootMoves) == 1 {
+			break
+		}
+	}
+
+	res.Nodes = s.nodes
+	res.Elapsed = time.Since(s.start)
+
+	return res
+}
+
+// searchRoot scores the root moves, keeping the best MultiPV of them. Every
+// kept score is exact: a move only has to beat the worst kept score to be
+// searched with a full window.
+func (s *searcher) searchRoot(depth int, moves []uint) ([]Line, bool) {
+	var lines []Line
+
+	for _, cell := range moves {
+		s.pos.make(cell)
+
+		var score int
+
+		if len(lines) < s.limits.MultiPV {
+			score = -s.negamax(depth-1, 1, -infinity, infinity)
+		} else {
+			worst := lines[len(lines)-1].Score
+			score = -s.negamax(depth-1, 1, -infinity, -worst)
+
+			if score <= worst {
+				s.pos.unmake()
+
+				if s.stopped {
+					return nil, false
+				}
+
+				continue
+			}
+		}
+
+		s.pos.unmake()
+
+		if s.stopped {
+			return nil, false
+		}
+
+		line := Line{
+			Move:  s.move(cell),
+			Score: score,
+			PV:    []Move{s.move(cell)},
+		}
+
+		for _, c := range s.pv[1][:s.pvLen[1]] {
+			line.PV = append(line.PV, s.move(c))
+		}
+
+		i := sort.Search(len(lines), func(i int) bool {
+			return lines[i].Score < score
+		})
+
+		lines = append(lines, Line{})
+		copy(lines[i+1:], lines[i:])
+		lines[i] = line
+
+		if len(lines) > s.limits.MultiPV {
+			lines = lines[:s.limits.MultiPV]
+		}
+	}
+
+	return lines, true
+}
+
+func (s *searcher) negamax(depth, ply int, alpha, beta int) int {
+	s.pvLen[ply] = 0
+
+	if s.stop() {
+		return 0
+	}
+
+	s.nodes++
+
+	p := s.pos
+	b := p.b
+	pt := b.CurrentPlayer()
+	o := (pt + 1) % 2
+
+	switch b.State() {
+	case game.NotFinished:
+	case game.NobodyWins:
+		return 0
+	default:
+		// The previous move has won.
+		return -(WinScore - ply)
+	}
+
+	if p.fours[pt] > 0 {
+		return WinScore - ply - 1
+	}
+
+	var forced []uint
+
+	if p.fours[o] > 0 {
+		forced = p.winCells(o)
+
+		// Both cannot be blocked.
+		if len(forced) > 1 {
+			return -(WinScore - ply - 2)
+		}
+
+		// A forced block does not use up depth.
+		depth++
+	}
+
+	if depth <= 0 || ply >= maxPly {
+		return p.evaluate()
+	}
+
+	// Positions equivalent under a symmetry of the board share their entry,
+	// the move of the entry is kept turned into the canonical position.
+	key, sym := b.Canonical()
+	ttMove := tt.NoMove
+
+	if e, ok := s.table.Probe(key); ok {
+		if e.Move != tt.NoMove {
+			ttMove = uint16(b.TransformCell(sym.Inverse(), uint(e.Move)))
+		}
+
+		if int(e.Depth) >= depth {
+			score := fromTable(e.Score, ply)
+
+			switch {
+			case e.Bound == tt.Exact,
+				e.Bound == tt.Lower && score >= beta,
+				e.Bound == tt.Upper && score <= alpha:
+				return score
+			}
+		}
+	}
+
+	moves := forced
+	if moves == nil {
+		moves = s.orderedMoves(ttMove, maxBranch)
+	}
+
+	if len(moves) == 0 {
+		return 0
+	}
+
+	origAlpha := alpha
+	best := -infinity
+	bestMove := tt.NoMove
+
+	for _, cell := range moves {
+		p.make(cell)
+		score := -s.negamax(depth-1, ply+1, -beta, -alpha)
+		p.unmake()
+
+		if s.stopped {
+			return 0
+		}
+
+		if score > best {
+			best = score
+			bestMove = uint16(cell)
+		}
+
+		if score > alpha {
+			alpha = score
+
+			s.pv[ply][0] = cell
+			copy(s.pv[ply][1:], s.pv[ply+1][:s.pvLen[ply+1]])
+			s.pvLen[ply] = s.pvLen[ply+1] + 1
+		}
+
+		if alpha >= beta {
+			break
+		}
+	}
+
+	bound := tt.Exact
+
+	switch {
+	case best <= origAlpha:
+		bound = tt.Upper
+	case best >= beta:
+		bound = tt.Lower
+	}
+
+	if bestMove != tt.NoMove {
+		bestMove = uint16(b.TransformCell(sym, uint(bestMove)))
+	}
+
+	s.table.Store(tt.Entry{
+		Key:   key,
+		Score: toTable(best, ply),
+		Move:  bestMove,
+		Depth: int8(depth),
+		Bound: bound,
+	})
+
+	return best
+}
+
+// rootMoves returns every move worth searching at the root, or the block if
+// the opponent threatens to win.
+func (s *searcher) rootMoves() []uint {
+	p := s.pos
+	b := p.b
+	pt := b.CurrentPlayer()
+
+	if p.fours[pt] > 0 {
+		return p.winCells(pt)[:1]
+	}
+
+	if blocks := p.winCells((pt + 1) % 2); len(blocks) == 1 {
+		return blocks
+	}
+
+	if len(b.Moves()) == 0 {
+		center := b.Size() / 2
+		return []uint{b.Cell(center, center)}
+	}
+
+	moves := s.orderedMoves(tt.NoMove, 0)
+	if len(moves) == 0 {
+		// Nothing is near the stones, which only happens on tiny boards.
+		for cell := uint(0); cell < b.Size()*b.Size(); cell++ {
+			if b.IsEmpty(cell) {
+				moves = append(moves, cell)
+			}
+		}
+	}
+
+	return moves
+}
+
+// orderedMoves returns the empty cells near stones, the table move first and
+// then the most valuable ones. At most limit moves are returned, unless limit
+// is zero.
+func (s *searcher) orderedMoves(ttMove uint16, limit int) []uint {
+	p := s.pos
+	b := p.b
+
+	var cells []scoredCell
+
+	for cell := uint(0); cell < b.Size()*b.Size(); cell++ {
+		if p.near[cell] == 0 || !b.IsEmpty(cell) {
+			continue
+		}
+
+		score := p.gain(cell)
+		if uint16(cell) == ttMove {
+			score = infinity
+		}
+
+		cells = append(cells, scoredCell{cell: cell, score: score})
+	}
+
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].score != cells[j].score {
+			return cells[i].score > cells[j].score
+		}
+
+		return cells[i].cell < cells[j].cell
+	})
+
+	if limit > 0 && len(cells) > limit {
+		cells = cells[:limit]
+	}
+
+	res := make([]uint, len(cells))
+	for i, c := range cells {
+		res[i] = c.cell
+	}
+
+	return res
+}
+
+func (s *searcher) stop() bool {
+	if s.stopped {
+		return true
+	}
+
+	if s.nodes == 0 || s.nodes%checkEvery != 0 {
+		return false
+	}
+
+	total := atomic.AddInt64(s.total, checkEvery)
+
+	if s.mustFinish {
+		return false
+	}
+
+	switch {
+	case s.limits.Nodes > 0 && total >= int64(s.limits.Nodes),
+		!s.deadline.IsZero() && time.Now().After(s.deadline),
+		s.ctx.Err() != nil:
+		s.stopped = true
+	}
+
+	return s.stopped
+}
+
+// totalNodes returns the number of nodes searched by all the threads so far.
+func (s *searcher) totalNodes() int {
+	return int(atomic.LoadInt64(s.total)) + s.nodes%checkEvery
+}
+
+func (s *searcher) move(cell uint) Move {
+	x, y := s.pos.b.Coords(cell)
+	return Move{X: x, Y: y}
+}
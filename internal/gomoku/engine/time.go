@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+const (
+	// The time kept in reserve for the engine and the interface to make the
+	// move once the search stops.
+	moveOverhead = 50 * time.Millisecond
+
+	// Games are assumed to last at least that many more moves of the player
+	// to move.
+	minMovesToGo = 10
+	maxMovesToGo = 30
+
+	// A search is stopped at the latest after hardTimeFactor times the time
+	// allocated to the move.
+	hardTimeFactor = 4
+)
+
+// TimeLeft is the state of the clock of the player to move. A zero TimeLeft
+// means the game is not timed.
+type TimeLeft struct {
+	Remaining time.Duration
+	Increment time.Duration
+}
+
+func (tl TimeLeft) timed() bool {
+	return tl.Remaining > 0 || tl.Increment > 0
+}
+
+// Allocate returns how long to think about the next move of the player with
+// the time left: the soft time after which no new iteration is started, and
+// the hard time the search never goes beyond. Critical positions get more
+// time.
+func Allocate(tl TimeLeft, g *game.Game, critical bool) (soft, hard time.Duration) {
+	left := tl.Remaining - moveOverhead
+	if left <= 0 {
+		return time.Millisecond, time.Millisecond
+	}
+
+	// Half the board is rarely filled, the longer the game the fewer moves
+	// are left.
+	cells := int(g.Size() * g.Size())
+	movesToGo := (cells/2 - len(g.Moves())) / 2
+
+	switch {
+	case movesToGo < minMovesToGo:
+		movesToGo = minMovesToGo
+	case movesToGo > maxMovesToGo:
+		movesToGo = maxMovesToGo
+	}
+
+	soft = left/time.Duration(movesToGo) + tl.Increment*3/4
+
+	if critical {
+		soft *= 2
+	}
+
+	hard = soft * hardTimeFactor
+
+	// Never risk more than a third of the time left on one move.
+	if max := left / 3; hard > max {
+		hard = max
+	}
+
+	if soft > hard {
+		soft = hard
+	}
+
+	return soft, hard
+}
+
+// Critical tells whether the player to move is under threat or has a threat
+// of their own, in which case a move deserves more thought.
+func Critical(g *game.Game) bool {
+	if g.State() != game.NotFinished {
+		return false
+	}
+
+	b := g.Bitboard()
+
+	// A window two stones short of being filled, like three stones out of
+	// five, threatens to become a four.
+	threat := b.WinCond() - 2
+
+	for w := range b.Layout().Windows() {
+		for pt := game.FirstPlayer; pt <= game.SecondPlayer; pt++ {
+			if b.Count(pt, w) >= threat && b.Count((pt+1)%2, w) == 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Ponder searches the position on the time of the opponent, who is to move,
+// until the context is done. Nothing is returned, the search only fills the
+// transposition table for the next search. Unlike Search, Ponder stops as
+// soon as the context is done.
+func (e *Engine) Ponder(ctx context.Context, g *game.Game) {
+	if g.State() != game.NotFinished {
+		return
+	}
+
+	e.search(ctx, g, Limits{}, nil, false)
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllocate(t *testing.T) {
+	g := testGame(t)
+
+	tests := []struct {
+		name       string
+		tl         TimeLeft
+		critical   bool
+		soft, hard time.Duration
+	}{
+		// A minute on an empty board is spread over the longest game.
+		{"minute", TimeLeft{Remaining: time.Minute + moveOverhead}, false, 2 * time.Second, 8 * time.Second},
+		{"critical", TimeLeft{Remaining: time.Minute + moveOverhead}, true, 4 * time.Second, 16 * time.Second},
+		{"increment", TimeLeft{Remaining: time.Minute + moveOverhead, Increment: time.Second}, false, 2750 * time.Millisecond, 11 * time.Second},
+		// The hard time never goes over a third of the time left.
+		{"capped", TimeLeft{Remaining: 3*time.Second + moveOverhead, Increment: 10 * time.Second}, false, time.Second, time.Second},
+		{"out of time", TimeLeft{Remaining: moveOverhead}, false, time.Millisecond, time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		soft, hard := Allocate(tt.tl, g, tt.critical)
+
+		if soft != tt.soft || hard != tt.hard {
+			t.Errorf("%s: allocated %v and %v, want %v and %v", tt.name, soft, hard, tt.soft, tt.hard)
+		}
+	}
+}
+
+func TestAllocateLaterInTheGame(t *testing.T) {
+	tl := TimeLeft{Remaining: 10 * time.Minute}
+	g := testGame(t)
+
+	prev, _ := Allocate(tl, g, false)
+
+	// Fewer moves are left as the board fills, down to minMovesToGo.
+	for _, m := range [][2]uint{{0, 0}, {0, 2}, {0, 4}, {0, 6}, {0, 8}, {0, 10}, {0, 12}, {0, 14}} {
+		for x := uint(1); x < 15; x += 2 {
+			g.MakeMove(x, m[1])
+			g.MakeMove(x+1, m[1])
+		}
+
+		soft, hard := Allocate(tl, g, false)
+
+		if soft < prev {
+			t.Fatalf("%d moves: %v allocated, less than %v before", len(g.Moves()), soft, prev)
+		}
+
+		if hard > (tl.Remaining-moveOverhead)/3 || soft > hard {
+			t.Fatalf("%d moves: allocated %v and %v", len(g.Moves()), soft, hard)
+		}
+
+		prev = soft
+	}
+
+	if want := (tl.Remaining - moveOverhead) / minMovesToGo; prev != want {
+		t.Errorf("late in the game %v is allocated, want %v", prev, want)
+	}
+}
+
+func TestCritical(t *testing.T) {
+	tests := []struct {
+		name  string
+		moves [][2]uint
+		want  bool
+	}{
+		{"empty", nil, false},
+		{"two", [][2]uint{{7, 7}, {0, 0}, {8, 7}, {0, 14}}, false},
+		{"three", [][2]uint{{7, 7}, {0, 0}, {8, 7}, {0, 14}, {9, 7}}, true},
+		{"split three", [][2]uint{{7, 7}, {0, 0}, {8, 7}, {0, 14}, {10, 7}}, true},
+		{"three of the opponent", [][2]uint{{0, 0}, {7, 7}, {0, 14}, {8, 8}, {14, 0}, {9, 9}}, true},
+		{"blocked three", [][2]uint{{7, 7}, {5, 7}, {8, 7}, {6, 7}, {9, 7}, {10, 7}, {7, 0}, {11, 7}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := Critical(testGame(t, tt.moves...)); got != tt.want {
+			t.Errorf("%s: Critical is %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// A finished game has nothing left to think about.
+	g := testGame(t, [2]uint{7, 7}, [2]uint{0, 0}, [2]uint{8, 7}, [2]uint{0, 2}, [2]uint{9, 7}, [2]uint{0, 4}, [2]uint{10, 7}, [2]uint{0, 6}, [2]uint{11, 7})
+
+	if Critical(g) {
+		t.Error("a finished game is critical")
+	}
+}
@@ -0,0 +1,359 @@
+package game
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Window is a line of winCond consecutive cells, any of which a player has to
+// fill completely to win.
+type Window struct {
+	Mask       Bitset
+	Start, End uint
+
+	lo, hi uint8
+}
+
+func (w *Window) filledBy(b *Bitset) bool {
+	for i := w.lo; i <= w.hi; i++ {
+		if b[i]&w.Mask[i] != w.Mask[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Layout holds the windows of a board of a given size and win condition.
+// Layouts are immutable and shared between boards.
+type Layout struct {
+	size    uint
+	winCond uint
+
+	windows     []Window
+	cellWindows [][]int
+	keys        [][2]uint64
+
+	// symmetry maps every cell to the cell each transform moves it to.
+	symmetry [TransformCount][]uint16
+}
+
+var (
+	layoutsMu sync.Mutex
+	layouts   = make(map[[2]uint]*Layout)
+)
+
+func layoutFor(size, winCond uint) *Layout {
+	layoutsMu.Lock()
+	defer layoutsMu.Unlock()
+
+	key := [2]uint{size, winCond}
+
+	if l, ok := layouts[key]; ok {
+		return l
+	}
+
+	l := newLayout(size, winCond)
+	layouts[key] = l
+
+	return l
+}
+
+func newLayout(size, winCond uint) *Layout {
+	l := &Layout{
+		size:        size,
+		winCond:     winCond,
+		cellWindows: make([][]int, size*size),
+		keys:        make([][2]uint64, size*size),
+	}
+
+	for x := uint(0); x < size; x++ {
+		for y := uint(0); y < size; y++ {
+			l.keys[x*size+y] = zobristKeys[x*MaxSize+y]
+		}
+	}
+
+	for t := Identity; t < TransformCount; t++ {
+		l.symmetry[t] = make([]uint16, size*size)
+
+		for x := uint(0); x < size; x++ {
+			for y := uint(0); y < size; y++ {
+				tx, ty := t.Apply(x, y, size)
+				l.symmetry[t][x*size+y] = uint16(tx*size + ty)
+			}
+		}
+	}
+
+	dirs := [4][2]int{{1, 0}, {0, 1}, {1, 1}, {1, -1}}
+
+	for _, d := range dirs {
+		for x := 0; x < int(size); x++ {
+			for y := 0; y < int(size); y++ {
+				x1 := x + d[0]*(int(winCond)-1)
+				y1 := y + d[1]*(int(winCond)-1)
+
+				if x1 < 0 || y1 < 0 || x1 >= int(size) || y1 >= int(size) {
+					continue
+				}
+
+				w := Window{
+					Start: uint(x)*size + uint(y),
+					End:   uint(x1)*size + uint(y1),
+					lo:    bitsetWords,
+				}
+
+				idx := len(l.windows)
+
+				for n := 0; n < int(winCond); n++ {
+					cell := uint(x+d[0]*n)*size + uint(y+d[1]*n)
+					w.Mask.Set(cell)
+
+					if word := uint8(cell / 64); word < w.lo {
+						w.lo = word
+					}
+
+					if word := uint8(cell / 64); word > w.hi {
+						w.hi = word
+					}
+
+					l.cellWindows[cell] = append(l.cellWindows[cell], idx)
+				}
+
+				l.windows = append(l.windows, w)
+			}
+		}
+	}
+
+	return l
+}
+
+func (l *Layout) Size() uint {
+	return l.size
+}
+
+func (l *Layout) WinCond() uint {
+	return l.winCond
+}
+
+func (l *Layout) Windows() []Window {
+	return l.windows
+}
+
+// CellWindows returns the indices of the windows going through the cell.
+func (l *Layout) CellWindows(cell uint) []int {
+	return l.cellWindows[cell]
+}
+
+// Bitboard is a board representation meant for search. Stones of each player
+// are kept in a bitset and every window counts the stones each player has in
+// it, so making a move and checking whether it wins only touches the windows
+// going through the cell.
+type Bitboard struct {
+	layout *Layout
+
+	stones    [2]Bitset
+	counts    [2][]uint8
+	moves     []uint
+	curplayer PlayerType
+	state     GameState
+	winWindow int
+
+	// hashes are the hashes of the stones turned by every transform, the
+	// first one is the hash of the position as it is.
+	hashes [TransformCount]uint64
+}
+
+func NewBitboard(size, winCond uint) (*Bitboard, error) {
+	if err := CheckSettings(nil, nil, size, winCond); err != nil {
+		return nil, err
+	}
+
+	l := layoutFor(size, winCond)
+
+	return &Bitboard{
+		layout:    l,
+		counts:    [2][]uint8{make([]uint8, len(l.windows)), make([]uint8, len(l.windows))},
+		moves:     make([]uint, 0, size*size),
+		state:     NotFinished,
+		winWindow: -1,
+	}, nil
+}
+
+// Bitboard returns a bitboard holding the position of the game.
+func (g *Game) Bitboard() *Bitboard {
+	b, _ := NewBitboard(g.size, g.winCond)
+
+	for _, f := range g.moves {
+		b.curplayer = PlayerType(f.Ft - 1)
+		b.Make(b.Cell(f.X, f.Y))
+	}
+
+	b.curplayer = g.curplayer
+
+	return b
+}
+
+func (b *Bitboard) Clone() *Bitboard {
+	c := *b
+	c.counts = [2][]uint8{
+		append([]uint8(nil), b.counts[0]...),
+		append([]uint8(nil), b.counts[1]...),
+	}
+	c.moves = append(make([]uint, 0, cap(b.moves)), b.moves...)
+
+	return &c
+}
+
+func (b *Bitboard) Layout() *Layout {
+	return b.layout
+}
+
+func (b *Bitboard) Size() uint {
+	return b.layout.size
+}
+
+func (b *Bitboard) WinCond() uint {
+	return b.layout.winCond
+}
+
+func (b *Bitboard) Cell(x, y uint) uint {
+	return x*b.layout.size + y
+}
+
+func (b *Bitboard) Coords(cell uint) (x, y uint) {
+	return cell / b.layout.size, cell % b.layout.size
+}
+
+func (b *Bitboard) Field(cell uint) FieldType {
+	switch {
+	case b.stones[FirstPlayer].Has(cell):
+		return FirstPlayerField
+	case b.stones[SecondPlayer].Has(cell):
+		return SecondPlayerField
+	}
+
+	return EmptyField
+}
+
+func (b *Bitboard) IsEmpty(cell uint) bool {
+	return !b.stones[FirstPlayer].Has(cell) && !b.stones[SecondPlayer].Has(cell)
+}
+
+func (b *Bitboard) Stones(pt PlayerType) Bitset {
+	return b.stones[pt]
+}
+
+func (b *Bitboard) Occupied() Bitset {
+	return b.stones[FirstPlayer].Union(b.stones[SecondPlayer])
+}
+
+// Count returns the number of stones the player has in the window.
+func (b *Bitboard) Count(pt PlayerType, window int) uint {
+	return uint(b.counts[pt][window])
+}
+
+func (b *Bitboard) CurrentPlayer() PlayerType {
+	return b.curplayer
+}
+
+func (b *Bitboard) State() GameState {
+	return b.state
+}
+
+func (b *Bitboard) Moves() []uint {
+	return b.moves
+}
+
+func (b *Bitboard) Strike() (Strike, error) {
+	if b.winWindow < 0 {
+		return Strike{}, fmt.Errorf("nobody has won")
+	}
+
+	w := &b.layout.windows[b.winWindow]
+	x0, y0 := b.Coords(w.Start)
+	x1, y1 := b.Coords(w.End)
+
+	return Strike{X0: x0, Y0: y0, X1: x1, Y1: y1}, nil
+}
+
+// Make puts a stone of the current player on the empty cell and passes the
+// turn. It does not check whether the cell is empty or the game is over.
+func (b *Bitboard) Make(cell uint) {
+	pt := b.curplayer
+	counts := b.counts[pt]
+	winCond := uint8(b.layout.winCond)
+
+	b.stones[pt].Set(cell)
+	b.moves = append(b.moves, cell)
+	b.updateHashes(cell, pt)
+
+	for _, w := range b.layout.cellWindows[cell] {
+		counts[w]++
+
+		if counts[w] == winCond && b.winWindow < 0 {
+			b.winWindow = w
+			b.state = GameState(pt) + 1
+		}
+	}
+
+	if b.state == NotFinished && uint(len(b.moves)) == b.layout.size*b.layout.size {
+		b.state = NobodyWins
+	}
+
+	b.curplayer = (pt + 1) % 2
+}
+
+func (b *Bitboard) updateHashes(cell uint, pt PlayerType) {
+	for t := range b.hashes {
+		b.hashes[t] ^= b.layout.keys[b.layout.symmetry[t][cell]][pt]
+	}
+}
+
+// Unmake takes back the last move.
+func (b *Bitboard) Unmake() {
+	cell := b.moves[len(b.moves)-1]
+	b.moves = b.moves[:len(b.moves)-1]
+
+	pt := (b.curplayer + 1) % 2
+	counts := b.counts[pt]
+
+	b.stones[pt].Clear(cell)
+	b.updateHashes(cell, pt)
+
+	for _, w := range b.layout.cellWindows[cell] {
+		counts[w]--
+	}
+
+	b.curplayer = pt
+	b.state = NotFinished
+	b.winWindow = -1
+}
+
+// Wins tells whether a stone of the player on the empty cell would win.
+func (b *Bitboard) Wins(pt PlayerType, cell uint) bool {
+	winCond := uint8(b.layout.winCond) - 1
+
+	for _, w := range b.layout.cellWindows[cell] {
+		if b.counts[pt][w] == winCond {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Winner checks the whole board with the window masks. It is much slower than
+// State and is meant for positions that were not built with Make.
+func (b *Bitboard) Winner() (PlayerType, bool) {
+	for i := range b.layout.windows {
+		w := &b.layout.windows[i]
+
+		for pt := FirstPlayer; pt <= SecondPlayer; pt++ {
+			if w.filledBy(&b.stones[pt]) {
+				return pt, true
+			}
+		}
+	}
+
+	return FirstPlayer, false
+}
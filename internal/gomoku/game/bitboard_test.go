@@ -0,0 +1,144 @@
+package game
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// randomMoves returns the moves of a game played at random until its end.
+func randomMoves(rng *rand.Rand, size, winCond uint) [][2]uint {
+	b, _ := NewBitboard(size, winCond)
+
+	var moves [][2]uint
+
+	for _, cell := range rng.Perm(int(size * size)) {
+		if b.State() != NotFinished {
+			break
+		}
+
+		x, y := b.Coords(uint(cell))
+		moves = append(moves, [2]uint{x, y})
+		b.Make(uint(cell))
+	}
+
+	return moves
+}
+
+func TestBitboardAgreesWithGame(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 500; i++ {
+		size := MinSize + uint(rng.Intn(MaxSize-MinSize+1))
+		winCond := MinSize + uint(rng.Intn(int(size-MinSize+1)))
+
+		g, _ := NewGame(NewPlayer("o"), NewPlayer("x"), size, winCond)
+		b, err := NewBitboard(size, winCond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		moves := randomMoves(rng, size, winCond)
+
+		for n, m := range moves {
+			if err := g.MakeMove(m[0], m[1]); err != nil {
+				t.Fatalf("game %d (%dx%d, %d in a row), move %d: %v", i, size, size, winCond, n+1, err)
+			}
+
+			b.Make(b.Cell(m[0], m[1]))
+
+			if g.State() != b.State() {
+				t.Fatalf("game %d (%dx%d, %d in a row), move %d: game state is %v, bitboard state is %v",
+					i, size, size, winCond, n+1, g.State(), b.State())
+			}
+
+			if g.State() == NotFinished && g.CurrentPlayer() != b.CurrentPlayer() {
+				t.Fatalf("game %d, move %d: the game and the bitboard disagree on the player to move", i, n+1)
+			}
+		}
+
+		if g.State() == FirstPlayerWin || g.State() == SecondPlayerWin {
+			winner, ok := g.Bitboard().Winner()
+			if !ok || GameState(winner)+1 != g.State() {
+				t.Errorf("game %d: Winner is %v, %v for state %v", i, winner, ok, g.State())
+			}
+		}
+
+		for range moves {
+			b.Unmake()
+		}
+
+		if b.State() != NotFinished || b.CurrentPlayer() != FirstPlayer || len(b.Moves()) != 0 || !b.Occupied().Empty() {
+			t.Fatalf("game %d: the bitboard is not empty after taking every move back", i)
+		}
+	}
+}
+
+// benchmarkMoves are long games on the boards of the benchmarks.
+func benchmarkMoves(size, winCond uint) [][2]uint {
+	rng := rand.New(rand.NewSource(int64(size)))
+
+	var longest [][2]uint
+
+	for i := 0; i < 20; i++ {
+		if moves := randomMoves(rng, size, winCond); len(moves) > len(longest) {
+			longest = moves
+		}
+	}
+
+	return longest
+}
+
+var benchmarkBoards = [][2]uint{{3, 3}, {15, 5}, {20, 5}}
+
+// BenchmarkGamePlay plays a game with SetField and CheckWinner and takes it
+// back with Undo.
+func BenchmarkGamePlay(b *testing.B) {
+	for _, board := range benchmarkBoards {
+		moves := benchmarkMoves(board[0], board[1])
+
+		b.Run(fmt.Sprintf("%dx%d", board[0], board[1]), func(b *testing.B) {
+			g, _ := NewGame(NewPlayer("o"), NewPlayer("x"), board[0], board[1])
+
+			for i := 0; i < b.N; i++ {
+				for _, m := range moves {
+					g.SetField(m[0], m[1])
+					g.CheckWinner(m[0], m[1])
+					g.ChangePlayer()
+				}
+
+				for range moves {
+					g.Undo()
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkBitboardPlay plays the same game as BenchmarkGamePlay with Make
+// and State and takes it back with Unmake.
+func BenchmarkBitboardPlay(b *testing.B) {
+	for _, board := range benchmarkBoards {
+		moves := benchmarkMoves(board[0], board[1])
+
+		b.Run(fmt.Sprintf("%dx%d", board[0], board[1]), func(b *testing.B) {
+			bb, _ := NewBitboard(board[0], board[1])
+
+			cells := make([]uint, len(moves))
+			for i, m := range moves {
+				cells[i] = bb.Cell(m[0], m[1])
+			}
+
+			for i := 0; i < b.N; i++ {
+				for _, cell := range cells {
+					bb.Make(cell)
+					_ = bb.State()
+				}
+
+				for range cells {
+					bb.Unmake()
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package game
+
+import "math/bits"
+
+const bitsetWords = (MaxSize*MaxSize + 63) / 64
+
+// Bitset is a set of board cells. Cell (x, y) of a board of the given size
+// has index x*size + y.
+type Bitset [bitsetWords]uint64
+
+func (b *Bitset) Set(i uint) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+func (b *Bitset) Clear(i uint) {
+	b[i/64] &^= 1 << (i % 64)
+}
+
+func (b *Bitset) Has(i uint) bool {
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+func (b Bitset) Count() int {
+	n := 0
+
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+
+	return n
+}
+
+func (b Bitset) Empty() bool {
+	for _, w := range b {
+		if w != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (b Bitset) Union(o Bitset) Bitset {
+	for i := range b {
+		b[i] |= o[i]
+	}
+
+	return b
+}
+
+func (b Bitset) Intersect(o Bitset) Bitset {
+	for i := range b {
+		b[i] &= o[i]
+	}
+
+	return b
+}
+
+func (b *Bitset) Contains(o *Bitset) bool {
+	for i := range b {
+		if b[i]&o[i] != o[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Cells returns the indices of the cells in the set in ascending order.
+func (b Bitset) Cells() []uint {
+	res := make([]uint, 0, b.Count())
+
+	for i, w := range b {
+		for w != 0 {
+			res = append(res, uint(i*64+bits.TrailingZeros64(w)))
+			w &= w - 1
+		}
+	}
+
+	return res
+}
@@ -38,6 +38,10 @@ type Game struct {
 	winCond   uint
 	empty     uint
 	strike    Strike
+	moves     []Field
+	resigned  bool
+	timedOut  bool
+	hash      uint64
 }
 
 type Field struct {
@@ -103,12 +107,107 @@ func (g *Game) SetField(x, y uint) (bool, error) {
 	if g.fields[x][y] == EmptyField {
 		g.fields[x][y] = FieldType(g.curplayer) + 1
 		g.empty -= 1
+		g.hash ^= zobristKey(x, y, g.curplayer)
+		g.moves = append(g.moves, Field{X: x, Y: y, Ft: g.fields[x][y]})
 		return true, nil
 	}
 
 	return false, nil
 }
 
+func (g *Game) MakeMove(x, y uint) error {
+	suc, err := g.SetField(x, y)
+	if err != nil {
+		return err
+	}
+
+	if !suc {
+		return fmt.Errorf("the field is already taken")
+	}
+
+	// A move filling the last cell may still win.
+	if _, win := g.CheckWinner(x, y); win {
+		return nil
+	}
+
+	if draw := g.CheckDraw(); draw {
+		return nil
+	}
+
+	g.ChangePlayer()
+
+	return nil
+}
+
+func (g *Game) Undo() (Field, error) {
+	if len(g.moves) == 0 {
+		return Field{}, fmt.Errorf("there are no moves to undo")
+	}
+
+	last := g.moves[len(g.moves)-1]
+	g.moves = g.moves[:len(g.moves)-1]
+
+	g.fields[last.X][last.Y] = EmptyField
+	g.empty += 1
+	g.hash ^= zobristKey(last.X, last.Y, PlayerType(last.Ft-1))
+	g.curplayer = PlayerType(last.Ft - 1)
+	g.state = NotFinished
+	g.strike = Strike{}
+	g.resigned = false
+	g.timedOut = false
+
+	return last, nil
+}
+
+func (g *Game) Resign(pt PlayerType) error {
+	if g.state != NotFinished {
+		return fmt.Errorf("game over")
+	}
+
+	g.state = GameState((pt+1)%2) + 1
+	g.resigned = true
+
+	return nil
+}
+
+func (g *Game) Resigned() bool {
+	return g.resigned
+}
+
+// LoseOnTime ends the game with the loss of the player, who has run out of
+// time.
+func (g *Game) LoseOnTime(pt PlayerType) error {
+	if g.state != NotFinished {
+		return fmt.Errorf("game over")
+	}
+
+	g.state = GameState((pt+1)%2) + 1
+	g.timedOut = true
+
+	return nil
+}
+
+func (g *Game) TimedOut() bool {
+	return g.timedOut
+}
+
+func (g *Game) Clone() *Game {
+	c := *g
+	c.players = append([]*Player(nil), g.players...)
+	c.moves = append([]Field(nil), g.moves...)
+	c.fields = make([][]FieldType, g.size)
+
+	fieldsSub := make([]FieldType, g.size*g.size)
+
+	for i := uint(0); i < g.size; i++ {
+		c.fields[i] = fieldsSub[:g.size]
+		copy(c.fields[i], g.fields[i])
+		fieldsSub = fieldsSub[g.size:]
+	}
+
+	return &c
+}
+
 func (g *Game) Field(x, y uint) (FieldType, error) {
 	if x >= g.size || y >= g.size {
 		return EmptyField, fmt.Errorf("out of board bounds")
@@ -121,6 +220,14 @@ func (g *Game) Size() uint {
 	return g.size
 }
 
+func (g *Game) WinCond() uint {
+	return g.winCond
+}
+
+func (g *Game) Moves() []Field {
+	return append([]Field(nil), g.moves...)
+}
+
 func (g *Game) CurrentPlayer() PlayerType {
 	return g.curplayer
 }
@@ -219,6 +326,14 @@ func (g *Game) Strike() (Strike, error) {
 		return Strike{}, fmt.Errorf("game is not finished")
 	}
 
+	if g.resigned {
+		return Strike{}, fmt.Errorf("game was resigned")
+	}
+
+	if g.timedOut {
+		return Strike{}, fmt.Errorf("game was lost on time")
+	}
+
 	return g.strike, nil
 }
 
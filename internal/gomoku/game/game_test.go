@@ -0,0 +1,59 @@
+package game
+
+import "testing"
+
+func TestLastCellWin(t *testing.T) {
+	g, _ := NewGame(NewPlayer("o"), NewPlayer("x"), 3, 3)
+	b, _ := NewBitboard(3, 3)
+
+	// The first player fills the last cell with the diagonal.
+	moves := [][2]uint{{0, 0}, {0, 1}, {0, 2}, {1, 0}, {1, 1}, {1, 2}, {2, 1}, {2, 0}, {2, 2}}
+
+	for i, m := range moves {
+		if g.State() != NotFinished {
+			t.Fatalf("the game is over with state %v before move %d", g.State(), i+1)
+		}
+
+		if err := g.MakeMove(m[0], m[1]); err != nil {
+			t.Fatal(err)
+		}
+
+		b.Make(b.Cell(m[0], m[1]))
+	}
+
+	if g.State() != FirstPlayerWin {
+		t.Errorf("game state is %v, want %v", g.State(), FirstPlayerWin)
+	}
+
+	if b.State() != g.State() {
+		t.Errorf("bitboard state is %v, game state is %v", b.State(), g.State())
+	}
+
+	want := Strike{X0: 0, Y0: 0, X1: 2, Y1: 2}
+
+	if s, err := g.Strike(); err != nil || s != want {
+		t.Errorf("strike is %v, %v, want %v", s, err, want)
+	}
+
+	if _, err := g.Undo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if g.State() != NotFinished || g.CurrentPlayer() != FirstPlayer {
+		t.Errorf("after undo the state is %v with player %v to move", g.State(), g.CurrentPlayer())
+	}
+}
+
+func TestLastCellDraw(t *testing.T) {
+	g, _ := NewGame(NewPlayer("o"), NewPlayer("x"), 3, 3)
+
+	for _, m := range [][2]uint{{0, 0}, {1, 1}, {2, 2}, {0, 2}, {2, 0}, {1, 0}, {1, 2}, {2, 1}, {0, 1}} {
+		if err := g.MakeMove(m[0], m[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if g.State() != NobodyWins {
+		t.Errorf("game state is %v, want %v", g.State(), NobodyWins)
+	}
+}
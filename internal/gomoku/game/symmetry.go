@@ -0,0 +1,187 @@
+package game
+
+import "fmt"
+
+// Transform is one of the 8 symmetries of a square board: the rotations and
+// reflections that turn a position into an equivalent one.
+type Transform uint
+
+const (
+	Identity Transform = iota
+	Rotate90
+	Rotate180
+	Rotate270
+	FlipX
+	FlipY
+	Transpose
+	AntiTranspose
+
+	TransformCount
+)
+
+func (t Transform) String() string {
+	switch t {
+	case Identity:
+		return "identity"
+	case Rotate90:
+		return "rotation by 90 degrees"
+	case Rotate180:
+		return "rotation by 180 degrees"
+	case Rotate270:
+		return "rotation by 270 degrees"
+	case FlipX:
+		return "horizontal reflection"
+	case FlipY:
+		return "vertical reflection"
+	case Transpose:
+		return "reflection in the main diagonal"
+	case AntiTranspose:
+		return "reflection in the anti-diagonal"
+	}
+
+	return fmt.Sprintf("Transform(%d)", uint(t))
+}
+
+// Apply returns where the transform moves the square (x, y) of a board of
+// the size.
+func (t Transform) Apply(x, y, size uint) (uint, uint) {
+	n := size - 1
+
+	switch t {
+	case Rotate90:
+		return n - y, x
+	case Rotate180:
+		return n - x, n - y
+	case Rotate270:
+		return y, n - x
+	case FlipX:
+		return n - x, y
+	case FlipY:
+		return x, n - y
+	case Transpose:
+		return y, x
+	case AntiTranspose:
+		return n - y, n - x
+	}
+
+	return x, y
+}
+
+// Inverse returns the transform taking the squares back.
+func (t Transform) Inverse() Transform {
+	switch t {
+	case Rotate90:
+		return Rotate270
+	case Rotate270:
+		return Rotate90
+	}
+
+	return t
+}
+
+// TransformCell returns where the transform moves the cell of the bitboard.
+func (b *Bitboard) TransformCell(t Transform, cell uint) uint {
+	return uint(b.layout.symmetry[t][cell])
+}
+
+// Canonical returns the canonical hash of the position, the smallest hash
+// over the symmetries of the board, and the transform that turns the position
+// into the one with that hash. Equivalent positions have the same canonical
+// hash. If the position is symmetric itself, more than one transform gives
+// it and the first one is returned.
+func (b *Bitboard) Canonical() (uint64, Transform) {
+	best := Identity
+
+	for t := Rotate90; t < TransformCount; t++ {
+		if b.hashes[t] < b.hashes[best] {
+			best = t
+		}
+	}
+
+	return zobristHash(b.hashes[best], b.curplayer), best
+}
+
+// CanonicalHash returns the canonical hash of the position.
+func (b *Bitboard) CanonicalHash() uint64 {
+	h, _ := b.Canonical()
+	return h
+}
+
+// Symmetries returns the transforms that turn the position into the one with
+// the canonical hash.
+func (b *Bitboard) Symmetries() []Transform {
+	_, best := b.Canonical()
+	res := []Transform{best}
+
+	for t := best + 1; t < TransformCount; t++ {
+		if b.hashes[t] == b.hashes[best] {
+			res = append(res, t)
+		}
+	}
+
+	return res
+}
+
+// Canonical returns the canonical hash of the position of the game and the
+// transform that gives it, see Bitboard.Canonical.
+func (g *Game) Canonical() (uint64, Transform) {
+	return g.Bitboard().Canonical()
+}
+
+// Transform returns a copy of the game with every move turned by the
+// transform. The moves stay in the same order.
+func (g *Game) Transform(t Transform) *Game {
+	c, _ := NewGame(g.players[FirstPlayer], g.players[SecondPlayer], g.size, g.winCond)
+
+	for _, f := range g.moves {
+		x, y := t.Apply(f.X, f.Y, g.size)
+
+		c.curplayer = PlayerType(f.Ft - 1)
+		c.MakeMove(x, y)
+	}
+
+	c.curplayer = g.curplayer
+	c.state = g.state
+	c.resigned = g.resigned
+	c.timedOut = g.timedOut
+
+	return c
+}
+
+// CanonicalMoves returns the moves of the game turned by the transform that
+// gives the smallest sequence of moves, and the transform. Games that are
+// the same up to a symmetry of the board have the same canonical moves.
+func (g *Game) CanonicalMoves() ([]Field, Transform) {
+	best := g.Moves()
+	bestT := Identity
+
+	moves := make([]Field, len(g.moves))
+
+	for t := Rotate90; t < TransformCount; t++ {
+		for i, f := range g.moves {
+			x, y := t.Apply(f.X, f.Y, g.size)
+			moves[i] = Field{X: x, Y: y, Ft: f.Ft}
+		}
+
+		if lessMoves(moves, best) {
+			best, moves = moves, best
+			bestT = t
+		}
+	}
+
+	return best, bestT
+}
+
+func lessMoves(a, b []Field) bool {
+	for i := range a {
+		if a[i].X != b[i].X {
+			return a[i].X < b[i].X
+		}
+
+		if a[i].Y != b[i].Y {
+			return a[i].Y < b[i].Y
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,193 @@
+package game
+
+import "testing"
+
+var symmetrySizes = []uint{3, 4, 14, 15}
+
+func TestTransformInverse(t *testing.T) {
+	for _, size := range symmetrySizes {
+		for tr := Identity; tr < TransformCount; tr++ {
+			inv := tr.Inverse()
+
+			for x := uint(0); x < size; x++ {
+				for y := uint(0); y < size; y++ {
+					tx, ty := tr.Apply(x, y, size)
+
+					if tx >= size || ty >= size {
+						t.Fatalf("size %d: %v moves %d,%d off the board to %d,%d", size, tr, x, y, tx, ty)
+					}
+
+					if ix, iy := inv.Apply(tx, ty, size); ix != x || iy != y {
+						t.Fatalf("size %d: %v after %v moves %d,%d to %d,%d", size, inv, tr, x, y, ix, iy)
+					}
+				}
+			}
+		}
+	}
+}
+
+// sameTransform tells whether two functions move every square of the board
+// to the same square.
+func sameTransform(size uint, f, g func(x, y uint) (uint, uint)) bool {
+	for x := uint(0); x < size; x++ {
+		for y := uint(0); y < size; y++ {
+			fx, fy := f(x, y)
+			gx, gy := g(x, y)
+
+			if fx != gx || fy != gy {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func TestTransformGroup(t *testing.T) {
+	for _, size := range symmetrySizes {
+		for t1 := Identity; t1 < TransformCount; t1++ {
+			for t2 := t1 + 1; t2 < TransformCount; t2++ {
+				a, b := t1, t2
+
+				if sameTransform(size, func(x, y uint) (uint, uint) { return a.Apply(x, y, size) },
+					func(x, y uint) (uint, uint) { return b.Apply(x, y, size) }) {
+					t.Errorf("size %d: %v and %v are the same", size, t1, t2)
+				}
+			}
+		}
+
+		for t1 := Identity; t1 < TransformCount; t1++ {
+			for t2 := Identity; t2 < TransformCount; t2++ {
+				a, b := t1, t2
+				composed := func(x, y uint) (uint, uint) {
+					x, y = a.Apply(x, y, size)
+					return b.Apply(x, y, size)
+				}
+
+				found := false
+
+				for t3 := Identity; t3 < TransformCount && !found; t3++ {
+					c := t3
+					found = sameTransform(size, composed, func(x, y uint) (uint, uint) { return c.Apply(x, y, size) })
+				}
+
+				if !found {
+					t.Errorf("size %d: %v after %v is not a symmetry", size, t2, t1)
+				}
+			}
+		}
+	}
+}
+
+func TestTransformCell(t *testing.T) {
+	for _, size := range symmetrySizes {
+		b, _ := NewBitboard(size, 3)
+
+		for tr := Identity; tr < TransformCount; tr++ {
+			for x := uint(0); x < size; x++ {
+				for y := uint(0); y < size; y++ {
+					tx, ty := tr.Apply(x, y, size)
+
+					if got := b.TransformCell(tr, b.Cell(x, y)); got != b.Cell(tx, ty) {
+						t.Fatalf("size %d: %v moves cell %d to %d, want %d", size, tr, b.Cell(x, y), got, b.Cell(tx, ty))
+					}
+				}
+			}
+		}
+	}
+}
+
+// asymmetricGame returns a game whose position no symmetry of the board keeps.
+func asymmetricGame(t *testing.T, size uint) *Game {
+	t.Helper()
+
+	g, _ := NewGame(NewPlayer("o"), NewPlayer("x"), size, 3)
+
+	for _, m := range [][2]uint{{0, 0}, {1, 0}, {0, 2}} {
+		if err := g.MakeMove(m[0], m[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return g
+}
+
+func TestCanonical(t *testing.T) {
+	for _, size := range symmetrySizes {
+		g := asymmetricGame(t, size)
+		want, _ := g.Canonical()
+		wantMoves, _ := g.CanonicalMoves()
+
+		images := make(map[uint64]Transform)
+
+		for tr := Identity; tr < TransformCount; tr++ {
+			c := g.Transform(tr)
+
+			if h, _ := c.Canonical(); h != want {
+				t.Errorf("size %d: the canonical hash after %v is %x, want %x", size, tr, h, want)
+			}
+
+			if h := c.Bitboard().CanonicalHash(); h != want {
+				t.Errorf("size %d: the canonical hash of the bitboard after %v is %x, want %x", size, tr, h, want)
+			}
+
+			if moves, _ := c.CanonicalMoves(); !equalFields(moves, wantMoves) {
+				t.Errorf("size %d: the canonical moves after %v are %v, want %v", size, tr, moves, wantMoves)
+			}
+
+			if prev, ok := images[c.Hash()]; ok {
+				t.Errorf("size %d: %v and %v give the same position", size, prev, tr)
+			}
+
+			images[c.Hash()] = tr
+		}
+
+		if len(images) != int(TransformCount) {
+			t.Errorf("size %d: %d distinct images, want %d", size, len(images), TransformCount)
+		}
+
+		b := g.Bitboard()
+		h, tr := b.Canonical()
+
+		if syms := b.Symmetries(); len(syms) != 1 || syms[0] != tr {
+			t.Errorf("size %d: symmetries are %v, want only %v", size, syms, tr)
+		}
+
+		if h != g.Transform(tr).Hash() {
+			t.Errorf("size %d: %v does not give the canonical hash", size, tr)
+		}
+	}
+}
+
+func TestCanonicalSymmetric(t *testing.T) {
+	// A stone in the center of an odd board is kept by every symmetry.
+	g, _ := NewGame(NewPlayer("o"), NewPlayer("x"), 15, 5)
+	g.MakeMove(7, 7)
+
+	if syms := g.Bitboard().Symmetries(); len(syms) != int(TransformCount) {
+		t.Errorf("symmetries of the center stone are %v, want all of them", syms)
+	}
+
+	// A diagonal is kept by the identity and the reflection in it.
+	g, _ = NewGame(NewPlayer("o"), NewPlayer("x"), 14, 5)
+	g.MakeMove(2, 2)
+	g.MakeMove(5, 5)
+
+	if syms := g.Bitboard().Symmetries(); len(syms) != 2 {
+		t.Errorf("symmetries of the diagonal are %v, want 2 of them", syms)
+	}
+}
+
+func equalFields(a, b []Field) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,53 @@
+package game
+
+// Zobrist keys are generated from a fixed seed, so hashes stay the same
+// between runs and can be stored in files.
+const zobristSeed = 0x676f6d6f6b753267
+
+var (
+	zobristKeys [MaxSize * MaxSize][2]uint64
+	zobristSide uint64
+)
+
+func init() {
+	state := uint64(zobristSeed)
+
+	next := func() uint64 {
+		state += 0x9e3779b97f4a7c15
+
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+
+		return z ^ (z >> 31)
+	}
+
+	for i := range zobristKeys {
+		zobristKeys[i][FirstPlayer] = next()
+		zobristKeys[i][SecondPlayer] = next()
+	}
+
+	zobristSide = next()
+}
+
+func zobristKey(x, y uint, pt PlayerType) uint64 {
+	return zobristKeys[x*MaxSize+y][pt]
+}
+
+func zobristHash(stones uint64, curplayer PlayerType) uint64 {
+	if curplayer == SecondPlayer {
+		return stones ^ zobristSide
+	}
+
+	return stones
+}
+
+// Hash returns the Zobrist hash of the position: the stones on the board and
+// the player to move.
+func (g *Game) Hash() uint64 {
+	return zobristHash(g.hash, g.curplayer)
+}
+
+func (b *Bitboard) Hash() uint64 {
+	return zobristHash(b.hashes[Identity], b.curplayer)
+}
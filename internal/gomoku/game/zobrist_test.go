@@ -0,0 +1,117 @@
+package game
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// scratchHash computes the hash of the position of the game from its stones.
+func scratchHash(g *Game) uint64 {
+	var stones uint64
+
+	for _, f := range g.NotEmptyFields() {
+		stones ^= zobristKey(f.X, f.Y, PlayerType(f.Ft-1))
+	}
+
+	return zobristHash(stones, g.CurrentPlayer())
+}
+
+func TestHashIncremental(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 200; i++ {
+		size := MinSize + uint(rng.Intn(MaxSize-MinSize+1))
+		winCond := MinSize + uint(rng.Intn(int(size-MinSize+1)))
+
+		g, _ := NewGame(NewPlayer("o"), NewPlayer("x"), size, winCond)
+		b, _ := NewBitboard(size, winCond)
+
+		var hashes []uint64
+
+		for _, m := range randomMoves(rng, size, winCond) {
+			hashes = append(hashes, b.Hash())
+
+			g.MakeMove(m[0], m[1])
+			b.Make(b.Cell(m[0], m[1]))
+
+			// A finished game keeps the winner to move, the bitboard passes
+			// the turn anyway.
+			if g.State() != NotFinished {
+				break
+			}
+
+			if h := scratchHash(g); g.Hash() != h {
+				t.Fatalf("game %d: the hash of the game is %x, from scratch %x", i, g.Hash(), h)
+			}
+
+			if b.Hash() != g.Hash() {
+				t.Fatalf("game %d: the hash of the bitboard is %x, of the game %x", i, b.Hash(), g.Hash())
+			}
+
+			if h := g.Bitboard().Hash(); b.Hash() != h {
+				t.Fatalf("game %d: the hash of the bitboard is %x, of a new bitboard %x", i, b.Hash(), h)
+			}
+		}
+
+		for n := len(hashes) - 1; n >= 0; n-- {
+			b.Unmake()
+
+			if b.Hash() != hashes[n] {
+				t.Fatalf("game %d: the hash after taking back move %d is %x, want %x", i, n+1, b.Hash(), hashes[n])
+			}
+
+			g.Undo()
+
+			if h := scratchHash(g); g.Hash() != h {
+				t.Fatalf("game %d: the hash of the game after undo is %x, from scratch %x", i, g.Hash(), h)
+			}
+		}
+
+		if b.Hash() != 0 || g.Hash() != 0 {
+			t.Fatalf("game %d: the hash of the empty board is %x and %x, want 0", i, b.Hash(), g.Hash())
+		}
+	}
+}
+
+func TestHashTransposition(t *testing.T) {
+	orders := [][][2]uint{
+		{{7, 7}, {8, 8}, {6, 7}, {9, 9}, {5, 7}},
+		{{5, 7}, {9, 9}, {7, 7}, {8, 8}, {6, 7}},
+		{{6, 7}, {8, 8}, {5, 7}, {9, 9}, {7, 7}},
+	}
+
+	var want uint64
+
+	for i, moves := range orders {
+		g, _ := NewGame(NewPlayer("o"), NewPlayer("x"), 15, 5)
+		b, _ := NewBitboard(15, 5)
+
+		for _, m := range moves {
+			if err := g.MakeMove(m[0], m[1]); err != nil {
+				t.Fatal(err)
+			}
+
+			b.Make(b.Cell(m[0], m[1]))
+		}
+
+		if i == 0 {
+			want = g.Hash()
+		}
+
+		if g.Hash() != want || b.Hash() != want {
+			t.Errorf("order %d: hashes are %x and %x, want %x", i, g.Hash(), b.Hash(), want)
+		}
+	}
+
+	// The same stones with the other player to move are another position.
+	g, _ := NewGame(NewPlayer("o"), NewPlayer("x"), 15, 5)
+	g.MakeMove(7, 7)
+	g.MakeMove(8, 8)
+
+	h := g.Hash()
+	g.ChangePlayer()
+
+	if g.Hash() == h {
+		t.Error("the player to move does not change the hash")
+	}
+}
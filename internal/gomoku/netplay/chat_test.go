@@ -0,0 +1,138 @@
+package netplay
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func receiveChat(t *testing.T, ch chan Chat, what string) Chat {
+	t.Helper()
+
+	select {
+	case c := <-ch:
+		return c
+	case <-time.After(testTimeout):
+		timedOut(t, what)
+	}
+
+	return Chat{}
+}
+
+func TestChat(t *testing.T) {
+	host, guest, hev, gev := startGame(t)
+
+	spectator := NewSpectator(host.Addr().String(), "Carol")
+	sev := watch(spectator)
+
+	if err := spectator.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer spectator.Close()
+
+	receiveSettings(t, sev.start, "the spectator to start")
+
+	sent, err := guest.SendChat("  good luck  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Chat{From: "Bob", Text: "good luck", Time: sent.Time}
+
+	if sent != want {
+		t.Errorf("the guest sent %+v, want %+v", sent, want)
+	}
+
+	if c := receiveChat(t, hev.chat, "the host to get the chat"); c != want {
+		t.Errorf("the host got %+v, want %+v", c, want)
+	}
+
+	if c := receiveChat(t, sev.chat, "the spectator to get the chat"); c != want {
+		t.Errorf("the spectator got %+v, want %+v", c, want)
+	}
+
+	if _, err := host.SendChat("thanks"); err != nil {
+		t.Fatal(err)
+	}
+
+	if c := receiveChat(t, gev.chat, "the guest to get the chat"); c.From != "Alice" || c.Text != "thanks" {
+		t.Errorf("the guest got %+v, want thanks from Alice", c)
+	}
+
+	if c := receiveChat(t, sev.chat, "the spectator to get the chat"); c.From != "Alice" || c.Text != "thanks" {
+		t.Errorf("the spectator got %+v, want thanks from Alice", c)
+	}
+
+	if _, err := spectator.SendChat("hello"); err == nil {
+		t.Error("the spectator sent a chat")
+	}
+}
+
+func TestChatLimits(t *testing.T) {
+	_, guest, _, _ := startGame(t)
+
+	for _, text := range []string{"", "   ", strings.Repeat("ж", MaxChatLength+1)} {
+		if _, err := guest.SendChat(text); err == nil {
+			t.Errorf("a chat of %d characters was sent", len([]rune(text)))
+		}
+	}
+
+	if _, err := guest.SendChat(strings.Repeat("ж", MaxChatLength)); err != nil {
+		t.Errorf("a chat of the longest length was not sent: %v", err)
+	}
+
+	// One of the burst has been used already.
+	for i := 1; i < chatBurst; i++ {
+		if _, err := guest.SendChat("spam"); err != nil {
+			t.Fatalf("chat %d of the burst: %v", i+1, err)
+		}
+	}
+
+	if _, err := guest.SendChat("spam"); err == nil || !strings.Contains(err.Error(), "too fast") {
+		t.Errorf("a chat over the burst gave %v, want a rate error", err)
+	}
+}
+
+func TestChatFromRawGuest(t *testing.T) {
+	host, hev := startHost(t)
+	conn := dialRaw(t, host.Addr().String())
+
+	conn.Send(&Message{Type: HelloMessage, Version: ProtocolVersion, Name: "Bob", Role: playerRole})
+	receiveType(t, conn)
+	receiveSettings(t, hev.start, "the host to start")
+
+	// The host drops chats that are too long and over the rate, and names
+	// the sender itself.
+	send := func(text string) {
+		conn.Send(&Message{Type: ChatMessage, Chat: &Chat{From: "Mallory", Text: text}})
+	}
+
+	send(strings.Repeat("x", MaxChatLength+1))
+	send(" ")
+
+	for i := 0; i < chatBurst+3; i++ {
+		send("hi")
+	}
+
+	for i := 0; i < chatBurst; i++ {
+		if c := receiveChat(t, hev.chat, "the chat of the guest"); c.From != "Bob" || c.Text != "hi" {
+			t.Fatalf("the host got %+v, want hi from Bob", c)
+		}
+	}
+
+	// A move after the chats makes sure they have all been handled.
+	if err := host.SendMove(7, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	receiveType(t, conn)
+	conn.Send(&Message{Type: MoveMessage, Move: &Move{X: 8, Y: 8, Seq: 1}})
+	receiveMove(t, hev.move, "the move of the guest")
+
+	select {
+	case c := <-hev.chat:
+		t.Errorf("the host got %+v over the rate", c)
+	default:
+	}
+}
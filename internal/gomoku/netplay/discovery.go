@@ -0,0 +1,257 @@
+package netplay
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	DiscoveryPort = 7655
+
+	announceInterval = time.Second
+	gameTTL          = 5 * time.Second
+
+	announceType = "announce"
+)
+
+type Announcement struct {
+	Type    string `json:"type"`
+	Version uint   `json:"version"`
+	Name    string `json:"name"`
+	Port    uint   `json:"port"`
+	Size    uint   `json:"size"`
+	WinCond uint   `json:"wincond"`
+	Open    bool   `json:"open"`
+}
+
+type LANGame struct {
+	Announcement
+
+	Addr string
+	seen time.Time
+}
+
+type Announcer struct {
+	conn     net.PacketConn
+	target   *net.UDPAddr
+	announce func() Announcement
+	done     chan struct{}
+	once     sync.Once
+}
+
+func NewAnnouncer(target string, announce func() Announcement) (*Announcer, error) {
+	if target == "" {
+		target = fmt.Sprintf("255.255.255.255:%d", DiscoveryPort)
+	}
+
+	addr, err := net.ResolveUDPAddr("udp4", target)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Announcer{
+		conn:     conn,
+		target:   addr,
+		announce: announce,
+		done:     make(chan struct{}),
+	}, nil
+}
+
+func (a *Announcer) Start() {
+	go func() {
+		ticker := time.NewTicker(announceInterval)
+		defer ticker.Stop()
+
+		for {
+			a.send()
+
+			select {
+			case <-a.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (a *Announcer) send() {
+	ann := a.announce()
+	ann.Type = announceType
+	ann.Version = ProtocolVersion
+
+	data, err := json.Marshal(ann)
+	if err != nil {
+		return
+	}
+
+	a.conn.WriteTo(data, a.target)
+}
+
+func (a *Announcer) Stop() {
+	a.once.Do(func() {
+		close(a.done)
+		a.conn.Close()
+	})
+}
+
+type Browser struct {
+	addr string
+	conn net.PacketConn
+
+	mu            sync.Mutex
+	games         map[string]*LANGame
+	updateHandler func(games []LANGame)
+	once          sync.Once
+}
+
+func NewBrowser(addr string) *Browser {
+	if addr == "" {
+		addr = fmt.Sprintf(":%d", DiscoveryPort)
+	}
+
+	return &Browser{
+		addr:          addr,
+		games:         make(map[string]*LANGame),
+		updateHandler: func([]LANGame) {},
+	}
+}
+
+func (b *Browser) ConnectUpdate(handler func(games []LANGame)) {
+	b.updateHandler = handler
+}
+
+func (b *Browser) Addr() net.Addr {
+	if b.conn == nil {
+		return nil
+	}
+
+	return b.conn.LocalAddr()
+}
+
+func (b *Browser) Start() error {
+	lc := net.ListenConfig{Control: reuseAddr}
+
+	conn, err := lc.ListenPacket(context.Background(), "udp4", b.addr)
+	if err != nil {
+		return err
+	}
+
+	b.conn = conn
+
+	go b.listen()
+
+	return nil
+}
+
+func (b *Browser) Stop() {
+	b.once.Do(func() {
+		if b.conn != nil {
+			b.conn.Close()
+		}
+	})
+}
+
+func (b *Browser) Games() []LANGame {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.gamesLocked()
+}
+
+func (b *Browser) gamesLocked() []LANGame {
+	games := make([]LANGame, 0, len(b.games))
+
+	for _, g := range b.games {
+		games = append(games, *g)
+	}
+
+	sort.Slice(games, func(i, j int) bool {
+		return games[i].Addr < games[j].Addr
+	})
+
+	return games
+}
+
+func (b *Browser) listen() {
+	buf := make([]byte, 2048)
+
+	for {
+		b.conn.SetReadDeadline(time.Now().Add(announceInterval))
+
+		n, from, err := b.conn.ReadFrom(buf)
+		if err != nil {
+			if nerr, ok := err.(net.Error); !ok || !nerr.Timeout() {
+				return
+			}
+		}
+
+		changed := b.expire()
+
+		if n > 0 && from != nil {
+			if b.add(buf[:n], from) {
+				changed = true
+			}
+		}
+
+		if changed {
+			b.updateHandler(b.Games())
+		}
+	}
+}
+
+func (b *Browser) add(data []byte, from net.Addr) bool {
+	var ann Announcement
+
+	if err := json.Unmarshal(data, &ann); err != nil {
+		return false
+	}
+
+	if ann.Type != announceType || ann.Version != ProtocolVersion || ann.Port == 0 {
+		return false
+	}
+
+	udpAddr, ok := from.(*net.UDPAddr)
+	if !ok {
+		return false
+	}
+
+	addr := net.JoinHostPort(udpAddr.IP.String(), fmt.Sprint(ann.Port))
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	old, exists := b.games[addr]
+	b.games[addr] = &LANGame{
+		Announcement: ann,
+		Addr:         addr,
+		seen:         time.Now(),
+	}
+
+	return !exists || old.Announcement != ann
+}
+
+func (b *Browser) expire() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	changed := false
+
+	for addr, g := range b.games {
+		if time.Since(g.seen) > gameTTL {
+			delete(b.games, addr)
+			changed = true
+		}
+	}
+
+	return changed
+}
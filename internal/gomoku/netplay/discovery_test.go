@@ -0,0 +1,148 @@
+package netplay
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func startBrowser(t *testing.T, addr string) (*Browser, chan []LANGame) {
+	t.Helper()
+
+	updates := make(chan []LANGame, 16)
+
+	b := NewBrowser(addr)
+	b.ConnectUpdate(func(games []LANGame) { updates <- games })
+
+	if err := b.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(b.Stop)
+
+	return b, updates
+}
+
+func receiveGames(t *testing.T, updates chan []LANGame) []LANGame {
+	t.Helper()
+
+	select {
+	case games := <-updates:
+		return games
+	case <-time.After(testTimeout):
+		timedOut(t, "an update of the games")
+	}
+
+	return nil
+}
+
+func TestDiscovery(t *testing.T) {
+	b, updates := startBrowser(t, "127.0.0.1:0")
+
+	ann := Announcement{Name: "Alice", Port: 7654, Size: 15, WinCond: 5, Open: true}
+
+	a, err := NewAnnouncer(b.Addr().String(), func() Announcement { return ann })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a.Start()
+	defer a.Stop()
+
+	games := receiveGames(t, updates)
+	if len(games) != 1 {
+		t.Fatalf("got %d games, want 1", len(games))
+	}
+
+	g := games[0]
+
+	if g.Addr != "127.0.0.1:7654" {
+		t.Errorf("game address is %s, want 127.0.0.1:7654", g.Addr)
+	}
+
+	want := ann
+	want.Type = announceType
+	want.Version = ProtocolVersion
+
+	if g.Announcement != want {
+		t.Errorf("announcement is %+v, want %+v", g.Announcement, want)
+	}
+
+	if len(b.Games()) != 1 {
+		t.Errorf("the browser holds %d games, want 1", len(b.Games()))
+	}
+}
+
+func TestDiscoveryIgnoresForeignDatagrams(t *testing.T) {
+	b, updates := startBrowser(t, "127.0.0.1:0")
+
+	conn, err := net.Dial("udp4", b.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	send := func(v interface{}) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := conn.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conn.Write([]byte("not json"))
+	send(Announcement{Type: "hello", Version: ProtocolVersion, Name: "Eve", Port: 1})
+	send(Announcement{Type: announceType, Version: ProtocolVersion + 1, Name: "Eve", Port: 2})
+	send(Announcement{Type: announceType, Version: ProtocolVersion, Name: "Eve"})
+	send(Announcement{Type: announceType, Version: ProtocolVersion, Name: "Bob", Port: 3})
+
+	games := receiveGames(t, updates)
+	if len(games) != 1 || games[0].Name != "Bob" {
+		t.Fatalf("got games %+v, want only the one of Bob", games)
+	}
+}
+
+func TestDiscoveryExpires(t *testing.T) {
+	b := NewBrowser("127.0.0.1:0")
+
+	ann := Announcement{Type: announceType, Version: ProtocolVersion, Name: "Alice", Port: 7654}
+	data, _ := json.Marshal(ann)
+	from := &net.UDPAddr{IP: net.IPv4(192, 168, 1, 2), Port: 40000}
+
+	if !b.add(data, from) {
+		t.Fatal("a new game is not a change")
+	}
+
+	if b.add(data, from) {
+		t.Error("the same announcement again is a change")
+	}
+
+	if b.expire() {
+		t.Error("a fresh game expired")
+	}
+
+	b.games["192.168.1.2:7654"].seen = time.Now().Add(-gameTTL - time.Second)
+
+	if !b.expire() || len(b.Games()) != 0 {
+		t.Error("a game announced too long ago is still listed")
+	}
+}
+
+func TestBrowsersShareThePort(t *testing.T) {
+	first, _ := startBrowser(t, "127.0.0.1:0")
+
+	addr := fmt.Sprintf("127.0.0.1:%d", first.Addr().(*net.UDPAddr).Port)
+
+	second := NewBrowser(addr)
+	if err := second.Start(); err != nil {
+		t.Fatalf("a second browser cannot listen on %s: %v", addr, err)
+	}
+
+	second.Stop()
+}
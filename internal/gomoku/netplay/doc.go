@@ -0,0 +1,84 @@
+// Package netplay implements network play between two gomoku2go instances.
+//
+// One instance hosts a game on a TCP port and another one joins it by
+// host:port. The host always plays as the first player.
+//
+// # Protocol
+//
+// Peers exchange newline-delimited JSON objects. Every message has a "type"
+// field, the rest of the fields depend on the type. Unknown fields must be
+// ignored. The current protocol version is 1.
+//
+//	hello    guest -> host   {"type":"hello","version":1,"name":"Bob",
+//	                          "role":"player"}
+//	                         {"type":"hello","version":1,"name":"Bob",
+//	                          "role":"player","session":"…","moves":[…]}
+//	                         {"type":"hello","version":1,"name":"Eve",
+//	                          "role":"spectator"}
+//	welcome  host -> guest   {"type":"welcome","version":1,"session":"…",
+//	                          "settings":{…},"moves":[…]}
+//	newgame  both            {"type":"newgame","settings":{…}}
+//	move     both            {"type":"move","move":{"x":3,"y":4,"seq":7}}
+//	result   host -> guest   {"type":"result","state":1,"strike":{…}}
+//	clock    host -> guest   {"type":"clock","clock":{"elapsed":61000,
+//	                          "running":true}}
+//	chat     both            {"type":"chat","chat":{"from":"Bob",
+//	                          "text":"gg","time":1634567890123}}
+//	ping     both            {"type":"ping"}
+//	bye      both            {"type":"bye"}
+//	error    both            {"type":"error","error":"…"}
+//
+// A guest starts by sending hello. The host answers with welcome, which
+// carries a session token, the game settings (board size, win condition and
+// player names) and the list of moves played so far. A hello with a version
+// the host does not speak is answered with an error message.
+//
+// Moves carry a sequence number equal to the number of moves played before
+// them. Both peers replay every move on their own copy of the game and treat
+// an invalid move, a move out of turn or a wrong sequence number as a fatal
+// error. When the game ends the host sends result, and the guest checks it
+// against its own copy.
+//
+// Only the host starts new games. A newgame message sent by the guest is a
+// request, the host answers it with a newgame message carrying the settings.
+//
+// Peers send ping every 5 seconds and consider the connection lost when
+// nothing has been received for 15 seconds. A lost connection pauses the
+// game. The guest then keeps redialing the host and sends hello with the
+// session token and its list of moves. The host merges the lists (a peer
+// can be ahead by the moves it failed to deliver), answers with welcome
+// holding the merged list and the game resumes. bye ends the session for
+// good.
+//
+// # Chat
+//
+// Players may exchange chat messages over the game connection. time is the
+// sending time in milliseconds since the Unix epoch. The receiver replaces
+// from with the name the sender plays under, and silently drops empty
+// messages, messages longer than 500 characters and messages beyond a rate
+// of one per second with bursts of five.
+//
+// # Spectators
+//
+// A hello with the "spectator" role joins the game read-only, any number of
+// spectators may watch a game. The welcome sent to a spectator carries the
+// full move list and the host clock. Afterwards the host forwards every
+// move, newgame and result message of the game to spectators, each with a
+// "clock" field holding the elapsed game time in milliseconds and whether
+// the clock is running, and sends clock messages when the game is paused or
+// resumed. Chat messages of the players are forwarded to spectators as well,
+// while chat messages of spectators are dropped. A spectator that sends a
+// move is disconnected with an error.
+//
+// # Discovery
+//
+// While a game is hosted, the host broadcasts an announcement to UDP port
+// 7655 every second:
+//
+//	{"type":"announce","version":1,"name":"Alice","port":7654,
+//	 "size":15,"wincond":5,"open":true}
+//
+// open tells whether the game still waits for an opponent. Browsers listen on
+// the port, take the host address from the datagram source and forget games
+// that have not been announced for 5 seconds.
+package netplay
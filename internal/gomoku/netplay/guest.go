@@ -0,0 +1,190 @@
+package netplay
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	dialTimeout      = 5 * time.Second
+	reconnectDelay   = 2 * time.Second
+	reconnectTimeout = 2 * time.Minute
+)
+
+func NewGuest(addr, name string) *Peer {
+	p := newPeer(GuestRole, addr)
+	p.name = name
+
+	return p
+}
+
+func NewSpectator(addr, name string) *Peer {
+	p := newPeer(SpectatorRole, addr)
+	p.name = name
+
+	return p
+}
+
+func (p *Peer) startGuest() {
+	conn, welcome, err := p.dial()
+	if err != nil {
+		p.fail(nil, err)
+		return
+	}
+
+	p.attach(conn, welcome)
+}
+
+func (p *Peer) dial() (*Conn, *Message, error) {
+	c, err := net.DialTimeout("tcp", p.addr, dialTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conn := NewConn(c)
+
+	p.mu.Lock()
+	hello := &Message{
+		Type:    HelloMessage,
+		Version: ProtocolVersion,
+		Name:    p.name,
+		Role:    playerRole,
+		Session: p.session,
+		Moves:   p.movesLocked(),
+	}
+	p.mu.Unlock()
+
+	if p.role == SpectatorRole {
+		hello = &Message{
+			Type:    HelloMessage,
+			Version: ProtocolVersion,
+			Name:    p.name,
+			Role:    spectatorRole,
+		}
+	}
+
+	if err := conn.Send(hello); err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	msg, err := conn.Receive()
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+
+	switch {
+	case msg.Type == ErrorMessage:
+		conn.Close()
+		return nil, nil, &remoteError{msg: msg.Error}
+	case msg.Type != WelcomeMessage:
+		conn.Close()
+		return nil, nil, fmt.Errorf("expected %q message, got %q", WelcomeMessage, msg.Type)
+	case msg.Version != ProtocolVersion:
+		conn.Close()
+		return nil, nil, fmt.Errorf("unsupported protocol version %d", msg.Version)
+	case msg.Settings == nil:
+		conn.Close()
+		return nil, nil, fmt.Errorf("welcome message without settings")
+	}
+
+	return conn, msg, nil
+}
+
+func (p *Peer) reconnect() {
+	deadline := time.Now().Add(reconnectTimeout)
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-time.After(reconnectDelay):
+		}
+
+		conn, welcome, err := p.dial()
+		if err != nil {
+			var rerr *remoteError
+
+			if errors.As(err, &rerr) || time.Now().After(deadline) {
+				p.fail(nil, fmt.Errorf("could not reconnect: %v", err))
+				return
+			}
+
+			continue
+		}
+
+		if p.role == SpectatorRole {
+			p.attach(conn, welcome)
+			return
+		}
+
+		p.mu.Lock()
+
+		if p.closed {
+			p.mu.Unlock()
+			conn.Close()
+			return
+		}
+
+		if err := p.syncLocked(welcome.Moves); err != nil {
+			p.mu.Unlock()
+			conn.Close()
+			p.fail(nil, err)
+			return
+		}
+
+		p.conn = conn
+		moves := p.movesLocked()
+
+		p.mu.Unlock()
+
+		p.resumeHandler(moves)
+		p.serve(conn)
+
+		return
+	}
+}
+
+func (p *Peer) attach(conn *Conn, welcome *Message) {
+	replica, err := newReplica(*welcome.Settings)
+	if err != nil {
+		conn.Close()
+		p.fail(nil, err)
+		return
+	}
+
+	p.mu.Lock()
+
+	if p.closed {
+		p.mu.Unlock()
+		conn.Close()
+		return
+	}
+
+	p.session = welcome.Session
+	p.settings = *welcome.Settings
+	p.replica = replica
+
+	if err := p.syncLocked(welcome.Moves); err != nil {
+		p.mu.Unlock()
+		conn.Close()
+		p.fail(nil, err)
+		return
+	}
+
+	p.conn = conn
+	moves := p.movesLocked()
+
+	p.mu.Unlock()
+
+	p.startHandler(*welcome.Settings, moves)
+
+	if welcome.Clock != nil {
+		p.clockHandler(welcome.Clock.Duration(), welcome.Clock.Running)
+	}
+
+	p.serve(conn)
+}
@@ -0,0 +1,290 @@
+package netplay
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+func NewHost(addr string, settings Settings) *Peer {
+	p := newPeer(HostRole, addr)
+	p.settings = settings
+	p.name = settings.Names[0]
+
+	return p
+}
+
+func (p *Peer) Addr() net.Addr {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.listener == nil {
+		return nil
+	}
+
+	return p.listener.Addr()
+}
+
+func (p *Peer) Accept(c net.Conn) error {
+	if p.role != HostRole {
+		return fmt.Errorf("only the host accepts connections")
+	}
+
+	p.handshake(NewConn(c))
+
+	return nil
+}
+
+func (p *Peer) startHost() error {
+	if p.addr == "" {
+		return nil
+	}
+
+	ln, err := net.Listen("tcp", p.addr)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.listener = ln
+	p.mu.Unlock()
+
+	go p.acceptLoop(ln)
+
+	return nil
+}
+
+func (p *Peer) acceptLoop(ln net.Listener) {
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			select {
+			case <-p.done:
+			default:
+				p.fail(nil, err)
+			}
+
+			return
+		}
+
+		go p.handshake(NewConn(c))
+	}
+}
+
+func (p *Peer) handshake(conn *Conn) {
+	msg, err := conn.Receive()
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	if err := checkHello(msg); err != nil {
+		conn.Send(&Message{Type: ErrorMessage, Error: err.Error()})
+		conn.Close()
+		return
+	}
+
+	if msg.Role == spectatorRole {
+		p.handshakeSpectator(conn)
+		return
+	}
+
+	p.mu.Lock()
+
+	first := p.session == ""
+	played := len(p.movesLocked())
+
+	if err := p.admitLocked(msg); err != nil {
+		p.mu.Unlock()
+		conn.Send(&Message{Type: ErrorMessage, Error: err.Error()})
+		conn.Close()
+		return
+	}
+
+	p.conn = conn
+
+	settings := p.settings
+	moves := p.movesLocked()
+	welcome := &Message{
+		Type:     WelcomeMessage,
+		Version:  ProtocolVersion,
+		Session:  p.session,
+		Settings: &settings,
+		Moves:    moves,
+	}
+	result := p.resultLocked()
+
+	if p.replica.State() == game.NotFinished {
+		p.clock.Start()
+	}
+
+	var watched []*Message
+
+	if first {
+		watched = append(watched, &Message{Type: NewGameMessage, Settings: &settings})
+	} else {
+		for i := played; i < len(moves); i++ {
+			watched = append(watched, &Message{Type: MoveMessage, Move: &moves[i]})
+		}
+
+		watched = append(watched, &Message{Type: ClockMessage}, result)
+	}
+
+	watchers := p.watchLocked(watched...)
+
+	p.mu.Unlock()
+
+	conn.Send(welcome)
+
+	if result != nil {
+		conn.Send(result)
+	}
+
+	watchers()
+
+	if first {
+		p.startHandler(settings, moves)
+	} else {
+		p.resumeHandler(moves)
+	}
+
+	p.serve(conn)
+}
+
+func checkHello(msg *Message) error {
+	if msg.Type != HelloMessage {
+		return fmt.Errorf("expected %q message, got %q", HelloMessage, msg.Type)
+	}
+
+	if msg.Version != ProtocolVersion {
+		return fmt.Errorf("unsupported protocol version %d", msg.Version)
+	}
+
+	if msg.Role != "" && msg.Role != playerRole && msg.Role != spectatorRole {
+		return fmt.Errorf("unknown role %q", msg.Role)
+	}
+
+	return nil
+}
+
+func (p *Peer) handshakeSpectator(conn *Conn) {
+	p.mu.Lock()
+
+	if p.closed {
+		p.mu.Unlock()
+		conn.Send(&Message{Type: ErrorMessage, Error: "the game is closed"})
+		conn.Close()
+		return
+	}
+
+	settings := p.settings
+	welcome := &Message{
+		Type:     WelcomeMessage,
+		Version:  ProtocolVersion,
+		Settings: &settings,
+		Moves:    p.movesLocked(),
+		Clock:    p.clockLocked(),
+	}
+
+	p.spectators[conn] = struct{}{}
+
+	p.mu.Unlock()
+
+	conn.Send(welcome)
+
+	p.serveSpectator(conn)
+}
+
+func (p *Peer) serveSpectator(conn *Conn) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.spectators, conn)
+		p.mu.Unlock()
+
+		conn.Close()
+	}()
+
+	go p.keepalive(conn, stop)
+
+	for {
+		msg, err := conn.Receive()
+		if err != nil {
+			return
+		}
+
+		switch msg.Type {
+		case PingMessage, ChatMessage:
+		case ByeMessage:
+			return
+		case MoveMessage, NewGameMessage:
+			conn.Send(&Message{Type: ErrorMessage, Error: "spectators cannot play"})
+			return
+		default:
+			conn.Send(&Message{Type: ErrorMessage, Error: fmt.Sprintf("unexpected %q message", msg.Type)})
+			return
+		}
+	}
+}
+
+func (p *Peer) admitLocked(msg *Message) error {
+	if p.closed {
+		return fmt.Errorf("the game is closed")
+	}
+
+	if p.session != "" {
+		if msg.Session != p.session {
+			return fmt.Errorf("the game is already full")
+		}
+
+		if err := p.syncLocked(msg.Moves); err != nil {
+			return err
+		}
+
+		// The guest may notice a broken connection before the host does and
+		// come back while the old one is still open.
+		if p.conn != nil {
+			p.conn.Close()
+		}
+
+		return nil
+	}
+
+	if p.conn != nil {
+		return fmt.Errorf("the game is already full")
+	}
+
+	name := msg.Name
+	if name == "" {
+		name = "Player 2"
+	}
+
+	if runes := []rune(name); len(runes) > maxNameLength {
+		name = string(runes[:maxNameLength])
+	}
+
+	p.settings.Names[1] = name
+
+	replica, err := newReplica(p.settings)
+	if err != nil {
+		return err
+	}
+
+	p.replica = replica
+	p.session = newSession()
+	p.clock.Reset()
+
+	return nil
+}
+
+func newSession() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,156 @@
+package netplay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+const (
+	ProtocolVersion = 1
+	DefaultPort     = 7654
+
+	pingInterval = 5 * time.Second
+	readTimeout  = 3 * pingInterval
+
+	maxMessageSize = 64 * 1024
+
+	MaxChatLength = 500
+
+	// maxNameLength is the limit the settings put on the names of the players.
+	maxNameLength = 16
+
+	chatRate  = 1
+	chatBurst = 5
+)
+
+type MessageType string
+
+const (
+	HelloMessage   MessageType = "hello"
+	WelcomeMessage MessageType = "welcome"
+	NewGameMessage MessageType = "newgame"
+	MoveMessage    MessageType = "move"
+	ResultMessage  MessageType = "result"
+	ClockMessage   MessageType = "clock"
+	ChatMessage    MessageType = "chat"
+	PingMessage    MessageType = "ping"
+	ByeMessage     MessageType = "bye"
+	ErrorMessage   MessageType = "error"
+)
+
+const (
+	playerRole    = "player"
+	spectatorRole = "spectator"
+)
+
+type Settings struct {
+	Size    uint      `json:"size"`
+	WinCond uint      `json:"wincond"`
+	Names   [2]string `json:"names"`
+}
+
+type Move struct {
+	X   uint `json:"x"`
+	Y   uint `json:"y"`
+	Seq uint `json:"seq"`
+}
+
+type Clock struct {
+	Elapsed int64 `json:"elapsed"`
+	Running bool  `json:"running"`
+}
+
+func (c *Clock) Duration() time.Duration {
+	return time.Duration(c.Elapsed) * time.Millisecond
+}
+
+type Chat struct {
+	From string `json:"from"`
+	Text string `json:"text"`
+	Time int64  `json:"time"`
+}
+
+func (c *Chat) Timestamp() time.Time {
+	return time.Unix(0, c.Time*int64(time.Millisecond))
+}
+
+type Message struct {
+	Type     MessageType    `json:"type"`
+	Version  uint           `json:"version,omitempty"`
+	Name     string         `json:"name,omitempty"`
+	Role     string         `json:"role,omitempty"`
+	Session  string         `json:"session,omitempty"`
+	Settings *Settings      `json:"settings,omitempty"`
+	Moves    []Move         `json:"moves,omitempty"`
+	Move     *Move          `json:"move,omitempty"`
+	State    game.GameState `json:"state,omitempty"`
+	Strike   *game.Strike   `json:"strike,omitempty"`
+	Clock    *Clock         `json:"clock,omitempty"`
+	Chat     *Chat          `json:"chat,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+type Conn struct {
+	conn    net.Conn
+	scanner *bufio.Scanner
+
+	mu sync.Mutex
+}
+
+func NewConn(conn net.Conn) *Conn {
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 4096), maxMessageSize)
+
+	return &Conn{
+		conn:    conn,
+		scanner: scanner,
+	}
+}
+
+func (c *Conn) Send(msg *Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(readTimeout))
+	_, err = c.conn.Write(append(data, '\n'))
+
+	return err
+}
+
+func (c *Conn) Receive() (*Message, error) {
+	c.conn.SetReadDeadline(time.Now().Add(readTimeout))
+
+	if !c.scanner.Scan() {
+		if err := c.scanner.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("connection closed")
+	}
+
+	msg := &Message{}
+	if err := json.Unmarshal(c.scanner.Bytes(), msg); err != nil {
+		return nil, fmt.Errorf("malformed message: %v", err)
+	}
+
+	return msg, nil
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *Conn) RemoteAddr() net.Addr {
+	return c.conn.RemoteAddr()
+}
@@ -0,0 +1,645 @@
+package netplay
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/clock"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+type Role uint
+
+const (
+	HostRole Role = iota
+	GuestRole
+	SpectatorRole
+)
+
+var errBye = errors.New("the other side has left the game")
+
+type remoteError struct {
+	msg string
+}
+
+func (e *remoteError) Error() string {
+	return e.msg
+}
+
+type Peer struct {
+	role Role
+	addr string
+	name string
+
+	mu         sync.Mutex
+	conn       *Conn
+	listener   net.Listener
+	spectators map[*Conn]struct{}
+	session    string
+	settings   Settings
+	replica    *game.Game
+	clock      clock.Stopwatch
+	closed     bool
+	done       chan struct{}
+
+	sendLimiter *limiter
+	recvLimiter *limiter
+
+	startHandler  func(s Settings, moves []Move)
+	moveHandler   func(x, y uint)
+	pauseHandler  func(err error)
+	resumeHandler func(moves []Move)
+	closeHandler  func(err error)
+	clockHandler  func(elapsed time.Duration, running bool)
+	chatHandler   func(c Chat)
+}
+
+func newPeer(role Role, addr string) *Peer {
+	return &Peer{
+		role:       role,
+		addr:       addr,
+		spectators: make(map[*Conn]struct{}),
+		done:       make(chan struct{}),
+
+		sendLimiter: newLimiter(chatRate, chatBurst),
+		recvLimiter: newLimiter(chatRate, chatBurst),
+
+		startHandler:  func(Settings, []Move) {},
+		moveHandler:   func(uint, uint) {},
+		pauseHandler:  func(error) {},
+		resumeHandler: func([]Move) {},
+		closeHandler:  func(error) {},
+		clockHandler:  func(time.Duration, bool) {},
+		chatHandler:   func(Chat) {},
+	}
+}
+
+func (p *Peer) Role() Role {
+	return p.role
+}
+
+func (p *Peer) LocalPlayer() game.PlayerType {
+	if p.role == HostRole {
+		return game.FirstPlayer
+	}
+
+	return game.SecondPlayer
+}
+
+func (p *Peer) Settings() Settings {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.settings
+}
+
+func (p *Peer) HasOpponent() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.session != ""
+}
+
+func (p *Peer) ConnectStart(handler func(s Settings, moves []Move)) {
+	p.startHandler = handler
+}
+
+func (p *Peer) ConnectMove(handler func(x, y uint)) {
+	p.moveHandler = handler
+}
+
+func (p *Peer) ConnectPause(handler func(err error)) {
+	p.pauseHandler = handler
+}
+
+func (p *Peer) ConnectResume(handler func(moves []Move)) {
+	p.resumeHandler = handler
+}
+
+func (p *Peer) ConnectClose(handler func(err error)) {
+	p.closeHandler = handler
+}
+
+func (p *Peer) ConnectClock(handler func(elapsed time.Duration, running bool)) {
+	p.clockHandler = handler
+}
+
+func (p *Peer) ConnectChat(handler func(c Chat)) {
+	p.chatHandler = handler
+}
+
+func (p *Peer) Start() error {
+	if p.role == HostRole {
+		return p.startHost()
+	}
+
+	go p.startGuest()
+
+	return nil
+}
+
+func (p *Peer) SendMove(x, y uint) error {
+	if p.role == SpectatorRole {
+		return fmt.Errorf("spectators cannot make moves")
+	}
+
+	p.mu.Lock()
+
+	if p.replica == nil {
+		p.mu.Unlock()
+		return fmt.Errorf("game is not started")
+	}
+
+	if p.replica.CurrentPlayer() != p.LocalPlayer() {
+		p.mu.Unlock()
+		return fmt.Errorf("it is not your turn")
+	}
+
+	m := Move{X: x, Y: y, Seq: uint(len(p.replica.Moves()))}
+
+	if err := p.applyMove(m); err != nil {
+		p.mu.Unlock()
+		return err
+	}
+
+	conn := p.conn
+	result := p.resultLocked()
+	moveMsg := &Message{Type: MoveMessage, Move: &m}
+	watchers := p.watchLocked(moveMsg, result)
+
+	p.mu.Unlock()
+
+	if conn != nil {
+		conn.Send(moveMsg)
+
+		if result != nil {
+			conn.Send(result)
+		}
+	}
+
+	watchers()
+
+	return nil
+}
+
+func (p *Peer) SendChat(text string) (Chat, error) {
+	if p.role == SpectatorRole {
+		return Chat{}, fmt.Errorf("spectators cannot chat")
+	}
+
+	text = strings.TrimSpace(text)
+
+	switch {
+	case text == "":
+		return Chat{}, fmt.Errorf("the message is empty")
+	case utf8.RuneCountInString(text) > MaxChatLength:
+		return Chat{}, fmt.Errorf("the message is longer than %d characters", MaxChatLength)
+	case !p.sendLimiter.Allow():
+		return Chat{}, fmt.Errorf("you are sending messages too fast")
+	}
+
+	p.mu.Lock()
+
+	c := Chat{
+		From: p.settings.Names[p.LocalPlayer()],
+		Text: text,
+		Time: time.Now().UnixNano() / int64(time.Millisecond),
+	}
+
+	conn := p.conn
+	msg := &Message{Type: ChatMessage, Chat: &c}
+	watchers := p.watchLocked(msg)
+
+	p.mu.Unlock()
+
+	if conn == nil {
+		return Chat{}, fmt.Errorf("not connected")
+	}
+
+	if err := conn.Send(msg); err != nil {
+		return Chat{}, err
+	}
+
+	watchers()
+
+	return c, nil
+}
+
+func (p *Peer) NewGame() error {
+	if p.role == SpectatorRole {
+		return fmt.Errorf("spectators cannot start new games")
+	}
+
+	if p.role == GuestRole {
+		p.mu.Lock()
+		conn := p.conn
+		p.mu.Unlock()
+
+		if conn == nil {
+			return fmt.Errorf("not connected to the host")
+		}
+
+		return conn.Send(&Message{Type: NewGameMessage})
+	}
+
+	p.mu.Lock()
+
+	if p.session == "" {
+		p.mu.Unlock()
+		return fmt.Errorf("no one has joined the game yet")
+	}
+
+	replica, err := newReplica(p.settings)
+	if err != nil {
+		p.mu.Unlock()
+		return err
+	}
+
+	p.replica = replica
+	p.clock.Reset()
+	p.clock.Start()
+
+	settings := p.settings
+	conn := p.conn
+	newGame := &Message{Type: NewGameMessage, Settings: &settings}
+	watchers := p.watchLocked(newGame)
+
+	p.mu.Unlock()
+
+	if conn != nil {
+		conn.Send(newGame)
+	}
+
+	watchers()
+
+	p.startHandler(settings, nil)
+
+	return nil
+}
+
+func (p *Peer) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return
+	}
+
+	p.closed = true
+	close(p.done)
+
+	if p.conn != nil {
+		p.conn.Send(&Message{Type: ByeMessage})
+		p.conn.Close()
+		p.conn = nil
+	}
+
+	for conn := range p.spectators {
+		conn.Send(&Message{Type: ByeMessage})
+		conn.Close()
+	}
+
+	p.spectators = make(map[*Conn]struct{})
+
+	if p.listener != nil {
+		p.listener.Close()
+	}
+}
+
+func newReplica(s Settings) (*game.Game, error) {
+	p1 := game.NewPlayer(s.Names[0])
+	p2 := game.NewPlayer(s.Names[1])
+
+	return game.NewGame(p1, p2, s.Size, s.WinCond)
+}
+
+func (p *Peer) applyMove(m Move) error {
+	if seq := uint(len(p.replica.Moves())); m.Seq != seq {
+		return fmt.Errorf("unexpected move number %d, expected %d", m.Seq, seq)
+	}
+
+	return p.replica.MakeMove(m.X, m.Y)
+}
+
+func (p *Peer) movesLocked() []Move {
+	var moves []Move
+
+	if p.replica == nil {
+		return moves
+	}
+
+	for i, f := range p.replica.Moves() {
+		moves = append(moves, Move{X: f.X, Y: f.Y, Seq: uint(i)})
+	}
+
+	return moves
+}
+
+func (p *Peer) syncLocked(moves []Move) error {
+	local := p.movesLocked()
+
+	for i := 0; i < len(local) && i < len(moves); i++ {
+		if local[i].X != moves[i].X || local[i].Y != moves[i].Y {
+			return fmt.Errorf("move lists have diverged at move %d", i)
+		}
+	}
+
+	for i := len(local); i < len(moves); i++ {
+		if err := p.applyMove(moves[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *Peer) clockLocked() *Clock {
+	return &Clock{
+		Elapsed: int64(p.clock.Elapsed() / time.Millisecond),
+		Running: p.clock.Running(),
+	}
+}
+
+func (p *Peer) watchLocked(msgs ...*Message) func() {
+	if p.role != HostRole || len(p.spectators) == 0 {
+		return func() {}
+	}
+
+	var out []*Message
+
+	for _, msg := range msgs {
+		if msg == nil {
+			continue
+		}
+
+		m := *msg
+		m.Clock = p.clockLocked()
+		out = append(out, &m)
+	}
+
+	conns := make([]*Conn, 0, len(p.spectators))
+	for conn := range p.spectators {
+		conns = append(conns, conn)
+	}
+
+	return func() {
+		for _, conn := range conns {
+			for _, msg := range out {
+				conn.Send(msg)
+			}
+		}
+	}
+}
+
+func (p *Peer) resultLocked() *Message {
+	if p.role != HostRole || p.replica.State() == game.NotFinished {
+		return nil
+	}
+
+	p.clock.Stop()
+
+	msg := &Message{Type: ResultMessage, State: p.replica.State()}
+
+	if s, err := p.replica.Strike(); err == nil && p.replica.State() != game.NobodyWins {
+		msg.Strike = &s
+	}
+
+	return msg
+}
+
+func (p *Peer) serve(conn *Conn) {
+	stop := make(chan struct{})
+	defer close(stop)
+
+	go p.keepalive(conn, stop)
+
+	for {
+		msg, err := conn.Receive()
+		if err != nil {
+			p.lost(conn, err)
+			return
+		}
+
+		if err := p.handle(conn, msg); err != nil {
+			var rerr *remoteError
+
+			if !errors.Is(err, errBye) && !errors.As(err, &rerr) {
+				conn.Send(&Message{Type: ErrorMessage, Error: err.Error()})
+			}
+
+			p.fail(conn, err)
+			return
+		}
+	}
+}
+
+func (p *Peer) keepalive(conn *Conn, stop chan struct{}) {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := conn.Send(&Message{Type: PingMessage}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (p *Peer) handle(conn *Conn, msg *Message) error {
+	switch msg.Type {
+	case PingMessage:
+		return nil
+	case ByeMessage:
+		return errBye
+	case ErrorMessage:
+		return &remoteError{msg: msg.Error}
+	case MoveMessage:
+		return p.handleMove(conn, msg)
+	case NewGameMessage:
+		return p.handleNewGame(msg)
+	case ResultMessage:
+		return p.handleResult(msg)
+	case ClockMessage:
+		return p.handleClock(msg)
+	case ChatMessage:
+		return p.handleChat(msg)
+	}
+
+	return fmt.Errorf("unexpected %q message", msg.Type)
+}
+
+func (p *Peer) handleMove(conn *Conn, msg *Message) error {
+	if msg.Move == nil {
+		return fmt.Errorf("move message without a move")
+	}
+
+	p.mu.Lock()
+
+	if p.replica == nil {
+		p.mu.Unlock()
+		return fmt.Errorf("game is not started")
+	}
+
+	if p.role != SpectatorRole && p.replica.CurrentPlayer() == p.LocalPlayer() {
+		p.mu.Unlock()
+		return fmt.Errorf("move out of turn")
+	}
+
+	if err := p.applyMove(*msg.Move); err != nil {
+		p.mu.Unlock()
+		return fmt.Errorf("invalid move: %v", err)
+	}
+
+	result := p.resultLocked()
+	watchers := p.watchLocked(msg, result)
+
+	p.mu.Unlock()
+
+	p.moveHandler(msg.Move.X, msg.Move.Y)
+
+	if result != nil {
+		conn.Send(result)
+	}
+
+	watchers()
+
+	if msg.Clock != nil {
+		p.clockHandler(msg.Clock.Duration(), msg.Clock.Running)
+	}
+
+	return nil
+}
+
+func (p *Peer) handleNewGame(msg *Message) error {
+	if p.role == HostRole {
+		return p.NewGame()
+	}
+
+	if msg.Settings == nil {
+		return fmt.Errorf("newgame message without settings")
+	}
+
+	replica, err := newReplica(*msg.Settings)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.settings = *msg.Settings
+	p.replica = replica
+	p.mu.Unlock()
+
+	p.startHandler(*msg.Settings, nil)
+
+	if msg.Clock != nil {
+		p.clockHandler(msg.Clock.Duration(), msg.Clock.Running)
+	}
+
+	return nil
+}
+
+func (p *Peer) handleResult(msg *Message) error {
+	if p.role == HostRole {
+		return fmt.Errorf("unexpected %q message", msg.Type)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.replica == nil || p.replica.State() != msg.State {
+		return fmt.Errorf("game result mismatch")
+	}
+
+	return nil
+}
+
+func (p *Peer) handleChat(msg *Message) error {
+	if msg.Chat == nil {
+		return fmt.Errorf("chat message without a chat")
+	}
+
+	c := *msg.Chat
+
+	if p.role != SpectatorRole {
+		c.Text = strings.TrimSpace(c.Text)
+
+		if c.Text == "" || utf8.RuneCountInString(c.Text) > MaxChatLength || !p.recvLimiter.Allow() {
+			return nil
+		}
+
+		p.mu.Lock()
+
+		c.From = p.settings.Names[1]
+		if p.role == GuestRole {
+			c.From = p.settings.Names[0]
+		}
+
+		watchers := p.watchLocked(&Message{Type: ChatMessage, Chat: &c})
+
+		p.mu.Unlock()
+
+		watchers()
+	}
+
+	p.chatHandler(c)
+
+	return nil
+}
+
+func (p *Peer) handleClock(msg *Message) error {
+	if p.role != SpectatorRole || msg.Clock == nil {
+		return fmt.Errorf("unexpected %q message", msg.Type)
+	}
+
+	p.clockHandler(msg.Clock.Duration(), msg.Clock.Running)
+
+	return nil
+}
+
+func (p *Peer) lost(conn *Conn, err error) {
+	p.mu.Lock()
+
+	if p.closed || p.conn != conn {
+		p.mu.Unlock()
+		return
+	}
+
+	p.conn = nil
+	conn.Close()
+
+	p.clock.Stop()
+	watchers := p.watchLocked(&Message{Type: ClockMessage})
+
+	p.mu.Unlock()
+
+	watchers()
+
+	p.pauseHandler(err)
+
+	if p.role != HostRole {
+		go p.reconnect()
+	}
+}
+
+func (p *Peer) fail(conn *Conn, err error) {
+	p.mu.Lock()
+
+	if p.closed || (conn != nil && p.conn != conn) {
+		p.mu.Unlock()
+		return
+	}
+
+	p.mu.Unlock()
+
+	p.Close()
+	p.closeHandler(err)
+}
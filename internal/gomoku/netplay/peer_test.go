@@ -0,0 +1,543 @@
+package netplay
+
+import (
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testTimeout = 10 * time.Second
+
+// events gathers what the handlers of a peer are called with.
+type events struct {
+	start  chan Settings
+	move   chan Move
+	pause  chan error
+	resume chan []Move
+	close  chan error
+	chat   chan Chat
+}
+
+func watch(p *Peer) *events {
+	ev := &events{
+		start:  make(chan Settings, 8),
+		move:   make(chan Move, 8),
+		pause:  make(chan error, 8),
+		resume: make(chan []Move, 8),
+		close:  make(chan error, 8),
+		chat:   make(chan Chat, 8),
+	}
+
+	p.ConnectStart(func(s Settings, moves []Move) { ev.start <- s })
+	p.ConnectMove(func(x, y uint) { ev.move <- Move{X: x, Y: y} })
+	p.ConnectPause(func(err error) { ev.pause <- err })
+	p.ConnectResume(func(moves []Move) { ev.resume <- moves })
+	p.ConnectClose(func(err error) { ev.close <- err })
+	p.ConnectChat(func(c Chat) { ev.chat <- c })
+
+	return ev
+}
+
+func timedOut(t *testing.T, what string) {
+	t.Helper()
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+func receiveSettings(t *testing.T, ch chan Settings, what string) Settings {
+	t.Helper()
+
+	select {
+	case s := <-ch:
+		return s
+	case <-time.After(testTimeout):
+		timedOut(t, what)
+	}
+
+	return Settings{}
+}
+
+func receiveMove(t *testing.T, ch chan Move, what string) Move {
+	t.Helper()
+
+	select {
+	case m := <-ch:
+		return m
+	case <-time.After(testTimeout):
+		timedOut(t, what)
+	}
+
+	return Move{}
+}
+
+func receiveMoves(t *testing.T, ch chan []Move, what string) []Move {
+	t.Helper()
+
+	select {
+	case moves := <-ch:
+		return moves
+	case <-time.After(testTimeout):
+		timedOut(t, what)
+	}
+
+	return nil
+}
+
+func receiveError(t *testing.T, ch chan error, what string) error {
+	t.Helper()
+
+	select {
+	case err := <-ch:
+		return err
+	case <-time.After(testTimeout):
+		timedOut(t, what)
+	}
+
+	return nil
+}
+
+func startHost(t *testing.T) (*Peer, *events) {
+	t.Helper()
+
+	host := NewHost("127.0.0.1:0", Settings{Size: 15, WinCond: 5, Names: [2]string{"Alice", ""}})
+	ev := watch(host)
+
+	if err := host.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(host.Close)
+
+	return host, ev
+}
+
+func startGame(t *testing.T) (host, guest *Peer, hev, gev *events) {
+	t.Helper()
+
+	host, hev = startHost(t)
+
+	guest = NewGuest(host.Addr().String(), "Bob")
+	gev = watch(guest)
+
+	if err := guest.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(guest.Close)
+
+	receiveSettings(t, hev.start, "the host to start")
+	receiveSettings(t, gev.start, "the guest to start")
+
+	return host, guest, hev, gev
+}
+
+// dialRaw connects to the host without a peer, for sending messages a peer
+// would not send.
+func dialRaw(t *testing.T, addr string) *Conn {
+	t.Helper()
+
+	c, err := net.DialTimeout("tcp", addr, testTimeout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn := NewConn(c)
+	t.Cleanup(func() { conn.Close() })
+
+	return conn
+}
+
+// receiveType skips pings until a message of another type arrives.
+func receiveType(t *testing.T, conn *Conn) *Message {
+	t.Helper()
+
+	for {
+		msg, err := conn.Receive()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if msg.Type != PingMessage {
+			return msg
+		}
+	}
+}
+
+func TestHandshake(t *testing.T) {
+	host, hev := startHost(t)
+
+	guest := NewGuest(host.Addr().String(), "Bob")
+	gev := watch(guest)
+
+	if err := guest.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer guest.Close()
+
+	want := Settings{Size: 15, WinCond: 5, Names: [2]string{"Alice", "Bob"}}
+
+	if s := receiveSettings(t, hev.start, "the host to start"); s != want {
+		t.Errorf("host settings are %+v, want %+v", s, want)
+	}
+
+	if s := receiveSettings(t, gev.start, "the guest to start"); s != want {
+		t.Errorf("guest settings are %+v, want %+v", s, want)
+	}
+
+	if !host.HasOpponent() || !guest.HasOpponent() {
+		t.Error("the peers do not know about each other")
+	}
+
+	if guest.Settings() != want {
+		t.Errorf("guest settings are %+v, want %+v", guest.Settings(), want)
+	}
+}
+
+func TestLongName(t *testing.T) {
+	host, hev := startHost(t)
+
+	guest := NewGuest(host.Addr().String(), strings.Repeat("Бо", 10))
+	watch(guest)
+
+	if err := guest.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer guest.Close()
+
+	want := strings.Repeat("Бо", 8)
+
+	if s := receiveSettings(t, hev.start, "the host to start"); s.Names[1] != want {
+		t.Errorf("the name of the guest is %q, want %q", s.Names[1], want)
+	}
+}
+
+func TestMoves(t *testing.T) {
+	host, guest, hev, gev := startGame(t)
+
+	if err := guest.SendMove(7, 7); err == nil {
+		t.Error("the guest moved out of turn")
+	}
+
+	if err := host.SendMove(7, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	if m := receiveMove(t, gev.move, "the move of the host"); m.X != 7 || m.Y != 7 {
+		t.Errorf("the guest got move %d,%d, want 7,7", m.X, m.Y)
+	}
+
+	if err := guest.SendMove(8, 8); err != nil {
+		t.Fatal(err)
+	}
+
+	if m := receiveMove(t, hev.move, "the move of the guest"); m.X != 8 || m.Y != 8 {
+		t.Errorf("the host got move %d,%d, want 8,8", m.X, m.Y)
+	}
+
+	if err := host.SendMove(8, 8); err == nil {
+		t.Error("the host played on a taken cell")
+	}
+}
+
+func TestInvalidMove(t *testing.T) {
+	tests := []struct {
+		name string
+		move Move
+		err  string
+	}{
+		{"taken", Move{X: 7, Y: 7, Seq: 1}, "the field is already taken"},
+		{"outside", Move{X: 15, Y: 0, Seq: 1}, "out of board bounds"},
+		{"sequence", Move{X: 8, Y: 8, Seq: 5}, "unexpected move number 5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, hev := startHost(t)
+			conn := dialRaw(t, host.Addr().String())
+
+			conn.Send(&Message{Type: HelloMessage, Version: ProtocolVersion, Name: "Bob", Role: playerRole})
+
+			if msg := receiveType(t, conn); msg.Type != WelcomeMessage {
+				t.Fatalf("got %q message, want %q", msg.Type, WelcomeMessage)
+			}
+
+			receiveSettings(t, hev.start, "the host to start")
+
+			if err := host.SendMove(7, 7); err != nil {
+				t.Fatal(err)
+			}
+
+			if msg := receiveType(t, conn); msg.Type != MoveMessage {
+				t.Fatalf("got %q message, want %q", msg.Type, MoveMessage)
+			}
+
+			conn.Send(&Message{Type: MoveMessage, Move: &tt.move})
+
+			msg := receiveType(t, conn)
+			if msg.Type != ErrorMessage || !strings.Contains(msg.Error, tt.err) {
+				t.Errorf("got %q message %q, want an error with %q", msg.Type, msg.Error, tt.err)
+			}
+
+			if err := receiveError(t, hev.close, "the host to close"); err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("the host closed with %v, want an error with %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestVersionMismatch(t *testing.T) {
+	t.Run("hello", func(t *testing.T) {
+		host, _ := startHost(t)
+		conn := dialRaw(t, host.Addr().String())
+
+		conn.Send(&Message{Type: HelloMessage, Version: ProtocolVersion + 1, Name: "Bob"})
+
+		msg := receiveType(t, conn)
+		if msg.Type != ErrorMessage || !strings.Contains(msg.Error, "unsupported protocol version") {
+			t.Errorf("got %q message %q, want an unsupported version error", msg.Type, msg.Error)
+		}
+
+		if host.HasOpponent() {
+			t.Error("the host admitted the guest")
+		}
+	})
+
+	t.Run("welcome", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer ln.Close()
+
+		go func() {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+
+			conn := NewConn(c)
+			defer conn.Close()
+
+			if _, err := conn.Receive(); err != nil {
+				return
+			}
+
+			conn.Send(&Message{
+				Type:     WelcomeMessage,
+				Version:  ProtocolVersion + 1,
+				Settings: &Settings{Size: 15, WinCond: 5},
+			})
+		}()
+
+		guest := NewGuest(ln.Addr().String(), "Bob")
+		gev := watch(guest)
+
+		if err := guest.Start(); err != nil {
+			t.Fatal(err)
+		}
+
+		defer guest.Close()
+
+		err = receiveError(t, gev.close, "the guest to close")
+		if err == nil || !strings.Contains(err.Error(), "unsupported protocol version") {
+			t.Errorf("the guest closed with %v, want an unsupported version error", err)
+		}
+	})
+}
+
+func TestReconnect(t *testing.T) {
+	host, guest, hev, gev := startGame(t)
+
+	if err := host.SendMove(7, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	receiveMove(t, gev.move, "the move of the host")
+
+	// Drop the connection without saying goodbye.
+	guest.mu.Lock()
+	guest.conn.Close()
+	guest.mu.Unlock()
+
+	receiveError(t, gev.pause, "the guest to pause")
+	receiveError(t, hev.pause, "the host to pause")
+
+	if err := host.SendMove(8, 8); err == nil {
+		t.Error("the host moved out of turn")
+	}
+
+	hmoves := receiveMoves(t, hev.resume, "the host to resume")
+	gmoves := receiveMoves(t, gev.resume, "the guest to resume")
+
+	for _, moves := range [][]Move{hmoves, gmoves} {
+		if len(moves) != 1 || moves[0].X != 7 || moves[0].Y != 7 {
+			t.Errorf("resumed with moves %v, want 7,7", moves)
+		}
+	}
+
+	if err := guest.SendMove(8, 8); err != nil {
+		t.Fatal(err)
+	}
+
+	if m := receiveMove(t, hev.move, "the move of the guest"); m.X != 8 || m.Y != 8 {
+		t.Errorf("the host got move %d,%d, want 8,8", m.X, m.Y)
+	}
+
+	select {
+	case err := <-hev.close:
+		t.Errorf("the host closed: %v", err)
+	case err := <-gev.close:
+		t.Errorf("the guest closed: %v", err)
+	default:
+	}
+}
+
+// proxy forwards connections to the host.
+type proxy struct {
+	ln   net.Listener
+	addr string
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func startProxy(t *testing.T, addr string) *proxy {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	px := &proxy{ln: ln, addr: addr}
+	t.Cleanup(px.close)
+
+	go px.serve()
+
+	return px
+}
+
+func (px *proxy) serve() {
+	for {
+		down, err := px.ln.Accept()
+		if err != nil {
+			return
+		}
+
+		up, err := net.Dial("tcp", px.addr)
+		if err != nil {
+			down.Close()
+			continue
+		}
+
+		px.mu.Lock()
+		px.conns = append(px.conns, down, up)
+		px.mu.Unlock()
+
+		go io.Copy(up, down)
+		go io.Copy(down, up)
+	}
+}
+
+// cut closes the connections on the side of the guest only. The connections
+// to the host stay open and silent, the way a dead link looks to it until
+// its read timeout.
+func (px *proxy) cut() {
+	px.mu.Lock()
+	defer px.mu.Unlock()
+
+	for i := 0; i < len(px.conns); i += 2 {
+		px.conns[i].Close()
+	}
+}
+
+func (px *proxy) close() {
+	px.ln.Close()
+
+	px.mu.Lock()
+	defer px.mu.Unlock()
+
+	for _, c := range px.conns {
+		c.Close()
+	}
+}
+
+func TestReconnectBeforeHostNotices(t *testing.T) {
+	host, hev := startHost(t)
+	px := startProxy(t, host.Addr().String())
+
+	guest := NewGuest(px.ln.Addr().String(), "Bob")
+	gev := watch(guest)
+
+	if err := guest.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer guest.Close()
+
+	receiveSettings(t, hev.start, "the host to start")
+	receiveSettings(t, gev.start, "the guest to start")
+
+	if err := host.SendMove(7, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	receiveMove(t, gev.move, "the move of the host")
+
+	px.mu.Lock()
+	stale := len(px.conns) - 1
+	px.mu.Unlock()
+
+	px.cut()
+
+	receiveError(t, gev.pause, "the guest to pause")
+
+	// The host takes the guest back on the old session and drops the old
+	// connection without pausing.
+	hmoves := receiveMoves(t, hev.resume, "the host to resume")
+	gmoves := receiveMoves(t, gev.resume, "the guest to resume")
+
+	for _, moves := range [][]Move{hmoves, gmoves} {
+		if len(moves) != 1 || moves[0].X != 7 || moves[0].Y != 7 {
+			t.Errorf("resumed with moves %v, want 7,7", moves)
+		}
+	}
+
+	select {
+	case err := <-hev.pause:
+		t.Errorf("the host paused: %v", err)
+	default:
+	}
+
+	if err := guest.SendMove(8, 8); err != nil {
+		t.Fatal(err)
+	}
+
+	if m := receiveMove(t, hev.move, "the move of the guest"); m.X != 8 || m.Y != 8 {
+		t.Errorf("the host got move %d,%d, want 8,8", m.X, m.Y)
+	}
+
+	// The host has closed the old connection on its side.
+	px.mu.Lock()
+	up := px.conns[stale]
+	px.mu.Unlock()
+
+	up.SetReadDeadline(time.Now().Add(testTimeout))
+
+	if _, err := io.Copy(io.Discard, up); err != nil {
+		t.Errorf("the old connection to the host is still open: %v", err)
+	}
+
+	select {
+	case err := <-hev.close:
+		t.Errorf("the host closed: %v", err)
+	case err := <-gev.close:
+		t.Errorf("the guest closed: %v", err)
+	default:
+	}
+}
@@ -0,0 +1,57 @@
+package netplay
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	type call struct {
+		at    time.Duration
+		allow bool
+	}
+
+	burst := func(at time.Duration, n int, allow bool) []call {
+		calls := make([]call, n)
+		for i := range calls {
+			calls[i] = call{at, allow}
+		}
+
+		return calls
+	}
+
+	join := func(parts ...[]call) []call {
+		var calls []call
+		for _, p := range parts {
+			calls = append(calls, p...)
+		}
+
+		return calls
+	}
+
+	tests := []struct {
+		name  string
+		rate  float64
+		burst int
+		calls []call
+	}{
+		{"burst", 1, 5, join(burst(0, 5, true), burst(0, 1, false))},
+		{"refill", 1, 5, join(burst(0, 5, true), []call{{time.Second, true}, {time.Second, false}})},
+		{"partial token", 1, 5, join(burst(0, 5, true), []call{{500 * time.Millisecond, false}, {time.Second, true}})},
+		{"idle is capped", 1, 5, join(burst(0, 5, true), burst(time.Minute, 5, true), burst(time.Minute, 1, false))},
+		{"fast rate", 2, 1, []call{{0, true}, {0, false}, {500 * time.Millisecond, true}, {900 * time.Millisecond, false}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := newLimiter(tt.rate, tt.burst)
+			start := l.last
+
+			for i, c := range tt.calls {
+				if got := l.allowAt(start.Add(c.at)); got != c.allow {
+					t.Fatalf("call %d at %v: allowed %v, want %v", i+1, c.at, got, c.allow)
+				}
+			}
+		})
+	}
+}
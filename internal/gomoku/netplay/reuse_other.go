@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package netplay
+
+import "syscall"
+
+// reuseAddr does nothing where sharing the discovery port is not supported,
+// a second browser on the same host then fails to start.
+func reuseAddr(network, address string, c syscall.RawConn) error {
+	return nil
+}
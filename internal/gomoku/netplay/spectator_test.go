@@ -0,0 +1,100 @@
+package netplay
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSpectator(t *testing.T) {
+	host, guest, hev, gev := startGame(t)
+
+	if err := host.SendMove(7, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	receiveMove(t, gev.move, "the move of the host")
+
+	if err := guest.SendMove(8, 8); err != nil {
+		t.Fatal(err)
+	}
+
+	receiveMove(t, hev.move, "the move of the guest")
+
+	spectator := NewSpectator(host.Addr().String(), "Carol")
+	sev := watch(spectator)
+
+	history := make(chan []Move, 1)
+	spectator.ConnectStart(func(s Settings, moves []Move) { history <- moves })
+
+	if err := spectator.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	defer spectator.Close()
+
+	// The welcome brings the moves played so far.
+	moves := receiveMoves(t, history, "the spectator to start")
+	if len(moves) != 2 || moves[0] != (Move{X: 7, Y: 7, Seq: 0}) || moves[1] != (Move{X: 8, Y: 8, Seq: 1}) {
+		t.Fatalf("the spectator started with moves %v, want 7,7 and 8,8", moves)
+	}
+
+	// The moves of both players are forwarded.
+	if err := host.SendMove(6, 6); err != nil {
+		t.Fatal(err)
+	}
+
+	if m := receiveMove(t, sev.move, "the move of the host"); m.X != 6 || m.Y != 6 {
+		t.Errorf("the spectator got move %d,%d, want 6,6", m.X, m.Y)
+	}
+
+	receiveMove(t, gev.move, "the move of the host")
+
+	if err := guest.SendMove(9, 9); err != nil {
+		t.Fatal(err)
+	}
+
+	if m := receiveMove(t, sev.move, "the move of the guest"); m.X != 9 || m.Y != 9 {
+		t.Errorf("the spectator got move %d,%d, want 9,9", m.X, m.Y)
+	}
+
+	if err := spectator.SendMove(5, 5); err == nil {
+		t.Error("the spectator made a move")
+	}
+
+	if err := spectator.NewGame(); err == nil {
+		t.Error("the spectator started a new game")
+	}
+}
+
+func TestSpectatorCannotPlay(t *testing.T) {
+	host, _, _, _ := startGame(t)
+
+	if err := host.SendMove(7, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	conn := dialRaw(t, host.Addr().String())
+	conn.Send(&Message{Type: HelloMessage, Version: ProtocolVersion, Name: "Carol", Role: spectatorRole})
+
+	welcome := receiveType(t, conn)
+	if welcome.Type != WelcomeMessage || len(welcome.Moves) != 1 || welcome.Session != "" {
+		t.Fatalf("got %q message with moves %v and session %q, want a welcome with 7,7 and no session",
+			welcome.Type, welcome.Moves, welcome.Session)
+	}
+
+	// A spectator sending the move of the guest is turned away.
+	conn.Send(&Message{Type: MoveMessage, Move: &Move{X: 8, Y: 8, Seq: 1}})
+
+	msg := receiveType(t, conn)
+	if msg.Type != ErrorMessage || !strings.Contains(msg.Error, "spectators cannot play") {
+		t.Errorf("got %q message %q, want an error", msg.Type, msg.Error)
+	}
+
+	host.mu.Lock()
+	n := len(host.replica.Moves())
+	host.mu.Unlock()
+
+	if n != 1 {
+		t.Errorf("the host has %d moves, want 1", n)
+	}
+}
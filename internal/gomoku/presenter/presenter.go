@@ -0,0 +1,714 @@
+package presenter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/clock"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/record"
+)
+
+type View interface {
+	SetStatus(text string)
+	SetButtonLabel(label string)
+
+	InitBoard(size uint)
+	SetInteractive(enabled bool)
+	DrawStone(x, y uint, ft game.FieldType)
+	DrawStrike(s game.Strike)
+
+	StartClock()
+	StopClock()
+	ResetClock()
+	SetClock(elapsed time.Duration)
+
+	ShowPlayerClocks(first, second time.Duration)
+	HidePlayerClocks()
+
+	AppendChat(t time.Time, from, text string)
+
+	ShowHints(hints []Hint)
+	ClearHints()
+}
+
+// Hint is a move suggested to the player to move, labelled with its score.
+type Hint struct {
+	X, Y  uint
+	Label string
+}
+
+type Presenter struct {
+	view      View
+	newGame   func() (*game.Game, error)
+	gameLogic *game.Game
+
+	remote      bool
+	spectator   bool
+	localPlayer game.PlayerType
+	sendMove    func(x, y uint) error
+	paused      bool
+	previewing  bool
+
+	// computer tells which players are played by the computer, whose moves
+	// come through Play.
+	computer [2]bool
+
+	// browsing tells that the board shows the position after the first
+	// shown moves instead of the game as it is.
+	browsing bool
+	shown    int
+
+	// fixed is the number of moves at the start of the game that cannot be
+	// taken back, like the stones of a puzzle.
+	fixed int
+
+	chat  []record.ChatMessage
+	hints [2]uint
+
+	// The clocks of the players of a timed local game, nil when the game is
+	// not timed.
+	timeControl clock.TimeControl
+	clocks      *clock.ChessClock
+
+	changedHandler func()
+}
+
+func New(view View, newGame func() (*game.Game, error)) *Presenter {
+	return &Presenter{
+		view:    view,
+		newGame: newGame,
+	}
+}
+
+func (p *Presenter) Game() *game.Game {
+	return p.gameLogic
+}
+
+// ConnectPositionChanged sets the handler called whenever the position shown
+// on the board changes: on moves, new games and history navigation.
+func (p *Presenter) ConnectPositionChanged(handler func()) {
+	p.changedHandler = handler
+}
+
+func (p *Presenter) StartGame() error {
+	g, err := p.newGame()
+	if err != nil {
+		return err
+	}
+
+	p.StartGameWith(g)
+
+	return nil
+}
+
+func (p *Presenter) StartGameWith(g *game.Game) {
+	p.gameLogic = g
+	p.paused = false
+	p.fixed = 0
+	p.chat = nil
+	p.hints = [2]uint{}
+	p.clocks = nil
+
+	if p.previewing || p.browsing {
+		p.previewing = false
+		p.browsing = false
+		p.updateInteractive()
+	}
+
+	defer p.positionChanged()
+
+	p.view.StopClock()
+	p.view.ResetClock()
+
+	p.view.InitBoard(g.Size())
+	p.view.SetButtonLabel("Restart Game")
+
+	for _, f := range g.Moves() {
+		p.view.DrawStone(f.X, f.Y, f.Ft)
+	}
+
+	if p.timeControl.Timed() && !p.remote {
+		p.clocks = clock.NewChessClock(p.timeControl)
+	}
+
+	p.showPlayerClocks()
+
+	if g.State() != game.NotFinished {
+		p.showResult()
+		return
+	}
+
+	p.setTurnStatus()
+	p.view.StartClock()
+	p.startPlayerClock()
+}
+
+// SetFixedMoves makes the first n moves of the current game impossible to
+// take back. A new game starts without fixed moves.
+func (p *Presenter) SetFixedMoves(n int) {
+	p.fixed = n
+}
+
+// SetTimeControl sets the time control of the next local games.
+func (p *Presenter) SetTimeControl(tc clock.TimeControl) {
+	p.timeControl = tc
+}
+
+// TimeLeft returns the time left to the player and the increment they get
+// per move, or false if the game is not timed.
+func (p *Presenter) TimeLeft(pt game.PlayerType) (remaining, increment time.Duration, ok bool) {
+	if p.clocks == nil {
+		return 0, 0, false
+	}
+
+	return p.clocks.Remaining(pt), p.clocks.Control().Increment, true
+}
+
+// CheckTime updates the clocks of the players and ends the game if the
+// player to move has run out of time. It is meant to be called a few times
+// a second.
+func (p *Presenter) CheckTime() {
+	if p.clocks == nil || !p.clocks.Running() {
+		return
+	}
+
+	p.showPlayerClocks()
+
+	pt := p.gameLogic.CurrentPlayer()
+	if !p.clocks.Expired(pt) {
+		return
+	}
+
+	if err := p.gameLogic.LoseOnTime(pt); err != nil {
+		return
+	}
+
+	defer p.positionChanged()
+
+	if p.browsing {
+		p.stopBrowsing()
+	}
+
+	p.showResult()
+}
+
+func (p *Presenter) startPlayerClock() {
+	if p.clocks != nil && !p.paused && p.gameLogic.State() == game.NotFinished {
+		p.clocks.Start(p.gameLogic.CurrentPlayer())
+	}
+}
+
+func (p *Presenter) showPlayerClocks() {
+	if p.clocks == nil {
+		p.view.HidePlayerClocks()
+		return
+	}
+
+	p.view.ShowPlayerClocks(p.clocks.Remaining(game.FirstPlayer), p.clocks.Remaining(game.SecondPlayer))
+}
+
+func (p *Presenter) SetRemote(localPlayer game.PlayerType, sendMove func(x, y uint) error) {
+	p.remote = true
+	p.localPlayer = localPlayer
+	p.sendMove = sendMove
+}
+
+func (p *Presenter) SetSpectator() {
+	p.remote = true
+	p.spectator = true
+	p.sendMove = nil
+	p.updateInteractive()
+}
+
+func (p *Presenter) ClearRemote() {
+	p.remote = false
+	p.spectator = false
+	p.sendMove = nil
+	p.updateInteractive()
+	p.Resume()
+}
+
+func (p *Presenter) SetComputer(pt game.PlayerType, computer bool) {
+	p.computer[pt] = computer
+
+	if p.gameLogic != nil && p.gameLogic.State() == game.NotFinished && !p.paused && !p.browsing && !p.previewing {
+		p.setTurnStatus()
+	}
+}
+
+func (p *Presenter) IsComputer(pt game.PlayerType) bool {
+	return !p.remote && p.computer[pt]
+}
+
+func (p *Presenter) SyncClock(elapsed time.Duration, running bool) {
+	p.view.StopClock()
+	p.view.SetClock(elapsed)
+
+	if running && !p.paused {
+		p.view.StartClock()
+	}
+}
+
+func (p *Presenter) Pause(status string) {
+	if p.paused {
+		return
+	}
+
+	p.paused = true
+	p.view.StopClock()
+	p.view.SetStatus(status)
+
+	if p.clocks != nil {
+		p.clocks.Stop()
+	}
+}
+
+func (p *Presenter) Resume() {
+	if !p.paused {
+		return
+	}
+
+	p.paused = false
+
+	if p.gameLogic != nil && p.gameLogic.State() == game.NotFinished {
+		if !p.browsing {
+			p.setTurnStatus()
+		}
+
+		p.view.StartClock()
+		p.startPlayerClock()
+	}
+}
+
+func (p *Presenter) AddChat(t time.Time, from, text string) {
+	p.chat = append(p.chat, record.ChatMessage{Time: t, From: from, Text: text})
+	p.view.AppendChat(t, from, text)
+}
+
+func (p *Presenter) Record() (*record.Record, error) {
+	if p.gameLogic == nil {
+		return nil, fmt.Errorf("game is not started")
+	}
+
+	r := record.New(p.gameLogic)
+	r.Chat = append(r.Chat, p.chat...)
+	r.Hints = p.hints
+
+	return r, nil
+}
+
+func (p *Presenter) Click(x, y uint) error {
+	if p.gameLogic == nil {
+		return fmt.Errorf("game is not started")
+	}
+
+	if p.paused {
+		return fmt.Errorf("the game is paused")
+	}
+
+	if p.previewing {
+		return fmt.Errorf("wait until the line is shown")
+	}
+
+	if p.browsing {
+		return fmt.Errorf("go to the last move to play")
+	}
+
+	if p.spectator {
+		return fmt.Errorf("spectators cannot make moves")
+	}
+
+	if p.IsComputer(p.gameLogic.CurrentPlayer()) {
+		return fmt.Errorf("wait for the computer to move")
+	}
+
+	if p.remote {
+		if p.gameLogic.CurrentPlayer() != p.localPlayer {
+			return fmt.Errorf("it is not your turn")
+		}
+
+		if err := p.sendMove(x, y); err != nil {
+			return err
+		}
+	}
+
+	return p.Play(x, y)
+}
+
+func (p *Presenter) Play(x, y uint) error {
+	if p.gameLogic == nil {
+		return fmt.Errorf("game is not started")
+	}
+
+	ft := game.FieldType(p.gameLogic.CurrentPlayer()) + 1
+
+	if err := p.gameLogic.MakeMove(x, y); err != nil {
+		return err
+	}
+
+	defer p.positionChanged()
+
+	if p.clocks != nil {
+		p.clocks.Press()
+		p.showPlayerClocks()
+	}
+
+	if p.browsing {
+		p.stopBrowsing()
+	} else {
+		p.view.ClearHints()
+		p.view.DrawStone(x, y, ft)
+	}
+
+	if p.gameLogic.State() != game.NotFinished {
+		p.showResult()
+		return nil
+	}
+
+	p.setTurnStatus()
+
+	return nil
+}
+
+func (p *Presenter) Undo() error {
+	if p.gameLogic == nil {
+		return fmt.Errorf("game is not started")
+	}
+
+	if p.remote {
+		return fmt.Errorf("moves cannot be undone in a network game")
+	}
+
+	if len(p.gameLogic.Moves()) <= p.fixed {
+		return fmt.Errorf("there are no moves to undo")
+	}
+
+	finished := p.gameLogic.State() != game.NotFinished
+
+	if _, err := p.gameLogic.Undo(); err != nil {
+		return err
+	}
+
+	// Against the computer the move it answered with is taken back too, so
+	// that it is the human's turn again.
+	for len(p.gameLogic.Moves()) > p.fixed &&
+		p.IsComputer(p.gameLogic.CurrentPlayer()) && !p.IsComputer((p.gameLogic.CurrentPlayer()+1)%2) {
+		if _, err := p.gameLogic.Undo(); err != nil {
+			break
+		}
+	}
+
+	defer p.positionChanged()
+
+	p.browsing = false
+	p.updateInteractive()
+	p.redrawBoard()
+	p.view.SetButtonLabel("Restart Game")
+	p.setTurnStatus()
+
+	if finished {
+		p.view.StartClock()
+	}
+
+	// The time spent on the moves taken back is not given back.
+	p.startPlayerClock()
+
+	return nil
+}
+
+// ShowHints shows the hints on the board and counts them as used by the
+// player to move. The hints stay until the next move.
+func (p *Presenter) ShowHints(hints []Hint) error {
+	if p.gameLogic == nil {
+		return fmt.Errorf("game is not started")
+	}
+
+	if p.gameLogic.State() != game.NotFinished {
+		return fmt.Errorf("the game is over")
+	}
+
+	if p.remote {
+		return fmt.Errorf("hints are not available in network games")
+	}
+
+	if p.previewing {
+		return fmt.Errorf("wait until the line is shown")
+	}
+
+	if p.browsing {
+		return fmt.Errorf("go to the last move to see hints")
+	}
+
+	p.hints[p.gameLogic.CurrentPlayer()]++
+	p.view.ShowHints(hints)
+
+	return nil
+}
+
+// HintsUsed returns the number of hints each player has used in the game.
+func (p *Presenter) HintsUsed() [2]uint {
+	return p.hints
+}
+
+// SetHintsUsed restores the hint counts of a game started with
+// StartGameWith, for example one loaded from a record.
+func (p *Presenter) SetHintsUsed(hints [2]uint) {
+	p.hints = hints
+}
+
+// StartPreview makes the board read-only, so that stones that are not part
+// of the game can be shown on it with PreviewStone.
+func (p *Presenter) StartPreview() {
+	if p.browsing {
+		p.stopBrowsing()
+		p.positionChanged()
+	}
+
+	p.previewing = true
+	p.view.ClearHints()
+	p.updateInteractive()
+}
+
+func (p *Presenter) PreviewStone(x, y uint, pt game.PlayerType) {
+	if p.previewing {
+		p.view.DrawStone(x, y, game.FieldType(pt)+1)
+	}
+}
+
+// StopPreview brings the board back to the position of the game.
+func (p *Presenter) StopPreview() {
+	if !p.previewing {
+		return
+	}
+
+	p.previewing = false
+	p.updateInteractive()
+
+	if p.gameLogic == nil {
+		return
+	}
+
+	p.redrawBoard()
+	p.showState()
+}
+
+// ShowMove shows the position after the first n moves of the game without
+// changing the game. The board is read-only until the last move is shown
+// again.
+func (p *Presenter) ShowMove(n int) error {
+	if p.gameLogic == nil {
+		return fmt.Errorf("game is not started")
+	}
+
+	if p.previewing {
+		return fmt.Errorf("wait until the line is shown")
+	}
+
+	total := len(p.gameLogic.Moves())
+
+	if n < 0 || n > total {
+		return fmt.Errorf("there is no move %d", n)
+	}
+
+	if n == p.ShownMoves() {
+		return nil
+	}
+
+	defer p.positionChanged()
+
+	if n == total {
+		p.stopBrowsing()
+		p.showState()
+
+		return nil
+	}
+
+	p.browsing = true
+	p.shown = n
+	p.updateInteractive()
+	p.redrawBoard()
+
+	p.view.SetStatus(fmt.Sprintf("Move %d of %d", n, total))
+
+	return nil
+}
+
+func (p *Presenter) Back() error {
+	if p.ShownMoves() == 0 {
+		return fmt.Errorf("this is the start of the game")
+	}
+
+	return p.ShowMove(p.ShownMoves() - 1)
+}
+
+func (p *Presenter) Forward() error {
+	if !p.browsing {
+		return fmt.Errorf("this is the last move")
+	}
+
+	return p.ShowMove(p.shown + 1)
+}
+
+// ShownMoves returns the number of moves of the position shown on the board.
+func (p *Presenter) ShownMoves() int {
+	if p.gameLogic == nil {
+		return 0
+	}
+
+	if p.browsing {
+		return p.shown
+	}
+
+	return len(p.gameLogic.Moves())
+}
+
+// Position returns a copy of the game as it was in the position shown on
+// the board.
+func (p *Presenter) Position() *game.Game {
+	if p.gameLogic == nil {
+		return nil
+	}
+
+	g := p.gameLogic.Clone()
+
+	for len(g.Moves()) > p.ShownMoves() {
+		g.Undo()
+	}
+
+	return g
+}
+
+func (p *Presenter) stopBrowsing() {
+	p.browsing = false
+	p.updateInteractive()
+	p.redrawBoard()
+}
+
+func (p *Presenter) positionChanged() {
+	if p.changedHandler != nil {
+		p.changedHandler()
+	}
+}
+
+func (p *Presenter) updateInteractive() {
+	p.view.SetInteractive(!p.spectator && !p.previewing && !p.browsing)
+}
+
+func (p *Presenter) shownFields() []game.Field {
+	moves := p.gameLogic.Moves()
+
+	if p.browsing {
+		moves = moves[:p.shown]
+	}
+
+	return moves
+}
+
+func (p *Presenter) redrawBoard() {
+	p.view.ClearHints()
+	p.view.InitBoard(p.gameLogic.Size())
+
+	for _, f := range p.shownFields() {
+		p.view.DrawStone(f.X, f.Y, f.Ft)
+	}
+}
+
+func (p *Presenter) Resign() error {
+	if p.gameLogic == nil {
+		return fmt.Errorf("game is not started")
+	}
+
+	if p.remote {
+		return fmt.Errorf("resigning is not supported in network games")
+	}
+
+	if err := p.gameLogic.Resign(p.gameLogic.CurrentPlayer()); err != nil {
+		return err
+	}
+
+	defer p.positionChanged()
+
+	if p.browsing {
+		p.stopBrowsing()
+	}
+
+	p.showResult()
+
+	return nil
+}
+
+func (p *Presenter) Redraw() {
+	if p.gameLogic == nil {
+		return
+	}
+
+	for _, f := range p.shownFields() {
+		p.view.DrawStone(f.X, f.Y, f.Ft)
+	}
+
+	if p.browsing {
+		return
+	}
+
+	if p.gameLogic.State() != game.NotFinished && p.gameLogic.State() != game.NobodyWins {
+		if s, err := p.gameLogic.Strike(); err == nil {
+			p.view.DrawStrike(s)
+		}
+	}
+}
+
+// showState shows the result of a finished game or whose turn it is.
+func (p *Presenter) showState() {
+	if p.gameLogic.State() != game.NotFinished {
+		p.showResult()
+	} else if !p.paused {
+		p.setTurnStatus()
+	}
+}
+
+func (p *Presenter) showResult() {
+	p.view.ClearHints()
+	p.view.StopClock()
+
+	if p.clocks != nil {
+		p.clocks.Stop()
+		p.showPlayerClocks()
+	}
+
+	p.view.SetButtonLabel("Start Game")
+
+	switch state := p.gameLogic.State(); state {
+	case game.NobodyWins:
+		p.view.SetStatus("Draw")
+	case game.FirstPlayerWin, game.SecondPlayerWin:
+		playerName := p.gameLogic.Player(game.PlayerType(state - 1)).Name()
+
+		if s, err := p.gameLogic.Strike(); err == nil {
+			p.view.DrawStrike(s)
+			p.view.SetStatus(fmt.Sprintf("%s wins", playerName))
+		} else if p.gameLogic.TimedOut() {
+			loserName := p.gameLogic.Player(game.PlayerType(state) % 2).Name()
+			p.view.SetStatus(fmt.Sprintf("%s ran out of time, %s wins", loserName, playerName))
+		} else {
+			loserName := p.gameLogic.Player(game.PlayerType(state) % 2).Name()
+			p.view.SetStatus(fmt.Sprintf("%s resigned, %s wins", loserName, playerName))
+		}
+	}
+}
+
+func (p *Presenter) setTurnStatus() {
+	playerName := p.gameLogic.Player(p.gameLogic.CurrentPlayer()).Name()
+
+	if p.remote && !p.spectator && p.gameLogic.CurrentPlayer() == p.localPlayer {
+		p.view.SetStatus(fmt.Sprintf("%s's turn (you)", playerName))
+		return
+	}
+
+	if p.IsComputer(p.gameLogic.CurrentPlayer()) {
+		p.view.SetStatus(fmt.Sprintf("%s's turn (computer)", playerName))
+		return
+	}
+
+	p.view.SetStatus(fmt.Sprintf("%s's turn", playerName))
+}
@@ -0,0 +1,438 @@
+package presenter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/clock"
+	"github.com/infastin/gomoku2go/internal/gomoku/engine"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/record"
+)
+
+// fakeView records what the presenter shows.
+type fakeView struct {
+	status      string
+	buttonLabel string
+	size        uint
+	interactive bool
+	stones      map[[2]uint]game.FieldType
+	strike      *game.Strike
+	clockOn     bool
+	hints       []Hint
+}
+
+func newFakeView() *fakeView {
+	return &fakeView{interactive: true}
+}
+
+func (v *fakeView) SetStatus(text string)       { v.status = text }
+func (v *fakeView) SetButtonLabel(label string) { v.buttonLabel = label }
+
+func (v *fakeView) InitBoard(size uint) {
+	v.size = size
+	v.stones = make(map[[2]uint]game.FieldType)
+	v.strike = nil
+}
+
+func (v *fakeView) SetInteractive(enabled bool) { v.interactive = enabled }
+
+func (v *fakeView) DrawStone(x, y uint, ft game.FieldType) {
+	v.stones[[2]uint{x, y}] = ft
+}
+
+func (v *fakeView) DrawStrike(s game.Strike) { v.strike = &s }
+
+func (v *fakeView) StartClock()                    { v.clockOn = true }
+func (v *fakeView) StopClock()                     { v.clockOn = false }
+func (v *fakeView) ResetClock()                    {}
+func (v *fakeView) SetClock(elapsed time.Duration) {}
+
+func (v *fakeView) ShowPlayerClocks(first, second time.Duration) {}
+func (v *fakeView) HidePlayerClocks()                            {}
+
+func (v *fakeView) AppendChat(t time.Time, from, text string) {}
+
+func (v *fakeView) ShowHints(hints []Hint) { v.hints = hints }
+func (v *fakeView) ClearHints()            { v.hints = nil }
+
+// newGame returns the constructor of the games of the presenter.
+func newGame(size, winCond uint) func() (*game.Game, error) {
+	return func() (*game.Game, error) {
+		return game.NewGame(game.NewPlayer("Alice"), game.NewPlayer("Bob"), size, winCond)
+	}
+}
+
+func click(t *testing.T, p *Presenter, moves ...[2]uint) {
+	t.Helper()
+
+	for _, m := range moves {
+		if err := p.Click(m[0], m[1]); err != nil {
+			t.Fatalf("click %v: %v", m, err)
+		}
+	}
+}
+
+func expectStatus(t *testing.T, v *fakeView, status string) {
+	t.Helper()
+
+	if v.status != status {
+		t.Errorf("status is %q, want %q", v.status, status)
+	}
+}
+
+func TestWin(t *testing.T) {
+	v := newFakeView()
+	p := New(v, newGame(5, 3))
+	p.StartGame()
+
+	expectStatus(t, v, "Alice's turn")
+
+	if v.buttonLabel != "Restart Game" {
+		t.Errorf("button label is %q, want %q", v.buttonLabel, "Restart Game")
+	}
+
+	click(t, p, [2]uint{0, 0})
+	expectStatus(t, v, "Bob's turn")
+
+	if ft := v.stones[[2]uint{0, 0}]; ft != game.FirstPlayerField {
+		t.Errorf("stone at 0,0 is %v, want %v", ft, game.FirstPlayerField)
+	}
+
+	if err := p.Click(0, 0); err == nil {
+		t.Error("a taken cell was played")
+	}
+
+	click(t, p, [2]uint{4, 4}, [2]uint{1, 1}, [2]uint{4, 3}, [2]uint{2, 2})
+
+	expectStatus(t, v, "Alice wins")
+
+	if v.buttonLabel != "Start Game" {
+		t.Errorf("button label is %q, want %q", v.buttonLabel, "Start Game")
+	}
+
+	if len(v.stones) != 5 {
+		t.Errorf("%d stones are shown, want 5", len(v.stones))
+	}
+
+	want := game.Strike{X0: 0, Y0: 0, X1: 2, Y1: 2}
+	if v.strike == nil || *v.strike != want {
+		t.Errorf("strike is %v, want %v", v.strike, want)
+	}
+
+	if v.clockOn {
+		t.Error("the clock runs after the end of the game")
+	}
+
+	if err := p.Click(3, 3); err == nil {
+		t.Error("a move was played after the end of the game")
+	}
+}
+
+func TestDraw(t *testing.T) {
+	v := newFakeView()
+	p := New(v, newGame(3, 3))
+	p.StartGame()
+
+	click(t, p,
+		[2]uint{0, 0}, [2]uint{1, 1}, [2]uint{2, 2},
+		[2]uint{0, 2}, [2]uint{2, 0}, [2]uint{1, 0},
+		[2]uint{1, 2}, [2]uint{2, 1}, [2]uint{0, 1})
+
+	expectStatus(t, v, "Draw")
+
+	if v.strike != nil {
+		t.Errorf("a strike is shown after a draw: %v", *v.strike)
+	}
+
+	if v.buttonLabel != "Start Game" {
+		t.Errorf("button label is %q, want %q", v.buttonLabel, "Start Game")
+	}
+}
+
+func TestUndo(t *testing.T) {
+	v := newFakeView()
+	p := New(v, newGame(5, 3))
+	p.StartGame()
+
+	if err := p.Undo(); err == nil {
+		t.Error("a move was taken back at the start of the game")
+	}
+
+	click(t, p, [2]uint{0, 0}, [2]uint{4, 4}, [2]uint{1, 1}, [2]uint{4, 3}, [2]uint{2, 2})
+	expectStatus(t, v, "Alice wins")
+
+	if err := p.Undo(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, v, "Alice's turn")
+
+	if v.buttonLabel != "Restart Game" {
+		t.Errorf("button label is %q, want %q", v.buttonLabel, "Restart Game")
+	}
+
+	if v.strike != nil {
+		t.Error("the strike is still shown")
+	}
+
+	if _, ok := v.stones[[2]uint{2, 2}]; ok || len(v.stones) != 4 {
+		t.Errorf("stones shown after undo: %v", v.stones)
+	}
+
+	if !v.clockOn {
+		t.Error("the clock does not run again")
+	}
+
+	if g := p.Game(); g.State() != game.NotFinished || len(g.Moves()) != 4 {
+		t.Errorf("game has state %v and %d moves after undo", g.State(), len(g.Moves()))
+	}
+}
+
+func TestUndoFixedMoves(t *testing.T) {
+	// A puzzle position with the second player to move against the computer.
+	g, _ := game.NewGame(game.NewPlayer("Alice"), game.NewPlayer("Bob"), 5, 3)
+	g.MakeMove(0, 0)
+	g.MakeMove(4, 4)
+	g.MakeMove(0, 4)
+
+	v := newFakeView()
+	p := New(v, newGame(5, 3))
+	p.SetComputer(game.FirstPlayer, true)
+	p.StartGameWith(g)
+	p.SetFixedMoves(3)
+
+	if err := p.Undo(); err == nil {
+		t.Error("a stone of the position was taken back")
+	}
+
+	click(t, p, [2]uint{4, 3})
+
+	if err := p.Play(4, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// The move and the reply are taken back, the position stays.
+	if err := p.Undo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(p.Game().Moves()); n != 3 {
+		t.Fatalf("%d moves are left after undo, want 3", n)
+	}
+
+	expectStatus(t, v, "Bob's turn")
+
+	if err := p.Undo(); err == nil {
+		t.Error("a stone of the position was taken back")
+	}
+
+	// A lone move of the player is taken back without touching the position.
+	click(t, p, [2]uint{4, 3})
+
+	if err := p.Undo(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(p.Game().Moves()); n != 3 {
+		t.Errorf("%d moves are left after undo, want 3", n)
+	}
+
+	// A new game has no fixed moves.
+	p.SetComputer(game.FirstPlayer, false)
+	p.StartGame()
+	click(t, p, [2]uint{2, 2})
+
+	if err := p.Undo(); err != nil {
+		t.Errorf("undo in a new game: %v", err)
+	}
+}
+
+func TestResign(t *testing.T) {
+	v := newFakeView()
+	p := New(v, newGame(5, 3))
+	p.StartGame()
+
+	click(t, p, [2]uint{2, 2})
+
+	if err := p.Resign(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, v, "Bob resigned, Alice wins")
+
+	if v.strike != nil {
+		t.Error("a strike is shown after resigning")
+	}
+
+	if v.buttonLabel != "Start Game" {
+		t.Errorf("button label is %q, want %q", v.buttonLabel, "Start Game")
+	}
+
+	if err := p.Resign(); err == nil {
+		t.Error("a finished game was resigned")
+	}
+
+	if err := p.StartGame(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, v, "Alice's turn")
+
+	if len(v.stones) != 0 {
+		t.Errorf("%d stones are shown in a new game", len(v.stones))
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	v := newFakeView()
+	p := New(v, newGame(5, 3))
+	p.SetTimeControl(clock.TimeControl{Base: time.Millisecond})
+	p.StartGame()
+
+	time.Sleep(10 * time.Millisecond)
+	p.CheckTime()
+
+	expectStatus(t, v, "Alice ran out of time, Bob wins")
+
+	g := p.Game()
+	if g.State() != game.SecondPlayerWin || !g.TimedOut() || g.Resigned() {
+		t.Errorf("game has state %v, timed out %v, resigned %v", g.State(), g.TimedOut(), g.Resigned())
+	}
+
+	r, err := p.Record()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Result != game.SecondPlayerWin || r.Reason != record.TimeoutReason {
+		t.Errorf("the record has result %v for %q", r.Result, r.Reason)
+	}
+
+	// A game loaded from the record has been lost on time too.
+	loaded, err := r.Game()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p.StartGameWith(loaded)
+	expectStatus(t, v, "Alice ran out of time, Bob wins")
+}
+
+// computerReply plays the move of the computer like the application does.
+func computerReply(t *testing.T, p *Presenter, e *engine.Engine) {
+	t.Helper()
+
+	m, err := e.Play(context.Background(), p.Game().Clone(), engine.MinLevel, engine.TimeLeft{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Play(m.X, m.Y); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestComputer(t *testing.T) {
+	e, err := engine.New(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e.Seed(1)
+
+	v := newFakeView()
+	p := New(v, newGame(5, 3))
+	p.StartGame()
+	p.SetComputer(game.SecondPlayer, true)
+
+	click(t, p, [2]uint{0, 0})
+	expectStatus(t, v, "Bob's turn (computer)")
+
+	if err := p.Click(4, 4); err == nil {
+		t.Error("a move was played for the computer")
+	}
+
+	computerReply(t, p, e)
+	expectStatus(t, v, "Alice's turn")
+
+	if n := len(v.stones); n != 2 {
+		t.Fatalf("%d stones are shown, want 2", n)
+	}
+
+	// Undoing against the computer takes back its reply too.
+	if err := p.Undo(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, v, "Alice's turn")
+
+	if n := len(p.Game().Moves()); n != 0 {
+		t.Fatalf("%d moves are left after undo, want 0", n)
+	}
+
+	if len(v.stones) != 0 {
+		t.Errorf("stones shown after undo: %v", v.stones)
+	}
+
+	// The computer never misses a win in one move, even at the lowest level.
+	click(t, p, [2]uint{0, 0})
+	if err := p.Play(2, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	click(t, p, [2]uint{4, 0})
+	if err := p.Play(2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	click(t, p, [2]uint{0, 4})
+	computerReply(t, p, e)
+
+	expectStatus(t, v, "Bob wins")
+
+	if v.strike == nil {
+		t.Fatal("no strike is shown")
+	}
+
+	if s := *v.strike; s.X0 != 2 || s.X1 != 2 {
+		t.Errorf("strike is %v, want one along x = 2", s)
+	}
+}
+
+func TestBrowsing(t *testing.T) {
+	v := newFakeView()
+	p := New(v, newGame(5, 3))
+	p.StartGame()
+
+	click(t, p, [2]uint{0, 0}, [2]uint{4, 4}, [2]uint{1, 1})
+
+	if err := p.Back(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, v, "Move 2 of 3")
+
+	if v.interactive {
+		t.Error("the board takes clicks while browsing")
+	}
+
+	if err := p.Click(2, 2); err == nil {
+		t.Error("a move was played while browsing")
+	}
+
+	if len(v.stones) != 2 {
+		t.Errorf("%d stones are shown, want 2", len(v.stones))
+	}
+
+	if err := p.Forward(); err != nil {
+		t.Fatal(err)
+	}
+
+	expectStatus(t, v, "Bob's turn")
+
+	if !v.interactive || len(v.stones) != 3 {
+		t.Errorf("the board is interactive %v with %d stones, want true with 3", v.interactive, len(v.stones))
+	}
+}
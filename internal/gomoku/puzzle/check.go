@@ -0,0 +1,253 @@
+package puzzle
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/analysis"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+const (
+	// DefaultBudget is how many positions are searched at most to check a
+	// move or to find a reply.
+	DefaultBudget = 2000000
+
+	// forcedWinBudget is the budget of the searches for forced wins of the
+	// VCF and Defend goals.
+	forcedWinBudget = 200000
+)
+
+// ErrTooComplex is returned when a move cannot be checked within the budget.
+var ErrTooComplex = errors.New("the position is too complex to check")
+
+// Check tells whether the move of the player solving the puzzle keeps the
+// goal within reach. The game is the position of the puzzle after the moves
+// played so far, with the player solving the puzzle to move.
+func (p *Puzzle) Check(g *game.Game, x, y uint) (bool, error) {
+	if err := p.fits(g); err != nil {
+		return false, err
+	}
+
+	if g.CurrentPlayer() != p.Player() {
+		return false, fmt.Errorf("it is not the turn of the player solving the puzzle")
+	}
+
+	after := g.Clone()
+	if err := after.MakeMove(x, y); err != nil {
+		return false, err
+	}
+
+	if after.State() != game.NotFinished {
+		return after.State() == winState(p.Player()), nil
+	}
+
+	switch p.Goal {
+	case WinIn:
+		left := p.movesLeft(g) - 1
+		if left < 1 {
+			return false, nil
+		}
+
+		pr := newProver(after.Bitboard(), p.Player())
+		ok := pr.holds(left)
+
+		if pr.exhausted {
+			return false, ErrTooComplex
+		}
+
+		return ok, nil
+	case VCF:
+		b := after.Bitboard()
+		a, d := p.Player(), (p.Player()+1)%2
+
+		if len(winCells(b, d)) != 0 {
+			return false, nil
+		}
+
+		switch aw := winCells(b, a); len(aw) {
+		case 0:
+			return false, nil
+		case 1:
+			bx, by := b.Coords(aw[0])
+			after.MakeMove(bx, by)
+
+			res := analysis.FindVCF(after, forcedWinBudget)
+			if res.Exhausted {
+				return false, ErrTooComplex
+			}
+
+			return res.Found, nil
+		}
+
+		return true, nil
+	}
+
+	return defends(after)
+}
+
+// defends tells whether the player to move has neither a victory by
+// continuous fours nor a win in two moves, like from an open three.
+func defends(g *game.Game) (bool, error) {
+	res := analysis.FindVCF(g, forcedWinBudget)
+	if res.Exhausted {
+		return false, ErrTooComplex
+	}
+
+	if res.Found {
+		return false, nil
+	}
+
+	pr := newProver(g.Bitboard(), g.CurrentPlayer())
+	win := pr.winIn(2)
+
+	if pr.exhausted {
+		return false, ErrTooComplex
+	}
+
+	return !win, nil
+}
+
+// Reply returns the move of the opponent of the player solving the puzzle,
+// the defense lasting longest. The game is the position after a correct
+// move, with the opponent to move.
+func (p *Puzzle) Reply(g *game.Game) (x, y uint, err error) {
+	if err := p.fits(g); err != nil {
+		return 0, 0, err
+	}
+
+	if g.State() != game.NotFinished || g.CurrentPlayer() == p.Player() {
+		return 0, 0, fmt.Errorf("it is not the turn of the opponent")
+	}
+
+	b := g.Bitboard()
+
+	if p.Goal != WinIn {
+		// A four has to be blocked.
+		if aw := winCells(b, p.Player()); len(aw) != 0 {
+			x, y := b.Coords(aw[0])
+			return x, y, nil
+		}
+
+		replies := replyCells(b, p.Player())
+		if len(replies) == 0 {
+			return 0, 0, fmt.Errorf("there are no moves")
+		}
+
+		x, y := b.Coords(replies[0])
+		return x, y, nil
+	}
+
+	pr := newProver(b, p.Player())
+
+	// The defense does not matter when the attacker wins anyway, only how
+	// long it lasts.
+	cell, _ := pr.defense(p.movesLeft(g))
+	if pr.exhausted {
+		return 0, 0, ErrTooComplex
+	}
+
+	x, y = b.Coords(cell)
+	return x, y, nil
+}
+
+// Solved tells whether the goal of the puzzle is reached in the game, which
+// has to be played with correct moves only.
+func (p *Puzzle) Solved(g *game.Game) bool {
+	if p.Goal == Defend {
+		return len(g.Moves()) > p.Stones()
+	}
+
+	return g.State() == winState(p.Player())
+}
+
+// Validate checks the puzzle with the solver: the goal can be reached and,
+// for wins, not any faster. A Defend puzzle needs a defense and a move that
+// does not defend.
+func (p *Puzzle) Validate() error {
+	if err := p.check(); err != nil {
+		return err
+	}
+
+	g, _ := p.Game(game.NewPlayer("o"), game.NewPlayer("x"))
+	b := g.Bitboard()
+	a := p.Player()
+
+	switch p.Goal {
+	case WinIn:
+		pr := newProver(b, a)
+
+		if !pr.winIn(p.Moves) {
+			if pr.exhausted {
+				return fmt.Errorf("puzzle %s: %v", p.ID, ErrTooComplex)
+			}
+
+			return fmt.Errorf("puzzle %s: there is no win in %d", p.ID, p.Moves)
+		}
+
+		if p.Moves > 1 && pr.winIn(p.Moves-1) {
+			return fmt.Errorf("puzzle %s: there is a win in %d already", p.ID, p.Moves-1)
+		}
+	case VCF:
+		if len(winCells(b, a)) != 0 {
+			return fmt.Errorf("puzzle %s: there is a win in 1", p.ID)
+		}
+
+		if res := analysis.FindVCF(g, forcedWinBudget); !res.Found {
+			return fmt.Errorf("puzzle %s: there is no victory by continuous fours", p.ID)
+		}
+	case Defend:
+		var good, bad bool
+
+		for _, cell := range replyCells(b, (a+1)%2) {
+			x, y := b.Coords(cell)
+
+			ok, err := p.Check(g, x, y)
+			if err != nil {
+				return fmt.Errorf("puzzle %s: %v", p.ID, err)
+			}
+
+			if ok {
+				good = true
+			} else {
+				bad = true
+			}
+		}
+
+		if !good {
+			return fmt.Errorf("puzzle %s: there is no defense", p.ID)
+		}
+
+		if !bad {
+			return fmt.Errorf("puzzle %s: the opponent does not threaten anything", p.ID)
+		}
+	}
+
+	return nil
+}
+
+func (p *Puzzle) fits(g *game.Game) error {
+	if g.Size() != p.Size || g.WinCond() != p.WinCond {
+		return fmt.Errorf("the game is not played on the board of the puzzle")
+	}
+
+	if len(g.Moves()) < p.Stones() {
+		return fmt.Errorf("the game does not start at the position of the puzzle")
+	}
+
+	return nil
+}
+
+// movesLeft returns how many moves the player solving a WinIn puzzle has
+// left in the game.
+func (p *Puzzle) movesLeft(g *game.Game) int {
+	return p.Moves - (len(g.Moves())-p.Stones()+1)/2
+}
+
+func winState(pt game.PlayerType) game.GameState {
+	if pt == game.FirstPlayer {
+		return game.FirstPlayerWin
+	}
+
+	return game.SecondPlayerWin
+}
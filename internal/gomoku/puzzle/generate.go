@@ -0,0 +1,175 @@
+package puzzle
+
+import (
+	"fmt"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/analysis"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+const (
+	// MaxRating is the rating of the hardest puzzles, the easiest ones are
+	// rated 1.
+	MaxRating = 5
+
+	// findBudget is the budget of the prover looking for wins in positions
+	// of games, which mostly have none.
+	findBudget = 200000
+)
+
+// New returns a puzzle with the position of the game, its goal is left to
+// the caller.
+func New(g *game.Game) Puzzle {
+	size := g.Size()
+
+	rows := make([][]byte, size)
+	for y := range rows {
+		rows[y] = make([]byte, size)
+
+		for x := range rows[y] {
+			rows[y][x] = '.'
+		}
+	}
+
+	for _, f := range g.Moves() {
+		switch f.Ft {
+		case game.FirstPlayerField:
+			rows[f.Y][f.X] = 'o'
+		case game.SecondPlayerField:
+			rows[f.Y][f.X] = 'x'
+		}
+	}
+
+	p := Puzzle{
+		Size:    size,
+		WinCond: g.WinCond(),
+		Board:   make([]string, size),
+	}
+
+	for y, row := range rows {
+		p.Board[y] = string(row)
+	}
+
+	return p
+}
+
+// Find looks for a forced win of the player to move with a single correct
+// first move: a win in at most MaxWinMoves moves, or else a victory by
+// continuous fours. Wins in one move are too easy to be puzzles. The puzzle
+// found is rated, its id and title are left to the caller.
+func Find(g *game.Game) (Puzzle, bool) {
+	if g.State() != game.NotFinished {
+		return Puzzle{}, false
+	}
+
+	b := g.Bitboard()
+	a := g.CurrentPlayer()
+
+	if len(winCells(b, a)) != 0 {
+		return Puzzle{}, false
+	}
+
+	p := New(g)
+
+	for n := 2; n <= MaxWinMoves; n++ {
+		pr := newProver(b, a)
+		pr.budget = findBudget
+
+		if pr.winIn(n) {
+			p.Goal = WinIn
+			p.Moves = n
+			break
+		}
+
+		if pr.exhausted {
+			return Puzzle{}, false
+		}
+	}
+
+	if p.Moves == 0 {
+		if res := analysis.FindVCF(g, forcedWinBudget); !res.Found {
+			return Puzzle{}, false
+		}
+
+		p.Goal = VCF
+	}
+
+	if n, err := p.solutions(g); err != nil || n != 1 {
+		return Puzzle{}, false
+	}
+
+	if err := p.Rate(); err != nil {
+		return Puzzle{}, false
+	}
+
+	return p, true
+}
+
+// solutions returns the number of correct first moves, counting up to two.
+func (p *Puzzle) solutions(g *game.Game) (int, error) {
+	b := g.Bitboard()
+	a := p.Player()
+
+	// Only the cells of windows the player can still fill and the blocks of
+	// fours of the opponent can start a win.
+	candidates := windowCells(b, a, 1)
+	candidates = append(candidates, winCells(b, (a+1)%2)...)
+
+	seen := make(map[uint]bool, len(candidates))
+	n := 0
+
+	for _, cell := range candidates {
+		if seen[cell] {
+			continue
+		}
+
+		seen[cell] = true
+		x, y := b.Coords(cell)
+
+		ok, err := p.Check(g, x, y)
+		if err != nil {
+			return 0, err
+		}
+
+		if ok {
+			if n++; n > 1 {
+				break
+			}
+		}
+	}
+
+	return n, nil
+}
+
+// Rate sets the rating of a puzzle from the number of moves of the win the
+// solver finds. The moves of a victory by continuous fours are all forced,
+// so they count less than those of other wins. Defend puzzles are not
+// rated.
+func (p *Puzzle) Rate() error {
+	g, err := p.Game(game.NewPlayer("o"), game.NewPlayer("x"))
+	if err != nil {
+		return err
+	}
+
+	switch p.Goal {
+	case WinIn:
+		p.Rating = p.Moves
+	case VCF:
+		res := analysis.FindVCF(g, forcedWinBudget)
+		if !res.Found {
+			return fmt.Errorf("puzzle %s: there is no victory by continuous fours", p.ID)
+		}
+
+		// The moves of the attacker.
+		depth := (len(res.Moves) + 1) / 2
+		p.Rating = 2 + depth/3
+	default:
+		p.Rating = 0
+	}
+
+	if p.Rating > MaxRating {
+		p.Rating = MaxRating
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package puzzle
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		id     string
+		goal   Goal
+		moves  int
+		rating int
+	}{
+		{"double-four", WinIn, 2, 2},
+		{"four-three", WinIn, 3, 3},
+		{"vcf-3", VCF, 0, 3},
+	}
+
+	for _, tt := range tests {
+		_, g := bundledPuzzle(t, tt.id)
+
+		p, ok := Find(g)
+		if !ok {
+			t.Errorf("%s: no puzzle found", tt.id)
+			continue
+		}
+
+		if p.Goal != tt.goal || p.Moves != tt.moves || p.Rating != tt.rating {
+			t.Errorf("%s: found a %v puzzle in %d moves rated %d, want a %v puzzle in %d moves rated %d",
+				tt.id, p.Goal, p.Moves, p.Rating, tt.goal, tt.moves, tt.rating)
+		}
+
+		if !reflect.DeepEqual(p.Board, New(g).Board) {
+			t.Errorf("%s: the board of the puzzle is not the position of the game", tt.id)
+		}
+
+		if n, err := p.solutions(g); err != nil || n != 1 {
+			t.Errorf("%s: the puzzle found has %d solutions, %v", tt.id, n, err)
+		}
+	}
+}
+
+func TestFindRejects(t *testing.T) {
+	// Either end of the open three makes an open four.
+	p, g := bundledPuzzle(t, "open-three")
+
+	if n, err := p.solutions(g); err != nil || n != 2 {
+		t.Fatalf("the open three has %d solutions, %v", n, err)
+	}
+
+	if _, ok := Find(g); ok {
+		t.Error("found a puzzle with two solutions")
+	}
+
+	// An open four is won in one move.
+	g.MakeMove(5, 7)
+	g.MakeMove(0, 14)
+
+	if _, ok := Find(g); ok {
+		t.Error("found a puzzle in a win in one move")
+	}
+
+	g.MakeMove(4, 7)
+
+	if _, ok := Find(g); ok {
+		t.Error("found a puzzle in a finished game")
+	}
+
+	// Nothing is forced at the start of a game.
+	g, _ = game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 15, 5)
+	g.MakeMove(7, 7)
+
+	if _, ok := Find(g); ok {
+		t.Error("found a puzzle after the first move")
+	}
+}
+
+func TestRate(t *testing.T) {
+	puzzles, err := Bundled()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The attacker makes 3 fours in vcf-1 and vcf-2, 4 in vcf-3 and 6 in
+	// vcf-4, the longest one.
+	for _, p := range puzzles {
+		want := p.Rating
+
+		if err := p.Rate(); err != nil {
+			t.Errorf("%s: %v", p.ID, err)
+			continue
+		}
+
+		if p.Rating != want {
+			t.Errorf("%s: rated %d, want %d", p.ID, p.Rating, want)
+		}
+	}
+
+	p, _ := bundledPuzzle(t, "vcf-1")
+	p.Goal = Defend
+
+	if p.Rate(); p.Rating != 0 {
+		t.Errorf("a defend puzzle is rated %d", p.Rating)
+	}
+}
+
+func TestGame(t *testing.T) {
+	puzzles, err := Bundled()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range puzzles {
+		g, err := p.Game(game.NewPlayer("o"), game.NewPlayer("x"))
+		if err != nil {
+			t.Fatalf("%s: %v", p.ID, err)
+		}
+
+		if len(g.Moves()) != p.Stones() || g.CurrentPlayer() != p.Player() || g.State() != game.NotFinished {
+			t.Errorf("%s: %d stones placed of %d, %v to move", p.ID, len(g.Moves()), p.Stones(), g.CurrentPlayer())
+		}
+
+		if !reflect.DeepEqual(New(g).Board, p.Board) {
+			t.Errorf("%s: the game is not at the position of the puzzle", p.ID)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		board []string
+		err   string
+	}{
+		// The five of the first player is made before the last stone of
+		// the second one is placed.
+		{"five", []string{"ooooo", ".....", "x....", ".....", "xxxx."}, "already over"},
+		{"second player five", []string{"xxxxx", ".....", ".....", "o....", "oooo."}, "already over"},
+		{"full", []string{"oxo", "oxo", "xox"}, "already over"},
+		{"stones", []string{"oo...", ".....", ".....", ".....", "....."}, "as many stones"},
+		{"cell", []string{"o?...", ".....", ".....", ".....", "....."}, "invalid cell"},
+		{"rows", []string{".....", "....."}, "rows"},
+	}
+
+	for _, tt := range tests {
+		p := Puzzle{ID: tt.name, Size: uint(len(tt.board[0])), WinCond: 3, Board: tt.board}
+		if p.Size == 5 {
+			p.WinCond = 5
+		}
+
+		if _, err := p.Game(game.NewPlayer("o"), game.NewPlayer("x")); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: the error is %v, want an error with %q", tt.name, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package puzzle
+
+import (
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// prover proves wins in a few moves by trying every move that matters. The
+// attacker only tries the cells of windows they can still fill, the defender
+// those and the cells making fours of their own. Other moves leave the lines
+// of the attacker as they are.
+type prover struct {
+	b        *game.Bitboard
+	attacker game.PlayerType
+	budget   int
+
+	nodes     int
+	exhausted bool
+}
+
+func newProver(b *game.Bitboard, attacker game.PlayerType) *prover {
+	return &prover{
+		b:        b,
+		attacker: attacker,
+		budget:   DefaultBudget,
+	}
+}
+
+// winIn tells whether the attacker, who is to move, wins with at most k
+// moves.
+func (pr *prover) winIn(k int) bool {
+	a := pr.attacker
+	d := (a + 1) % 2
+
+	if len(winCells(pr.b, a)) != 0 {
+		return true
+	}
+
+	if k <= 1 || !pr.step() {
+		return false
+	}
+
+	var candidates []uint
+
+	switch dw := winCells(pr.b, d); {
+	case len(dw) > 1:
+		return false
+	case len(dw) == 1:
+		candidates = dw
+	case k == 2:
+		// Only a four leaves a win for the next move.
+		candidates = windowCells(pr.b, a, pr.b.WinCond()-2)
+	default:
+		candidates = windowCells(pr.b, a, 1)
+	}
+
+	for _, cell := range candidates {
+		pr.b.Make(cell)
+		ok := pr.holds(k - 1)
+		pr.b.Unmake()
+
+		if ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// holds tells whether the attacker wins with at most k moves whatever the
+// defender, who is to move, does.
+func (pr *prover) holds(k int) bool {
+	_, ok := pr.defense(k)
+	return !ok && !pr.exhausted
+}
+
+// defense returns a move of the defender, who is to move, after which the
+// attacker does not win with at most k moves. If there is none, it returns
+// the move putting off the win the longest, and false.
+func (pr *prover) defense(k int) (uint, bool) {
+	a := pr.attacker
+	d := (a + 1) % 2
+
+	if pr.b.State() != game.NotFinished {
+		return 0, false
+	}
+
+	if dw := winCells(pr.b, d); len(dw) != 0 {
+		return dw[0], true
+	}
+
+	var replies []uint
+
+	switch aw := winCells(pr.b, a); {
+	case len(aw) > 1:
+		return aw[0], false
+	case len(aw) == 1:
+		replies = aw
+	default:
+		replies = replyCells(pr.b, a)
+	}
+
+	if len(replies) == 0 {
+		return 0, false
+	}
+
+	best, longest, blocked := replies[0], 0, 0
+
+	for _, cell := range replies {
+		weight := blockWeight(pr.b, a, cell)
+
+		pr.b.Make(cell)
+
+		// The shortest win after the reply.
+		n := 1
+		for n <= k && !pr.winIn(n) {
+			n++
+		}
+
+		pr.b.Unmake()
+
+		if pr.exhausted {
+			return 0, false
+		}
+
+		if n > k {
+			return cell, true
+		}
+
+		// Among the defenses lasting as long, the one blocking the most looks
+		// the most natural.
+		if n > longest || n == longest && weight > blocked {
+			best, longest, blocked = cell, n, weight
+		}
+	}
+
+	return best, false
+}
+
+func (pr *prover) step() bool {
+	if pr.nodes >= pr.budget {
+		pr.exhausted = true
+		return false
+	}
+
+	pr.nodes++
+
+	return true
+}
+
+// blockWeight tells how much a stone on the cell would block the player:
+// the squared numbers of stones of the windows of the player through it.
+func blockWeight(b *game.Bitboard, pt game.PlayerType, cell uint) int {
+	o := (pt + 1) % 2
+	weight := 0
+
+	for _, w := range b.Layout().CellWindows(cell) {
+		if b.Count(o, w) == 0 {
+			n := int(b.Count(pt, w))
+			weight += n * n
+		}
+	}
+
+	return weight
+}
+
+// winCells returns the empty cells on which the player wins.
+func winCells(b *game.Bitboard, pt game.PlayerType) []uint {
+	return windowCells(b, pt, b.WinCond()-1)
+}
+
+// windowCells returns the empty cells of the windows holding at least n
+// stones of the player and none of the opponent.
+func windowCells(b *game.Bitboard, pt game.PlayerType, n uint) []uint {
+	o := (pt + 1) % 2
+	size := b.Size()
+
+	var cells []uint
+
+	for cell := uint(0); cell < size*size; cell++ {
+		if !b.IsEmpty(cell) {
+			continue
+		}
+
+		for _, w := range b.Layout().CellWindows(cell) {
+			if b.Count(pt, w) >= n && b.Count(o, w) == 0 {
+				cells = append(cells, cell)
+				break
+			}
+		}
+	}
+
+	return cells
+}
+
+// replyCells returns the moves of the defender worth trying against the
+// attacker: the cells of the windows the attacker can still fill and the
+// cells making fours of the defender.
+func replyCells(b *game.Bitboard, attacker game.PlayerType) []uint {
+	cells := windowCells(b, attacker, 1)
+	seen := make(map[uint]bool, len(cells))
+
+	for _, cell := range cells {
+		seen[cell] = true
+	}
+
+	for _, cell := range windowCells(b, (attacker+1)%2, b.WinCond()-2) {
+		if !seen[cell] {
+			cells = append(cells, cell)
+		}
+	}
+
+	return cells
+}
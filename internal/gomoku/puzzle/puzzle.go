@@ -0,0 +1,293 @@
+// Package puzzle holds gomoku problems: positions with a player to move and
+// a goal to reach, like winning in a few moves or finding the only defense.
+package puzzle
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+const (
+	FormatVersion = 1
+
+	// MaxWinMoves is the longest win a puzzle may ask for.
+	MaxWinMoves = 3
+)
+
+//go:embed puzzles.json
+var bundled []byte
+
+type Goal uint
+
+const (
+	// WinIn asks to win in at most Moves moves against any defense.
+	WinIn Goal = iota
+
+	// VCF asks to win making a four with every move.
+	VCF
+
+	// Defend asks for a move after which the opponent has neither a victory
+	// by continuous fours nor a win in two moves.
+	Defend
+)
+
+func (goal Goal) String() string {
+	switch goal {
+	case WinIn:
+		return "win"
+	case VCF:
+		return "vcf"
+	case Defend:
+		return "defend"
+	}
+
+	return fmt.Sprintf("Goal(%d)", uint(goal))
+}
+
+func (goal Goal) MarshalText() ([]byte, error) {
+	switch goal {
+	case WinIn, VCF, Defend:
+		return []byte(goal.String()), nil
+	}
+
+	return nil, fmt.Errorf("unknown puzzle goal %d", uint(goal))
+}
+
+func (goal *Goal) UnmarshalText(text []byte) error {
+	for g := WinIn; g <= Defend; g++ {
+		if g.String() == string(text) {
+			*goal = g
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown puzzle goal %q", text)
+}
+
+type Puzzle struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Size    uint   `json:"size"`
+	WinCond uint   `json:"wincond"`
+	Goal    Goal   `json:"goal"`
+
+	// Moves is the number of moves to win in, for the WinIn goal only.
+	Moves int `json:"moves,omitempty"`
+
+	// Rating is the difficulty of the puzzle from 1 to MaxRating, or 0 if
+	// the puzzle is not rated.
+	Rating int `json:"rating,omitempty"`
+
+	// Board lists the rows of the board from the top: o is a stone of the
+	// first player, x of the second one and . an empty cell. The player to
+	// move follows from the numbers of stones.
+	Board []string `json:"board"`
+}
+
+// Description tells what the goal of the puzzle is.
+func (p *Puzzle) Description() string {
+	switch p.Goal {
+	case WinIn:
+		if p.Moves == 1 {
+			return "Win in 1 move"
+		}
+
+		return fmt.Sprintf("Win in %d moves", p.Moves)
+	case VCF:
+		return "Win with continuous fours"
+	case Defend:
+		return "Stop the forced win of the opponent"
+	}
+
+	return p.Goal.String()
+}
+
+// Stones returns the number of stones on the board of the puzzle.
+func (p *Puzzle) Stones() int {
+	n := 0
+
+	for _, row := range p.Board {
+		n += len(row) - bytes.Count([]byte(row), []byte{'.'})
+	}
+
+	return n
+}
+
+// Player returns the player solving the puzzle, who is to move first.
+func (p *Puzzle) Player() game.PlayerType {
+	if p.Stones()%2 == 0 {
+		return game.FirstPlayer
+	}
+
+	return game.SecondPlayer
+}
+
+// Game returns a game at the position of the puzzle. The stones of each
+// player are placed in order, taking turns, and the position is checked once
+// all of them are on the board.
+func (p *Puzzle) Game(p1, p2 *game.Player) (*game.Game, error) {
+	g, err := game.NewGame(p1, p2, p.Size, p.WinCond)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint(len(p.Board)) != p.Size {
+		return nil, fmt.Errorf("puzzle %s: the board has %d rows instead of %d", p.ID, len(p.Board), p.Size)
+	}
+
+	var stones [2][]game.Field
+
+	for y, row := range p.Board {
+		if uint(len(row)) != p.Size {
+			return nil, fmt.Errorf("puzzle %s: row %d has %d cells instead of %d", p.ID, y+1, len(row), p.Size)
+		}
+
+		for x, c := range row {
+			switch c {
+			case '.':
+			case 'o':
+				stones[game.FirstPlayer] = append(stones[game.FirstPlayer], game.Field{X: uint(x), Y: uint(y)})
+			case 'x':
+				stones[game.SecondPlayer] = append(stones[game.SecondPlayer], game.Field{X: uint(x), Y: uint(y)})
+			default:
+				return nil, fmt.Errorf("puzzle %s: invalid cell %q", p.ID, c)
+			}
+		}
+	}
+
+	first, second := stones[game.FirstPlayer], stones[game.SecondPlayer]
+	if len(first) != len(second) && len(first) != len(second)+1 {
+		return nil, fmt.Errorf("puzzle %s: the first player has to have as many stones as the second one or one more", p.ID)
+	}
+
+	// The stones are placed without looking for rows as they go, only the
+	// position with all of them on the board is judged.
+	place := func(f game.Field) error {
+		if _, err := g.SetField(f.X, f.Y); err != nil {
+			return err
+		}
+
+		g.ChangePlayer()
+
+		return nil
+	}
+
+	for i := range first {
+		if err := place(first[i]); err != nil {
+			return nil, err
+		}
+
+		if i < len(second) {
+			if err := place(second[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if _, won := g.Bitboard().Winner(); won || g.CheckDraw() {
+		return nil, fmt.Errorf("puzzle %s: the game is already over", p.ID)
+	}
+
+	return g, nil
+}
+
+func (p *Puzzle) check() error {
+	if p.ID == "" {
+		return fmt.Errorf("a puzzle has no id")
+	}
+
+	if p.Goal > Defend {
+		return fmt.Errorf("puzzle %s: unknown goal %d", p.ID, uint(p.Goal))
+	}
+
+	if p.Goal == WinIn && (p.Moves < 1 || p.Moves > MaxWinMoves) {
+		return fmt.Errorf("puzzle %s: the number of moves must be between 1 and %d", p.ID, MaxWinMoves)
+	}
+
+	if p.Rating < 0 || p.Rating > MaxRating {
+		return fmt.Errorf("puzzle %s: the rating must be between 0 and %d", p.ID, MaxRating)
+	}
+
+	_, err := p.Game(game.NewPlayer("o"), game.NewPlayer("x"))
+	return err
+}
+
+type file struct {
+	Version int      `json:"version"`
+	Puzzles []Puzzle `json:"puzzles"`
+}
+
+func Read(rd io.Reader) ([]Puzzle, error) {
+	var f file
+
+	if err := json.NewDecoder(rd).Decode(&f); err != nil {
+		return nil, fmt.Errorf("malformed puzzle file: %v", err)
+	}
+
+	if f.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported puzzle file version %d", f.Version)
+	}
+
+	ids := make(map[string]bool, len(f.Puzzles))
+
+	for i := range f.Puzzles {
+		p := &f.Puzzles[i]
+
+		if err := p.check(); err != nil {
+			return nil, fmt.Errorf("malformed puzzle file: %v", err)
+		}
+
+		if ids[p.ID] {
+			return nil, fmt.Errorf("malformed puzzle file: puzzle %s appears twice", p.ID)
+		}
+
+		ids[p.ID] = true
+	}
+
+	return f.Puzzles, nil
+}
+
+func Load(path string) ([]Puzzle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+func Write(w io.Writer, puzzles []Puzzle) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+
+	return enc.Encode(&file{
+		Version: FormatVersion,
+		Puzzles: puzzles,
+	})
+}
+
+func Save(path string, puzzles []Puzzle) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := Write(f, puzzles); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Bundled returns the puzzles that come with the application.
+func Bundled() ([]Puzzle, error) {
+	return Read(bytes.NewReader(bundled))
+}
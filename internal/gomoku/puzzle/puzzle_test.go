@@ -0,0 +1,234 @@
+package puzzle
+
+import (
+	"testing"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/analysis"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// bundledPuzzle returns the bundled puzzle with the id and a game at its
+// position.
+func bundledPuzzle(t *testing.T, id string) (*Puzzle, *game.Game) {
+	t.Helper()
+
+	puzzles, err := Bundled()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range puzzles {
+		if p := &puzzles[i]; p.ID == id {
+			g, err := p.Game(game.NewPlayer("o"), game.NewPlayer("x"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return p, g
+		}
+	}
+
+	t.Fatalf("there is no bundled puzzle %s", id)
+	return nil, nil
+}
+
+// checkMove checks the move with the puzzle and plays it.
+func checkMove(t *testing.T, p *Puzzle, g *game.Game, x, y uint, want bool) {
+	t.Helper()
+
+	ok, err := p.Check(g, x, y)
+	if err != nil {
+		t.Fatalf("checking %d,%d: %v", x, y, err)
+	}
+
+	if ok != want {
+		t.Fatalf("Check(%d, %d) is %v, want %v", x, y, ok, want)
+	}
+
+	if ok {
+		if err := g.MakeMove(x, y); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// reply plays the reply of the opponent.
+func reply(t *testing.T, p *Puzzle, g *game.Game) (uint, uint) {
+	t.Helper()
+
+	x, y, err := p.Reply(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := g.MakeMove(x, y); err != nil {
+		t.Fatalf("the reply %d,%d: %v", x, y, err)
+	}
+
+	return x, y
+}
+
+func TestBundled(t *testing.T) {
+	puzzles, err := Bundled()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(puzzles) == 0 {
+		t.Fatal("there are no bundled puzzles")
+	}
+
+	for i := range puzzles {
+		p := &puzzles[i]
+
+		t.Run(p.ID, func(t *testing.T) {
+			if err := p.Validate(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestWinIn(t *testing.T) {
+	// The first player has an open three on the row y = 7 from x = 6 to 8.
+	p, g := bundledPuzzle(t, "open-three")
+
+	if p.Player() != game.FirstPlayer {
+		t.Fatalf("the puzzle is solved by %v", p.Player())
+	}
+
+	if _, err := p.Check(g, 6, 7); err == nil {
+		t.Error("a move on a stone was checked")
+	}
+
+	checkMove(t, p, g, 0, 0, false)
+	checkMove(t, p, g, 5, 7, true)
+
+	if p.Solved(g) {
+		t.Fatal("the puzzle is solved after the open four")
+	}
+
+	rx, _ := reply(t, p, g)
+
+	// Whichever end of the open four is blocked, the other one wins.
+	x := uint(4)
+	if rx == 4 {
+		x = 9
+	}
+
+	checkMove(t, p, g, x, 7, true)
+
+	if !p.Solved(g) {
+		t.Errorf("the puzzle is not solved after the five, state %v", g.State())
+	}
+}
+
+func TestVCF(t *testing.T) {
+	p, g := bundledPuzzle(t, "vcf-1")
+
+	checkMove(t, p, g, 0, 0, false)
+
+	for !p.Solved(g) {
+		res := analysis.FindVCF(g, forcedWinBudget)
+		if !res.Found {
+			t.Fatalf("there is no VCF at move %d", len(g.Moves())+1)
+		}
+
+		// A move that gives up the four loses the chain.
+		if len(res.Moves) > 1 {
+			checkMove(t, p, g, res.Moves[1].X, res.Moves[1].Y, false)
+		}
+
+		checkMove(t, p, g, res.Moves[0].X, res.Moves[0].Y, true)
+
+		if g.State() != game.NotFinished {
+			break
+		}
+
+		// The reply has to block a four, the last one may be a double four.
+		b := g.Bitboard()
+		fours := winCells(b, p.Player())
+
+		if x, y := reply(t, p, g); !containsCell(fours, b.Cell(x, y)) {
+			t.Fatalf("the reply %d,%d does not block a four at %v", x, y, fours)
+		}
+	}
+
+	if g.State() != winState(p.Player()) {
+		t.Errorf("the game ended with %v", g.State())
+	}
+}
+
+func TestDefend(t *testing.T) {
+	p, g := bundledPuzzle(t, "defend-1")
+	b := g.Bitboard()
+
+	var good, bad []uint
+
+	for _, cell := range replyCells(b, (p.Player()+1)%2) {
+		x, y := b.Coords(cell)
+
+		ok, err := p.Check(g, x, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ok {
+			good = append(good, cell)
+		} else {
+			bad = append(bad, cell)
+		}
+	}
+
+	if len(good) == 0 || len(bad) == 0 {
+		t.Fatalf("%d moves defend and %d do not", len(good), len(bad))
+	}
+
+	// After a move that does not defend the opponent has a forced win.
+	x, y := b.Coords(bad[0])
+	after := g.Clone()
+	after.MakeMove(x, y)
+
+	if res := analysis.FindVCT(after, forcedWinBudget); !res.Found {
+		t.Errorf("the opponent has no forced win after %d,%d", x, y)
+	}
+
+	if _, _, err := p.Reply(g); err == nil {
+		t.Error("the opponent replied before the defense")
+	}
+
+	x, y = b.Coords(good[0])
+	checkMove(t, p, g, x, y, true)
+
+	if !p.Solved(g) {
+		t.Error("the puzzle is not solved after the defense")
+	}
+
+	reply(t, p, g)
+}
+
+func TestNotThePuzzle(t *testing.T) {
+	p, _ := bundledPuzzle(t, "open-three")
+
+	g, _ := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 15, 5)
+
+	if _, err := p.Check(g, 7, 7); err == nil {
+		t.Error("a move of another game was checked")
+	}
+
+	g, _ = game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 10, 5)
+
+	if _, _, err := p.Reply(g); err == nil {
+		t.Error("a game on another board got a reply")
+	}
+}
+
+func containsCell(cells []uint, cell uint) bool {
+	for _, c := range cells {
+		if c == cell {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,175 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+const (
+	FormatVersion = 1
+	FileExtension = ".gomoku"
+)
+
+// The reasons a game is won without a row.
+const (
+	ResignReason  = "resign"
+	TimeoutReason = "timeout"
+)
+
+type Move struct {
+	X uint `json:"x"`
+	Y uint `json:"y"`
+}
+
+type ChatMessage struct {
+	Time time.Time `json:"time"`
+	From string    `json:"from"`
+	Text string    `json:"text"`
+}
+
+type Record struct {
+	Version int            `json:"version"`
+	Date    time.Time      `json:"date"`
+	Size    uint           `json:"size"`
+	WinCond uint           `json:"wincond"`
+	Players [2]string      `json:"players"`
+	Moves   []Move         `json:"moves"`
+	Result  game.GameState `json:"result"`
+	// Reason tells how a game won without a row has ended.
+	Reason string        `json:"reason,omitempty"`
+	Chat   []ChatMessage `json:"chat,omitempty"`
+	// Hints is the number of hints each player has used.
+	Hints [2]uint `json:"hints"`
+}
+
+func New(g *game.Game) *Record {
+	r := &Record{
+		Version: FormatVersion,
+		Date:    time.Now(),
+		Size:    g.Size(),
+		WinCond: g.WinCond(),
+		Players: [2]string{
+			g.Player(game.FirstPlayer).Name(),
+			g.Player(game.SecondPlayer).Name(),
+		},
+		Moves:  []Move{},
+		Result: g.State(),
+	}
+
+	switch {
+	case g.Resigned():
+		r.Reason = ResignReason
+	case g.TimedOut():
+		r.Reason = TimeoutReason
+	}
+
+	for _, f := range g.Moves() {
+		r.Moves = append(r.Moves, Move{X: f.X, Y: f.Y})
+	}
+
+	return r
+}
+
+func Read(rd io.Reader) (*Record, error) {
+	r := &Record{}
+
+	if err := json.NewDecoder(rd).Decode(r); err != nil {
+		return nil, fmt.Errorf("malformed game record: %v", err)
+	}
+
+	if r.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported game record version %d", r.Version)
+	}
+
+	return r, nil
+}
+
+func Load(path string) (*Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return Read(f)
+}
+
+func (r *Record) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+
+	return enc.Encode(r)
+}
+
+func (r *Record) Save(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := r.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+func (r *Record) Game() (*game.Game, error) {
+	p1 := game.NewPlayer(r.Players[0])
+	p2 := game.NewPlayer(r.Players[1])
+
+	g, err := game.NewGame(p1, p2, r.Size, r.WinCond)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, m := range r.Moves {
+		if err := g.MakeMove(m.X, m.Y); err != nil {
+			return nil, fmt.Errorf("invalid move %d: %v", i+1, err)
+		}
+	}
+
+	if g.State() == game.NotFinished && (r.Result == game.FirstPlayerWin || r.Result == game.SecondPlayerWin) {
+		loser := game.PlayerType(r.Result) % 2
+
+		if r.Reason == TimeoutReason {
+			g.LoseOnTime(loser)
+		} else {
+			g.Resign(loser)
+		}
+	}
+
+	if g.State() != r.Result {
+		return nil, fmt.Errorf("game result does not match the moves")
+	}
+
+	return g, nil
+}
+
+// Key identifies the game up to the symmetries of the board: records of the
+// same game, however turned, have the same key.
+func (r *Record) Key() (string, error) {
+	g, err := r.Game()
+	if err != nil {
+		return "", err
+	}
+
+	moves, _ := g.CanonicalMoves()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%d/%d/%d:", r.Size, r.WinCond, r.Result)
+
+	for _, m := range moves {
+		fmt.Fprintf(&sb, "%d,%d;", m.X, m.Y)
+	}
+
+	return sb.String(), nil
+}
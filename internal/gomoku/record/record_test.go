@@ -0,0 +1,74 @@
+package record
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+func TestReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		end    func(g *game.Game)
+		reason string
+	}{
+		{"row", func(g *game.Game) { g.MakeMove(0, 2) }, ""},
+		{"resign", func(g *game.Game) { g.Resign(game.FirstPlayer) }, ResignReason},
+		{"timeout", func(g *game.Game) { g.LoseOnTime(game.FirstPlayer) }, TimeoutReason},
+	}
+
+	for _, tt := range tests {
+		g, _ := game.NewGame(game.NewPlayer("Alice"), game.NewPlayer("Bob"), 5, 3)
+
+		for _, m := range [][2]uint{{0, 0}, {4, 4}, {0, 1}, {4, 3}} {
+			g.MakeMove(m[0], m[1])
+		}
+
+		tt.end(g)
+
+		var buf bytes.Buffer
+
+		if err := New(g).Write(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		r, err := Read(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if r.Reason != tt.reason || r.Result != g.State() {
+			t.Errorf("%s: the record has result %v for %q, want %v for %q", tt.name, r.Result, r.Reason, g.State(), tt.reason)
+		}
+
+		loaded, err := r.Game()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if loaded.State() != g.State() || loaded.Resigned() != g.Resigned() || loaded.TimedOut() != g.TimedOut() {
+			t.Errorf("%s: the loaded game has state %v, resigned %v, timed out %v",
+				tt.name, loaded.State(), loaded.Resigned(), loaded.TimedOut())
+		}
+	}
+}
+
+func TestReasonMissing(t *testing.T) {
+	// Records from before the reason was saved are resignations.
+	data := `{"version":1,"size":5,"wincond":3,"players":["Alice","Bob"],"moves":[{"x":0,"y":0}],"result":2}`
+
+	r, err := Read(bytes.NewReader([]byte(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := r.Game()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.State() != game.SecondPlayerWin || !g.Resigned() {
+		t.Errorf("the game has state %v, resigned %v", g.State(), g.Resigned())
+	}
+}
@@ -0,0 +1,117 @@
+package settings
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/diamondburned/gotk4/pkg/gio/v2"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/clock"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+const (
+	schemaID = "com.github.infastin.gomoku2go"
+
+	maxNameLen = 16
+)
+
+type Settings struct {
+	*gio.Settings
+}
+
+func New() *Settings {
+	s := &Settings{gio.NewSettings(schemaID)}
+
+	if len(s.String("player1")) > maxNameLen {
+		s.SetString("player1", "Player 1")
+	}
+
+	if len(s.String("player2")) > maxNameLen {
+		s.SetString("player2", "Player 2")
+	}
+
+	return s
+}
+
+func (s *Settings) FirstPlayerName() string {
+	return s.String("player1")
+}
+
+func (s *Settings) SecondPlayerName() string {
+	return s.String("player2")
+}
+
+func (s *Settings) BoardSize() uint {
+	return s.Uint("size")
+}
+
+func (s *Settings) WinCond() uint {
+	return s.Uint("wincond")
+}
+
+// PlayerLevel returns the difficulty level of the computer playing for the
+// player, or 0 if a human plays.
+func (s *Settings) PlayerLevel(pt game.PlayerType) uint {
+	if pt == game.FirstPlayer {
+		return s.Uint("player1-level")
+	}
+
+	return s.Uint("player2-level")
+}
+
+// UseBook tells whether the computer plays the openings from the opening
+// book.
+func (s *Settings) UseBook() bool {
+	return s.Boolean("use-book")
+}
+
+// TimeControl returns the clock of local games, untimed if no minutes are
+// set.
+func (s *Settings) TimeControl() clock.TimeControl {
+	return clock.TimeControl{
+		Base:      time.Duration(s.Uint("time-minutes")) * time.Minute,
+		Increment: time.Duration(s.Uint("time-increment")) * time.Second,
+	}
+}
+
+// Ponder tells whether the computer thinks on the time of its human
+// opponent.
+func (s *Settings) Ponder() bool {
+	return s.Boolean("ponder")
+}
+
+// Threads returns the number of threads the computer thinks on, one per
+// processor unless set.
+func (s *Settings) Threads() int {
+	if n := s.Uint("threads"); n != 0 {
+		return int(n)
+	}
+
+	return runtime.NumCPU()
+}
+
+// SolvedPuzzles returns the ids of the puzzles solved so far.
+func (s *Settings) SolvedPuzzles() []string {
+	return s.Strv("solved-puzzles")
+}
+
+// SetPuzzleSolved adds the puzzle to the solved ones.
+func (s *Settings) SetPuzzleSolved(id string) {
+	solved := s.SolvedPuzzles()
+
+	for _, sid := range solved {
+		if sid == id {
+			return
+		}
+	}
+
+	s.SetStrv("solved-puzzles", append(solved, id))
+}
+
+func (s *Settings) NewGame() (*game.Game, error) {
+	p1 := game.NewPlayer(s.FirstPlayerName())
+	p2 := game.NewPlayer(s.SecondPlayerName())
+
+	return game.NewGame(p1, p2, s.BoardSize(), s.WinCond())
+}
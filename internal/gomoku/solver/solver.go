@@ -0,0 +1,390 @@
+// Package solver finds the game-theoretic value of positions on small
+// boards: whether the player to move wins, draws or loses with perfect play
+// on both sides.
+//
+// The solver is an alpha-beta search over the three values. Solved positions
+// are remembered in a transposition table by their canonical hash, so
+// positions equivalent under a symmetry of the board are solved once.
+package solver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/tt"
+)
+
+const (
+	// MaxCells is the number of cells of the largest board the solver takes.
+	MaxCells = 25
+
+	DefaultTableSize = 64 << 20
+
+	// DefaultBudget is how many positions a solver searches at most for one
+	// position before it gives up.
+	DefaultBudget = 50_000_000
+
+	checkEvery = 4096
+)
+
+// ErrTooComplex is returned when the budget of the solver runs out.
+var ErrTooComplex = errors.New("the position is too complex to solve")
+
+type Value int8
+
+const (
+	Loss Value = -1
+	Draw Value = 0
+	Win  Value = 1
+)
+
+func (v Value) String() string {
+	switch v {
+	case Loss:
+		return "loss"
+	case Draw:
+		return "draw"
+	case Win:
+		return "win"
+	}
+
+	return fmt.Sprintf("Value(%d)", int8(v))
+}
+
+// Letter returns W, D or L.
+func (v Value) Letter() string {
+	switch v {
+	case Loss:
+		return "L"
+	case Win:
+		return "W"
+	}
+
+	return "D"
+}
+
+// MoveValue is the value of a move for the player making it.
+type MoveValue struct {
+	X, Y  uint
+	Value Value
+}
+
+// Solver solves positions of games on boards of one size and win condition.
+// A solver runs one solve at a time.
+type Solver struct {
+	size    uint
+	winCond uint
+	budget  int
+
+	table *tt.Table
+	nodes int
+	limit int
+	ctx   context.Context
+	err   error
+
+	// order lists the cells from the center out.
+	order []uint
+}
+
+// CanSolve tells whether games on the board can be solved.
+func CanSolve(size, winCond uint) bool {
+	return size*size <= MaxCells
+}
+
+func New(size, winCond uint) (*Solver, error) {
+	if err := game.CheckSettings(nil, nil, size, winCond); err != nil {
+		return nil, err
+	}
+
+	if !CanSolve(size, winCond) {
+		return nil, fmt.Errorf("only boards of at most %d cells can be solved", MaxCells)
+	}
+
+	table, err := tt.New(DefaultTableSize, tt.TwoTier)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Solver{
+		size:    size,
+		winCond: winCond,
+		budget:  DefaultBudget,
+		table:   table,
+	}
+
+	center := float64(size-1) / 2
+	dist := func(cell uint) float64 {
+		dx, dy := float64(cell/size)-center, float64(cell%size)-center
+		return dx*dx + dy*dy
+	}
+
+	for cell := uint(0); cell < size*size; cell++ {
+		s.order = append(s.order, cell)
+	}
+
+	sort.SliceStable(s.order, func(i, j int) bool {
+		return dist(s.order[i]) < dist(s.order[j])
+	})
+
+	return s, nil
+}
+
+func (s *Solver) Size() uint {
+	return s.size
+}
+
+func (s *Solver) WinCond() uint {
+	return s.winCond
+}
+
+// SetBudget sets how many positions the solver searches at most.
+func (s *Solver) SetBudget(nodes int) {
+	s.budget = nodes
+}
+
+// Nodes returns the number of positions searched so far.
+func (s *Solver) Nodes() int {
+	return s.nodes
+}
+
+func (s *Solver) fits(g *game.Game) error {
+	if g.Size() != s.size || g.WinCond() != s.winCond {
+		return fmt.Errorf("the solver is for %dx%d boards with %d in a row", s.size, s.size, s.winCond)
+	}
+
+	return nil
+}
+
+// Solve returns the value of the position for the player to move.
+func (s *Solver) Solve(ctx context.Context, g *game.Game) (Value, error) {
+	if err := s.fits(g); err != nil {
+		return Draw, err
+	}
+
+	if g.State() != game.NotFinished {
+		return Draw, fmt.Errorf("the game is over")
+	}
+
+	b := g.Bitboard()
+	s.limit = s.nodes + s.budget
+
+	return s.run(ctx, func() Value {
+		return s.negamax(b, Loss, Win)
+	})
+}
+
+// Moves returns the value of every move of the player to move, the best
+// first.
+func (s *Solver) Moves(ctx context.Context, g *game.Game) ([]MoveValue, error) {
+	if err := s.fits(g); err != nil {
+		return nil, err
+	}
+
+	if g.State() != game.NotFinished {
+		return nil, fmt.Errorf("the game is over")
+	}
+
+	b := g.Bitboard()
+	pt := b.CurrentPlayer()
+	s.limit = s.nodes + s.budget
+
+	var res []MoveValue
+
+	for _, cell := range s.order {
+		if !b.IsEmpty(cell) {
+			continue
+		}
+
+		var v Value
+
+		if b.Wins(pt, cell) {
+			v = Win
+		} else {
+			b.Make(cell)
+
+			var err error
+			v, err = s.run(ctx, func() Value {
+				return -s.negamax(b, Loss, Win)
+			})
+
+			b.Unmake()
+
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		x, y := b.Coords(cell)
+		res = append(res, MoveValue{X: x, Y: y, Value: v})
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Value > res[j].Value
+	})
+
+	return res, nil
+}
+
+// Best returns a move keeping the best value for the player to move. A win
+// in one move is always played.
+func (s *Solver) Best(ctx context.Context, g *game.Game) (MoveValue, error) {
+	if err := s.fits(g); err != nil {
+		return MoveValue{}, err
+	}
+
+	if g.State() != game.NotFinished {
+		return MoveValue{}, fmt.Errorf("the game is over")
+	}
+
+	b := g.Bitboard()
+	pt := b.CurrentPlayer()
+
+	for _, cell := range s.order {
+		if b.IsEmpty(cell) && b.Wins(pt, cell) {
+			x, y := b.Coords(cell)
+			return MoveValue{X: x, Y: y, Value: Win}, nil
+		}
+	}
+
+	moves, err := s.Moves(ctx, g)
+	if err != nil {
+		return MoveValue{}, err
+	}
+
+	return moves[0], nil
+}
+
+func (s *Solver) run(ctx context.Context, f func() Value) (Value, error) {
+	s.ctx = ctx
+	s.err = nil
+
+	v := f()
+
+	s.ctx = nil
+
+	if s.err != nil {
+		return Draw, s.err
+	}
+
+	return v, nil
+}
+
+// negamax returns the value of the position for the player to move, or a
+// bound of it outside of (alpha, beta).
+func (s *Solver) negamax(b *game.Bitboard, alpha, beta Value) Value {
+	if s.err != nil {
+		return Draw
+	}
+
+	if s.nodes++; s.nodes%checkEvery == 0 {
+		switch {
+		case s.nodes >= s.limit:
+			s.err = ErrTooComplex
+		case s.ctx.Err() != nil:
+			s.err = s.ctx.Err()
+		}
+	}
+
+	switch b.State() {
+	case game.NotFinished:
+	case game.NobodyWins:
+		return Draw
+	default:
+		// The previous move has won.
+		return Loss
+	}
+
+	pt := b.CurrentPlayer()
+	o := (pt + 1) % 2
+
+	var blocks []uint
+
+	for _, cell := range s.order {
+		if !b.IsEmpty(cell) {
+			continue
+		}
+
+		if b.Wins(pt, cell) {
+			return Win
+		}
+
+		if b.Wins(o, cell) {
+			blocks = append(blocks, cell)
+		}
+	}
+
+	if len(blocks) > 1 {
+		return Loss
+	}
+
+	key := b.CanonicalHash()
+
+	if e, ok := s.table.Probe(key); ok {
+		v := Value(e.Score)
+
+		switch {
+		case e.Bound == tt.Exact,
+			e.Bound == tt.Lower && v >= beta,
+			e.Bound == tt.Upper && v <= alpha:
+			return v
+		}
+	}
+
+	moves := blocks
+	if moves == nil {
+		moves = s.order
+	}
+
+	origAlpha := alpha
+	best := Loss
+
+	for _, cell := range moves {
+		if !b.IsEmpty(cell) {
+			continue
+		}
+
+		b.Make(cell)
+		v := -s.negamax(b, -beta, -alpha)
+		b.Unmake()
+
+		if s.err != nil {
+			return Draw
+		}
+
+		if v > best {
+			best = v
+		}
+
+		if v > alpha {
+			alpha = v
+		}
+
+		if alpha >= beta {
+			break
+		}
+	}
+
+	bound := tt.Exact
+
+	switch {
+	case best <= origAlpha:
+		bound = tt.Upper
+	case best >= beta:
+		bound = tt.Lower
+	}
+
+	// Positions with more empty cells took longer to solve and are kept
+	// first.
+	s.table.Store(tt.Entry{
+		Key:   key,
+		Score: int32(best),
+		Move:  tt.NoMove,
+		Depth: int8(s.size*s.size - uint(len(b.Moves()))),
+		Bound: bound,
+	})
+
+	return best
+}
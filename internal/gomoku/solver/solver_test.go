@@ -0,0 +1,191 @@
+package solver
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+// checkMoves checks that the best move has the value of the position and
+// that every move has the value of the position it leads to.
+func checkMoves(t *testing.T, s *Solver, g *game.Game) Value {
+	t.Helper()
+
+	ctx := context.Background()
+
+	root, err := s.Solve(ctx, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moves, err := s.Moves(ctx, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(moves) != int(g.Size()*g.Size())-len(g.Moves()) {
+		t.Fatalf("%d move values for %d empty cells", len(moves), int(g.Size()*g.Size())-len(g.Moves()))
+	}
+
+	if moves[0].Value != root {
+		t.Errorf("the best move %+v does not have the value %v of the position", moves[0], root)
+	}
+
+	for i, m := range moves {
+		if i > 0 && m.Value > moves[i-1].Value {
+			t.Errorf("the moves are not sorted: %+v", moves)
+		}
+
+		after := g.Clone()
+		if err := after.MakeMove(m.X, m.Y); err != nil {
+			t.Fatal(err)
+		}
+
+		want := Draw
+
+		switch after.State() {
+		case game.NotFinished:
+			v, err := s.Solve(ctx, after)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want = -v
+		case game.NobodyWins:
+		default:
+			want = Win
+		}
+
+		if m.Value != want {
+			t.Errorf("move %d,%d has the value %v, the position after it %v", m.X, m.Y, m.Value, want)
+		}
+	}
+
+	best, err := s.Best(ctx, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if best.Value != root {
+		t.Errorf("Best returned %+v for a position with the value %v", best, root)
+	}
+
+	return root
+}
+
+func TestTicTacToe(t *testing.T) {
+	s, _ := New(3, 3)
+	g, _ := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 3, 3)
+
+	if v := checkMoves(t, s, g); v != Draw {
+		t.Errorf("3x3 with 3 in a row is a %v, want a draw", v)
+	}
+
+	moves, _ := s.Moves(context.Background(), g)
+
+	for _, m := range moves {
+		if m.Value != Draw {
+			t.Errorf("the first move %d,%d is a %v, want a draw", m.X, m.Y, m.Value)
+		}
+	}
+
+	// After a corner, the only answer that holds is the center.
+	g.MakeMove(0, 0)
+
+	if v := checkMoves(t, s, g); v != Draw {
+		t.Errorf("the answer to a corner is a %v, want a draw", v)
+	}
+
+	moves, _ = s.Moves(context.Background(), g)
+
+	for _, m := range moves {
+		center := m.X == 1 && m.Y == 1
+
+		if (m.Value == Draw) != center {
+			t.Errorf("the answer %d,%d to a corner is a %v", m.X, m.Y, m.Value)
+		}
+	}
+}
+
+func TestFirstPlayerWins(t *testing.T) {
+	// Three in a row on a 4x4 board is a win for the first player.
+	s, _ := New(4, 3)
+	g, _ := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 4, 3)
+
+	if v := checkMoves(t, s, g); v != Win {
+		t.Errorf("4x4 with 3 in a row is a %v, want a win", v)
+	}
+
+	// The second player wins if the first one throws the game away.
+	s, _ = New(3, 3)
+	g, _ = game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 3, 3)
+
+	for _, m := range [][2]uint{{0, 1}, {1, 1}, {2, 1}, {0, 0}} {
+		g.MakeMove(m[0], m[1])
+	}
+
+	if v := checkMoves(t, s, g); v != Loss {
+		t.Errorf("the position is a %v for the first player, want a loss", v)
+	}
+}
+
+func TestRandomPositions(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	s, _ := New(4, 3)
+
+	for i := 0; i < 10; i++ {
+		g, _ := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 4, 3)
+
+		for n := rng.Intn(6); n > 0 && g.State() == game.NotFinished; n-- {
+			x, y := uint(rng.Intn(4)), uint(rng.Intn(4))
+
+			if ft, _ := g.Field(x, y); ft == game.EmptyField {
+				g.MakeMove(x, y)
+			}
+		}
+
+		if g.State() == game.NotFinished {
+			checkMoves(t, s, g)
+		}
+	}
+}
+
+func TestTooComplex(t *testing.T) {
+	s, _ := New(5, 4)
+	s.SetBudget(1000)
+
+	g, _ := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 5, 4)
+
+	if _, err := s.Solve(context.Background(), g); !errors.Is(err, ErrTooComplex) {
+		t.Errorf("solving with a small budget gave %v, want %v", err, ErrTooComplex)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s.SetBudget(DefaultBudget)
+
+	if _, err := s.Solve(ctx, g); err == nil {
+		t.Error("a cancelled solve succeeded")
+	}
+}
+
+func TestNew(t *testing.T) {
+	if CanSolve(6, 4) {
+		t.Error("a 6x6 board can be solved")
+	}
+
+	if _, err := New(6, 4); err == nil {
+		t.Error("a solver for a 6x6 board was created")
+	}
+
+	s, _ := New(3, 3)
+	g, _ := game.NewGame(game.NewPlayer("o"), game.NewPlayer("x"), 4, 3)
+
+	if _, err := s.Solve(context.Background(), g); err == nil {
+		t.Error("a 4x4 game was solved by a 3x3 solver")
+	}
+}
@@ -0,0 +1,251 @@
+// Package tt implements a transposition table: a fixed size hash table of
+// search results keyed by the Zobrist hash of a position.
+//
+// Tables can be shared by searches running in parallel. Entries are guarded
+// by a fixed number of locks, each covering many buckets, so goroutines only
+// wait for each other when they touch buckets under the same lock.
+package tt
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+type Bound uint8
+
+const (
+	NoBound Bound = iota
+	Exact
+	Lower
+	Upper
+)
+
+// NoMove is stored in entries without a best move.
+const NoMove = ^uint16(0)
+
+type Entry struct {
+	Key   uint64
+	Score int32
+	Move  uint16
+	Depth int8
+	Bound Bound
+
+	age uint8
+}
+
+// Policy decides which entry of a bucket a new entry replaces.
+type Policy uint
+
+const (
+	// AlwaysReplace keeps the most recent entry.
+	AlwaysReplace Policy = iota
+	// DepthPreferred keeps the deeper entry, unless the stored one is left
+	// from an earlier search.
+	DepthPreferred
+	// TwoTier keeps two entries per bucket: a depth-preferred one and one that
+	// is always replaced.
+	TwoTier
+)
+
+const (
+	MinSize = 1 << 16
+
+	entrySize = int(unsafe.Sizeof(Entry{}))
+
+	maxLocks = 1024
+)
+
+type Table struct {
+	entries []Entry
+	mask    uint64
+	policy  Policy
+	age     uint8
+
+	locks    []sync.Mutex
+	lockMask uint64
+
+	probes uint64
+	hits   uint64
+}
+
+// New creates a table using at most size bytes. The number of entries is
+// rounded down to a power of two.
+func New(size int, policy Policy) (*Table, error) {
+	if size < MinSize {
+		return nil, fmt.Errorf("the table size must be at least %d bytes", MinSize)
+	}
+
+	if policy > TwoTier {
+		return nil, fmt.Errorf("unknown replacement policy %d", policy)
+	}
+
+	n := 1
+	for n*2*entrySize <= size {
+		n *= 2
+	}
+
+	locks := maxLocks
+	if locks > n/2 {
+		locks = n / 2
+	}
+
+	return &Table{
+		entries:  make([]Entry, n),
+		mask:     uint64(n - 1),
+		policy:   policy,
+		locks:    make([]sync.Mutex, locks),
+		lockMask: uint64(locks - 1),
+	}, nil
+}
+
+// Size returns the number of bytes used by the table.
+func (t *Table) Size() int {
+	return len(t.entries) * entrySize
+}
+
+func (t *Table) Len() int {
+	return len(t.entries)
+}
+
+// NewSearch marks entries stored so far as old, so that replacement policies
+// prefer to overwrite them. It must not be called while the table is in use.
+func (t *Table) NewSearch() {
+	t.age++
+}
+
+// Clear removes all the entries. It must not be called while the table is in
+// use.
+func (t *Table) Clear() {
+	for i := range t.entries {
+		t.entries[i] = Entry{}
+	}
+
+	t.age = 0
+	t.probes = 0
+	t.hits = 0
+}
+
+// lock locks the buckets the key may be stored in. Both entries of a two-tier
+// bucket are under the same lock.
+func (t *Table) lock(key uint64) *sync.Mutex {
+	mu := &t.locks[(key&t.mask)>>1&t.lockMask]
+	mu.Lock()
+
+	return mu
+}
+
+func (t *Table) bucket(key uint64) []Entry {
+	if t.policy == TwoTier {
+		i := key & t.mask &^ 1
+		return t.entries[i : i+2]
+	}
+
+	i := key & t.mask
+	return t.entries[i : i+1]
+}
+
+func (t *Table) Probe(key uint64) (Entry, bool) {
+	atomic.AddUint64(&t.probes, 1)
+
+	mu := t.lock(key)
+	defer mu.Unlock()
+
+	for _, e := range t.bucket(key) {
+		if e.Bound != NoBound && e.Key == key {
+			atomic.AddUint64(&t.hits, 1)
+			return e, true
+		}
+	}
+
+	return Entry{}, false
+}
+
+func (t *Table) Store(e Entry) {
+	if e.Bound == NoBound {
+		return
+	}
+
+	e.age = t.age
+
+	mu := t.lock(e.Key)
+	defer mu.Unlock()
+
+	bucket := t.bucket(e.Key)
+
+	// An entry for the same position is always updated, keeping its move if
+	// the new one has none.
+	for i := range bucket {
+		if bucket[i].Key == e.Key && bucket[i].Bound != NoBound {
+			if e.Move == NoMove {
+				e.Move = bucket[i].Move
+			}
+
+			switch {
+			case t.policy == TwoTier && i == 1:
+				// The always replaced tier takes any update, and one deep
+				// enough moves up to the first tier.
+				if t.replaces(&e, &bucket[0]) {
+					bucket[1] = bucket[0]
+					bucket[0] = e
+				} else {
+					bucket[1] = e
+				}
+			case t.policy == AlwaysReplace || t.replaces(&e, &bucket[i]):
+				bucket[i] = e
+			}
+
+			return
+		}
+	}
+
+	switch t.policy {
+	case AlwaysReplace:
+		bucket[0] = e
+	case DepthPreferred:
+		if t.replaces(&e, &bucket[0]) {
+			bucket[0] = e
+		}
+	case TwoTier:
+		if t.replaces(&e, &bucket[0]) {
+			bucket[1] = bucket[0]
+			bucket[0] = e
+		} else {
+			bucket[1] = e
+		}
+	}
+}
+
+func (t *Table) replaces(e, old *Entry) bool {
+	return old.Bound == NoBound || old.age != t.age || e.Depth >= old.Depth || e.Bound == Exact && old.Bound != Exact
+}
+
+// Hashfull returns how many of the first thousand entries are used by the
+// current search, in permille.
+func (t *Table) Hashfull() int {
+	n := 1000
+	if n > len(t.entries) {
+		n = len(t.entries)
+	}
+
+	used := 0
+
+	for i := range t.entries[:n] {
+		mu := t.lock(uint64(i))
+		e := t.entries[i]
+		mu.Unlock()
+
+		if e.Bound != NoBound && e.age == t.age {
+			used++
+		}
+	}
+
+	return used * 1000 / n
+}
+
+// Stats returns the number of probes and hits since the table was created or
+// cleared.
+func (t *Table) Stats() (probes, hits uint64) {
+	return atomic.LoadUint64(&t.probes), atomic.LoadUint64(&t.hits)
+}
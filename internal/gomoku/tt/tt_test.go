@@ -0,0 +1,230 @@
+package tt
+
+import (
+	"sync"
+	"testing"
+)
+
+func expectProbe(t *testing.T, table *Table, key uint64, found bool, depth int8) {
+	t.Helper()
+
+	e, ok := table.Probe(key)
+	if ok != found {
+		t.Fatalf("probing %x found %v, want %v", key, ok, found)
+	}
+
+	if ok && e.Depth != depth {
+		t.Errorf("entry %x has depth %d, want %d", key, e.Depth, depth)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(MinSize-1, AlwaysReplace); err == nil {
+		t.Error("a table smaller than MinSize was created")
+	}
+
+	if _, err := New(MinSize, TwoTier+1); err == nil {
+		t.Error("a table with an unknown policy was created")
+	}
+
+	for _, size := range []int{MinSize, MinSize + 1, 3 * MinSize, 1 << 20, 5<<20 + 123} {
+		table, err := New(size, TwoTier)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		n := table.Len()
+
+		if n&(n-1) != 0 {
+			t.Errorf("size %d: %d entries is not a power of two", size, n)
+		}
+
+		if table.Size() > size || 2*table.Size() <= size {
+			t.Errorf("size %d: the table uses %d bytes", size, table.Size())
+		}
+	}
+}
+
+func TestAlwaysReplace(t *testing.T) {
+	table, _ := New(MinSize, AlwaysReplace)
+
+	k1 := uint64(5)
+	k2 := k1 + uint64(table.Len())
+
+	table.Store(Entry{Key: k1, Depth: 8, Bound: Exact, Move: 3})
+	table.Store(Entry{Key: k2, Depth: 1, Bound: Upper, Move: NoMove})
+
+	expectProbe(t, table, k1, false, 0)
+	expectProbe(t, table, k2, true, 1)
+
+	// The same position keeps its move if the new entry has none.
+	table.Store(Entry{Key: k2, Depth: 2, Bound: Lower, Move: 7})
+	table.Store(Entry{Key: k2, Depth: 1, Bound: Upper, Move: NoMove})
+
+	if e, _ := table.Probe(k2); e.Depth != 1 || e.Move != 7 {
+		t.Errorf("entry has depth %d and move %d, want 1 and 7", e.Depth, e.Move)
+	}
+}
+
+func TestDepthPreferred(t *testing.T) {
+	table, _ := New(MinSize, DepthPreferred)
+
+	k1 := uint64(9)
+	k2 := k1 + uint64(table.Len())
+	k3 := k2 + uint64(table.Len())
+
+	table.Store(Entry{Key: k1, Depth: 8, Bound: Lower})
+	table.Store(Entry{Key: k2, Depth: 3, Bound: Lower})
+
+	expectProbe(t, table, k1, true, 8)
+	expectProbe(t, table, k2, false, 0)
+
+	// A shallower result for the same position does not replace it either.
+	table.Store(Entry{Key: k1, Depth: 2, Bound: Upper})
+	expectProbe(t, table, k1, true, 8)
+
+	// An exact score replaces a bound however deep.
+	table.Store(Entry{Key: k2, Depth: 3, Bound: Exact})
+	expectProbe(t, table, k2, true, 3)
+
+	// Entries of earlier searches are always replaced.
+	table.NewSearch()
+	table.Store(Entry{Key: k3, Depth: 1, Bound: Upper})
+
+	expectProbe(t, table, k2, false, 0)
+	expectProbe(t, table, k3, true, 1)
+}
+
+func TestTwoTier(t *testing.T) {
+	table, _ := New(MinSize, TwoTier)
+
+	// The keys share a bucket of two entries.
+	k1 := uint64(20)
+	k2 := k1 + 1
+	k3 := k1 + uint64(table.Len())
+	k4 := k3 + 1
+
+	table.Store(Entry{Key: k1, Depth: 8, Bound: Lower})
+	table.Store(Entry{Key: k2, Depth: 2, Bound: Lower})
+
+	expectProbe(t, table, k1, true, 8)
+	expectProbe(t, table, k2, true, 2)
+
+	// A shallow entry goes to the always replaced tier.
+	table.Store(Entry{Key: k3, Depth: 1, Bound: Lower})
+
+	expectProbe(t, table, k1, true, 8)
+	expectProbe(t, table, k2, false, 0)
+	expectProbe(t, table, k3, true, 1)
+
+	// A deep one takes the first tier and moves the old entry down.
+	table.Store(Entry{Key: k4, Depth: 9, Bound: Lower})
+
+	expectProbe(t, table, k4, true, 9)
+	expectProbe(t, table, k1, true, 8)
+	expectProbe(t, table, k3, false, 0)
+}
+
+func TestTwoTierSameKey(t *testing.T) {
+	table, _ := New(MinSize, TwoTier)
+
+	k1 := uint64(20)
+	k2 := k1 + uint64(table.Len())
+
+	table.Store(Entry{Key: k1, Depth: 8, Bound: Lower})
+	table.Store(Entry{Key: k2, Depth: 4, Bound: Lower, Move: 5})
+
+	// A shallower result for the position in the second tier replaces it.
+	table.Store(Entry{Key: k2, Depth: 2, Bound: Upper, Move: NoMove})
+
+	if e, ok := table.Probe(k2); !ok || e.Depth != 2 || e.Bound != Upper || e.Move != 5 {
+		t.Errorf("entry is %+v, %v, want the update with depth 2 and the old move", e, ok)
+	}
+
+	// A deeper one moves to the first tier.
+	table.Store(Entry{Key: k2, Depth: 9, Bound: Lower})
+
+	expectProbe(t, table, k2, true, 9)
+	expectProbe(t, table, k1, true, 8)
+
+	if b := table.bucket(k2); b[0].Key != k2 || b[1].Key != k1 {
+		t.Errorf("bucket holds %x and %x, want %x first", b[0].Key, b[1].Key, k2)
+	}
+}
+
+func TestNoBound(t *testing.T) {
+	table, _ := New(MinSize, AlwaysReplace)
+
+	table.Store(Entry{Key: 1, Depth: 3})
+	expectProbe(t, table, 1, false, 0)
+
+	// The key 0 of an empty entry is not found.
+	expectProbe(t, table, 0, false, 0)
+}
+
+func TestClear(t *testing.T) {
+	table, _ := New(MinSize, TwoTier)
+
+	table.Store(Entry{Key: 3, Depth: 3, Bound: Exact})
+	expectProbe(t, table, 3, true, 3)
+
+	if hashfull := table.Hashfull(); hashfull == 0 {
+		t.Error("the table is empty after a store")
+	}
+
+	table.Clear()
+	expectProbe(t, table, 3, false, 0)
+
+	if hashfull := table.Hashfull(); hashfull != 0 {
+		t.Errorf("hashfull is %d after clearing", hashfull)
+	}
+
+	if probes, hits := table.Stats(); probes != 1 || hits != 0 {
+		t.Errorf("stats are %d probes and %d hits, want 1 and 0", probes, hits)
+	}
+}
+
+// TestConcurrent stores and probes keys sharing locks from several
+// goroutines. Every entry holds its key in its score, so an entry torn by
+// two writers shows. Run with -race.
+func TestConcurrent(t *testing.T) {
+	for _, policy := range []Policy{AlwaysReplace, DepthPreferred, TwoTier} {
+		table, _ := New(MinSize, policy)
+
+		const (
+			workers = 8
+			stores  = 20000
+		)
+
+		// Keys that differ in the bits above the lock index only.
+		stride := uint64(len(table.locks)) * 2
+
+		var wg sync.WaitGroup
+
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+
+			go func(w int) {
+				defer wg.Done()
+
+				for i := 0; i < stores; i++ {
+					key := uint64(w*stores+i) * stride
+					table.Store(Entry{Key: key, Score: int32(key), Depth: int8(i % 16), Bound: Exact})
+
+					probe := uint64((w+1)%workers*stores+i) * stride
+					if e, ok := table.Probe(probe); ok && (e.Key != probe || e.Score != int32(probe)) {
+						t.Errorf("probing %x gave the entry %+v", probe, e)
+						return
+					}
+				}
+			}(w)
+		}
+
+		wg.Wait()
+
+		probes, hits := table.Stats()
+		if probes != workers*stores || hits > probes {
+			t.Errorf("policy %d: stats are %d probes and %d hits", policy, probes, hits)
+		}
+	}
+}
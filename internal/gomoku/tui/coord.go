@@ -0,0 +1,34 @@
+package tui
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func columnName(x uint) string {
+	return string(rune('a' + x))
+}
+
+func coordName(x, y uint) string {
+	return fmt.Sprintf("%s%d", columnName(x), y+1)
+}
+
+func parseCoord(s string, size uint) (x, y uint, err error) {
+	if len(s) < 2 || s[0] < 'a' || s[0] > 'z' {
+		return 0, 0, fmt.Errorf("invalid coordinate %q, expected a column letter followed by a row number", s)
+	}
+
+	row, err := strconv.ParseUint(s[1:], 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid coordinate %q, expected a column letter followed by a row number", s)
+	}
+
+	x = uint(s[0] - 'a')
+	y = uint(row) - 1
+
+	if x >= size || row == 0 || y >= size {
+		return 0, 0, fmt.Errorf("coordinate %q is out of board bounds", s)
+	}
+
+	return x, y, nil
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCoord(t *testing.T) {
+	tests := []struct {
+		s    string
+		size uint
+		x, y uint
+		err  string
+	}{
+		{"a1", 15, 0, 0, ""},
+		{"h8", 15, 7, 7, ""},
+		{"o15", 15, 14, 14, ""},
+		{"t20", 20, 19, 19, ""},
+		{"p1", 15, 0, 0, "out of board bounds"},
+		{"a16", 15, 0, 0, "out of board bounds"},
+		{"a0", 15, 0, 0, "out of board bounds"},
+		{"c4", 3, 0, 0, "out of board bounds"},
+		{"", 15, 0, 0, "invalid coordinate"},
+		{"a", 15, 0, 0, "invalid coordinate"},
+		{"11", 15, 0, 0, "invalid coordinate"},
+		{"A1", 15, 0, 0, "invalid coordinate"},
+		{"ab", 15, 0, 0, "invalid coordinate"},
+		{"a-1", 15, 0, 0, "invalid coordinate"},
+		{"a1x", 15, 0, 0, "invalid coordinate"},
+		{"a99999999999", 15, 0, 0, "invalid coordinate"},
+	}
+
+	for _, tt := range tests {
+		x, y, err := parseCoord(tt.s, tt.size)
+
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("parseCoord(%q, %d): %v", tt.s, tt.size, err)
+		case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+			t.Errorf("parseCoord(%q, %d) gave %d,%d, %v, want an error with %q", tt.s, tt.size, x, y, err, tt.err)
+		case tt.err == "" && (x != tt.x || y != tt.y):
+			t.Errorf("parseCoord(%q, %d) is %d,%d, want %d,%d", tt.s, tt.size, x, y, tt.x, tt.y)
+		}
+	}
+
+	for x := uint(0); x < 20; x++ {
+		for y := uint(0); y < 20; y++ {
+			if px, py, err := parseCoord(coordName(x, y), 20); err != nil || px != x || py != y {
+				t.Fatalf("%s parses to %d,%d, %v", coordName(x, y), px, py, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package tui
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+type keyKind uint
+
+const (
+	keyRune keyKind = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keySpace
+	keyBackspace
+	keyEscape
+	keyNewGame
+	keyQuit
+)
+
+type key struct {
+	kind keyKind
+	r    rune
+}
+
+func readKeys(r io.Reader, keys chan<- key) {
+	defer close(keys)
+
+	buf := make([]byte, 64)
+
+	for {
+		n, err := r.Read(buf)
+		if err != nil {
+			return
+		}
+
+		for _, k := range parseKeys(buf[:n]) {
+			keys <- k
+		}
+	}
+}
+
+func parseKeys(buf []byte) []key {
+	var res []key
+
+	for len(buf) > 0 {
+		switch b := buf[0]; {
+		case b == 0x1b:
+			if len(buf) >= 3 && (buf[1] == '[' || buf[1] == 'O') {
+				switch buf[2] {
+				case 'A':
+					res = append(res, key{kind: keyUp})
+				case 'B':
+					res = append(res, key{kind: keyDown})
+				case 'C':
+					res = append(res, key{kind: keyRight})
+				case 'D':
+					res = append(res, key{kind: keyLeft})
+				}
+
+				buf = buf[3:]
+				continue
+			}
+
+			res = append(res, key{kind: keyEscape})
+			buf = buf[1:]
+		case b == '\r' || b == '\n':
+			res = append(res, key{kind: keyEnter})
+			buf = buf[1:]
+		case b == ' ':
+			res = append(res, key{kind: keySpace})
+			buf = buf[1:]
+		case b == 0x7f || b == 0x08:
+			res = append(res, key{kind: keyBackspace})
+			buf = buf[1:]
+		case b == 0x0e:
+			res = append(res, key{kind: keyNewGame})
+			buf = buf[1:]
+		case b == 0x03 || b == 0x04 || b == 0x11:
+			res = append(res, key{kind: keyQuit})
+			buf = buf[1:]
+		case b < 0x20:
+			buf = buf[1:]
+		default:
+			r, size := utf8.DecodeRune(buf)
+			res = append(res, key{kind: keyRune, r: r})
+			buf = buf[size:]
+		}
+	}
+
+	return res
+}
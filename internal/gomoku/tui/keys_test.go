@@ -0,0 +1,35 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []key
+	}{
+		{"empty", "", nil},
+		{"runes", "h8", []key{{kind: keyRune, r: 'h'}, {kind: keyRune, r: '8'}}},
+		{"utf-8", "жa", []key{{kind: keyRune, r: 'ж'}, {kind: keyRune, r: 'a'}}},
+		{"arrows", "\x1b[A\x1b[B\x1b[C\x1b[D", []key{{kind: keyUp}, {kind: keyDown}, {kind: keyRight}, {kind: keyLeft}}},
+		{"application arrows", "\x1bOA\x1bOD", []key{{kind: keyUp}, {kind: keyLeft}}},
+		{"unknown sequence", "\x1b[Zx", []key{{kind: keyRune, r: 'x'}}},
+		{"lone escape", "\x1b", []key{{kind: keyEscape}}},
+		{"short sequence", "\x1b[", []key{{kind: keyEscape}, {kind: keyRune, r: '['}}},
+		{"enter", "\r\n", []key{{kind: keyEnter}, {kind: keyEnter}}},
+		{"space and backspace", " \x7f\x08", []key{{kind: keySpace}, {kind: keyBackspace}, {kind: keyBackspace}}},
+		{"controls", "\x0e\x03\x04\x11", []key{{kind: keyNewGame}, {kind: keyQuit}, {kind: keyQuit}, {kind: keyQuit}}},
+		{"ignored controls", "\x01\x1fa", []key{{kind: keyRune, r: 'a'}}},
+		{"invalid utf-8", "\xff", []key{{kind: keyRune, r: '�'}}},
+		{"truncated utf-8", "\xd0", []key{{kind: keyRune, r: '�'}}},
+	}
+
+	for _, tt := range tests {
+		if got := parseKeys([]byte(tt.in)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseKeys(%q) is %v, want %v", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
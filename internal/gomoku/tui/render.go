@@ -0,0 +1,198 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+)
+
+const (
+	reverse = "\x1b[7m"
+	bold    = "\x1b[1m"
+	reset   = "\x1b[0m"
+
+	clearScreen = "\x1b[H\x1b[2J"
+
+	helpLine = "arrows: move  enter/space: place  c7+enter: place at c7  ^N: %s  ^C: quit"
+)
+
+type layout uint
+
+const (
+	gridLayout layout = iota
+	compactLayout
+)
+
+func chooseLayout(size uint, width, height int) layout {
+	gridWidth := int(4*size + 4)
+	gridHeight := int(2*size + 2 + 4)
+
+	if width >= gridWidth && height >= gridHeight {
+		return gridLayout
+	}
+
+	return compactLayout
+}
+
+func fieldSymbol(ft game.FieldType, empty string) string {
+	switch ft {
+	case game.FirstPlayerField:
+		return "O"
+	case game.SecondPlayerField:
+		return "X"
+	}
+
+	return empty
+}
+
+func strikeCells(s game.Strike) map[[2]uint]bool {
+	cells := make(map[[2]uint]bool)
+
+	dx := sign(int(s.X1) - int(s.X0))
+	dy := sign(int(s.Y1) - int(s.Y0))
+
+	x, y := int(s.X0), int(s.Y0)
+
+	for {
+		cells[[2]uint{uint(x), uint(y)}] = true
+
+		if x == int(s.X1) && y == int(s.Y1) {
+			break
+		}
+
+		x += dx
+		y += dy
+	}
+
+	return cells
+}
+
+func sign(v int) int {
+	switch {
+	case v < 0:
+		return -1
+	case v > 0:
+		return 1
+	}
+
+	return 0
+}
+
+func (c *Client) renderBoard(sb *strings.Builder, l layout) {
+	size := c.size
+
+	var struck map[[2]uint]bool
+	if c.strike != nil {
+		struck = strikeCells(*c.strike)
+	}
+
+	cellWidth := 4
+	empty := " "
+
+	if l == compactLayout {
+		cellWidth = 2
+		empty = "·"
+	}
+
+	sb.WriteString(strings.Repeat(" ", 5))
+
+	for x := uint(0); x < size; x++ {
+		sb.WriteString(columnName(x))
+
+		if x != size-1 {
+			sb.WriteString(strings.Repeat(" ", cellWidth-1))
+		}
+	}
+
+	sb.WriteString("\r\n")
+
+	border := func(left, mid, right string) {
+		sb.WriteString("   ")
+		sb.WriteString(left)
+
+		for x := uint(0); x < size; x++ {
+			if l == gridLayout {
+				sb.WriteString("───")
+
+				if x != size-1 {
+					sb.WriteString(mid)
+				}
+			} else {
+				sb.WriteString("──")
+			}
+		}
+
+		if l == compactLayout {
+			sb.WriteString("─")
+		}
+
+		sb.WriteString(right)
+		sb.WriteString("\r\n")
+	}
+
+	border("┌", "┬", "┐")
+
+	for y := uint(0); y < size; y++ {
+		fmt.Fprintf(sb, "%2d │", y+1)
+
+		for x := uint(0); x < size; x++ {
+			sym := fieldSymbol(c.cells[x][y], empty)
+
+			if struck[[2]uint{x, y}] {
+				sym = bold + sym + reset
+			}
+
+			if x == c.cursorX && y == c.cursorY {
+				sym = reverse + sym + reset
+			}
+
+			if l == gridLayout {
+				sb.WriteString(" " + sym + " │")
+			} else {
+				sb.WriteString(" " + sym)
+			}
+		}
+
+		if l == compactLayout {
+			sb.WriteString(" │")
+		}
+
+		sb.WriteString("\r\n")
+
+		if l == gridLayout && y != size-1 {
+			border("├", "┼", "┤")
+		}
+	}
+
+	border("└", "┴", "┘")
+}
+
+func (c *Client) render(width, height int) string {
+	var sb strings.Builder
+
+	sb.WriteString(clearScreen)
+
+	if c.size != 0 {
+		c.renderBoard(&sb, chooseLayout(c.size, width, height))
+	}
+
+	sb.WriteString("\r\n")
+
+	if g := c.presenter.Game(); g != nil && g.State() == game.NotFinished {
+		ft := game.FieldType(g.CurrentPlayer()) + 1
+		fmt.Fprintf(&sb, "[%s] ", fieldSymbol(ft, " "))
+	}
+
+	fmt.Fprintf(&sb, "%s    Time: %s\r\n", c.status, c.clock)
+
+	if c.message != "" {
+		fmt.Fprintf(&sb, "%s\r\n", c.message)
+	} else {
+		fmt.Fprintf(&sb, "Move: %s\r\n", c.input)
+	}
+
+	fmt.Fprintf(&sb, helpLine, strings.ToLower(c.button))
+
+	return sb.String()
+}
@@ -0,0 +1,236 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/clock"
+	"github.com/infastin/gomoku2go/internal/gomoku/game"
+	"github.com/infastin/gomoku2go/internal/gomoku/presenter"
+)
+
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	leaveAltScreen = "\x1b[?25h\x1b[?1049l"
+
+	maxInputLen = 3
+)
+
+type Client struct {
+	in  *os.File
+	out *bufio.Writer
+
+	presenter *presenter.Presenter
+
+	size    uint
+	cells   [][]game.FieldType
+	strike  *game.Strike
+	cursorX uint
+	cursorY uint
+
+	input   string
+	status  string
+	button  string
+	message string
+	clock   *clock.Stopwatch
+}
+
+func NewClient(newGame func() (*game.Game, error)) *Client {
+	c := &Client{
+		in:    os.Stdin,
+		out:   bufio.NewWriter(os.Stdout),
+		clock: &clock.Stopwatch{},
+	}
+
+	c.presenter = presenter.New(c, newGame)
+
+	return c
+}
+
+func (c *Client) Run() error {
+	fd := int(c.in.Fd())
+
+	if !term.IsTerminal(fd) {
+		return fmt.Errorf("standard input is not a terminal")
+	}
+
+	if err := c.presenter.StartGame(); err != nil {
+		return err
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+
+	defer term.Restore(fd, state)
+
+	c.out.WriteString(enterAltScreen)
+	defer func() {
+		c.out.WriteString(leaveAltScreen)
+		c.out.Flush()
+	}()
+
+	keys := make(chan key)
+	go readKeys(c.in, keys)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		c.redraw()
+
+		select {
+		case k, ok := <-keys:
+			if !ok || k.kind == keyQuit {
+				return nil
+			}
+
+			c.handleKey(k)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) redraw() {
+	width, height, err := term.GetSize(int(c.in.Fd()))
+	if err != nil {
+		width, height = 80, 24
+	}
+
+	c.out.WriteString(c.render(width, height))
+	c.out.Flush()
+}
+
+func (c *Client) SetStatus(text string) {
+	c.status = text
+}
+
+func (c *Client) SetButtonLabel(label string) {
+	c.button = label
+}
+
+func (c *Client) InitBoard(size uint) {
+	c.size = size
+	c.cells = make([][]game.FieldType, size)
+	c.strike = nil
+
+	for i := range c.cells {
+		c.cells[i] = make([]game.FieldType, size)
+	}
+
+	c.cursorX = size / 2
+	c.cursorY = size / 2
+	c.input = ""
+}
+
+func (c *Client) SetInteractive(enabled bool) {}
+
+func (c *Client) DrawStone(x, y uint, ft game.FieldType) {
+	if x < c.size && y < c.size {
+		c.cells[x][y] = ft
+	}
+}
+
+func (c *Client) DrawStrike(s game.Strike) {
+	c.strike = &s
+}
+
+func (c *Client) StartClock() {
+	c.clock.Start()
+}
+
+func (c *Client) StopClock() {
+	c.clock.Stop()
+}
+
+func (c *Client) ResetClock() {
+	c.clock.Reset()
+}
+
+func (c *Client) SetClock(elapsed time.Duration) {
+	c.clock.Set(elapsed)
+}
+
+func (c *Client) AppendChat(t time.Time, from, text string) {}
+
+func (c *Client) ShowPlayerClocks(first, second time.Duration) {}
+
+func (c *Client) HidePlayerClocks() {}
+
+func (c *Client) ShowHints(hints []presenter.Hint) {}
+
+func (c *Client) ClearHints() {}
+
+func (c *Client) handleKey(k key) {
+	c.message = ""
+	size := c.size
+
+	switch k.kind {
+	case keyUp:
+		if c.cursorY > 0 {
+			c.cursorY--
+		}
+	case keyDown:
+		if c.cursorY < size-1 {
+			c.cursorY++
+		}
+	case keyLeft:
+		if c.cursorX > 0 {
+			c.cursorX--
+		}
+	case keyRight:
+		if c.cursorX < size-1 {
+			c.cursorX++
+		}
+	case keySpace:
+		c.handleMove(c.cursorX, c.cursorY)
+	case keyEnter:
+		if c.input == "" {
+			c.handleMove(c.cursorX, c.cursorY)
+			return
+		}
+
+		x, y, err := parseCoord(c.input, size)
+		c.input = ""
+
+		if err != nil {
+			c.message = fmt.Sprint("Error: ", err.Error())
+			return
+		}
+
+		c.cursorX, c.cursorY = x, y
+		c.handleMove(x, y)
+	case keyBackspace:
+		if len(c.input) > 0 {
+			c.input = c.input[:len(c.input)-1]
+		}
+	case keyEscape:
+		c.input = ""
+	case keyNewGame:
+		if err := c.presenter.StartGame(); err != nil {
+			c.message = fmt.Sprint("Error: ", err.Error())
+		}
+	case keyRune:
+		r := []rune(strings.ToLower(string(k.r)))[0]
+
+		if len(c.input) < maxInputLen && ((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')) {
+			c.input += string(r)
+		}
+	}
+}
+
+func (c *Client) handleMove(x, y uint) {
+	if err := c.presenter.Click(x, y); err != nil {
+		if c.presenter.Game().State() != game.NotFinished {
+			c.message = "The game is over, press ^N to start a new one"
+		} else {
+			c.message = fmt.Sprint("Error: ", err.Error())
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package view
+
+import (
+	"strings"
+
+	"github.com/diamondburned/gotk4/pkg/cairo"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+type AnalysisPane struct {
+	*gtk.Box
+
+	bar   *EvalBar
+	info  *gtk.Label
+	lines *gtk.Label
+	move  *gtk.Label
+}
+
+func newAnalysisPane(builder *gtk.Builder) *AnalysisPane {
+	pane := &AnalysisPane{}
+
+	pane.Box = builder.GetObject("analysis_pane").Cast().(*gtk.Box)
+	pane.info = builder.GetObject("analysis_info").Cast().(*gtk.Label)
+	pane.lines = builder.GetObject("analysis_lines").Cast().(*gtk.Label)
+	pane.move = builder.GetObject("history_move").Cast().(*gtk.Label)
+	pane.bar = newEvalBar(builder)
+
+	return pane
+}
+
+// SetActive shows or hides the pane together with the evaluation bar.
+func (pane *AnalysisPane) SetActive(active bool) {
+	pane.SetVisible(active)
+	pane.bar.SetVisible(active)
+}
+
+func (pane *AnalysisPane) Bar() *EvalBar {
+	return pane.bar
+}
+
+func (pane *AnalysisPane) SetInfo(text string) {
+	pane.info.SetText(text)
+}
+
+func (pane *AnalysisPane) SetLines(lines []string) {
+	pane.lines.SetText(strings.Join(lines, "\n\n"))
+}
+
+func (pane *AnalysisPane) SetMove(text string) {
+	pane.move.SetText(text)
+}
+
+func (pane *AnalysisPane) Clear() {
+	pane.info.SetText("")
+	pane.lines.SetText("")
+	pane.move.SetText("")
+	pane.bar.SetFraction(0.5)
+}
+
+// EvalBar shows how good the position is for each player: the part of the
+// bar filled from the bottom is the share of the first player.
+type EvalBar struct {
+	*gtk.DrawingArea
+
+	fraction float64
+}
+
+func newEvalBar(builder *gtk.Builder) *EvalBar {
+	bar := &EvalBar{fraction: 0.5}
+
+	bar.DrawingArea = builder.GetObject("eval_bar").Cast().(*gtk.DrawingArea)
+	bar.SetDrawFunc(bar.drawFunc)
+
+	return bar
+}
+
+func (bar *EvalBar) SetFraction(fraction float64) {
+	switch {
+	case fraction < 0:
+		fraction = 0
+	case fraction > 1:
+		fraction = 1
+	}
+
+	bar.fraction = fraction
+	bar.QueueDraw()
+}
+
+func (bar *EvalBar) drawFunc(_ *gtk.DrawingArea, cr *cairo.Context, width, height int) {
+	sctx := bar.StyleContext()
+	base, _ := sctx.LookupColor("theme_base_color")
+	fg, _ := sctx.LookupColor("theme_fg_color")
+
+	w := float64(width)
+	h := float64(height)
+	filled := h * bar.fraction
+
+	cr.SetSourceRGBA(float64(base.Red()),
+		float64(base.Green()),
+		float64(base.Blue()),
+		float64(base.Alpha()))
+
+	cr.Rectangle(0, 0, w, h-filled)
+	cr.Fill()
+
+	cr.SetSourceRGBA(float64(fg.Red()),
+		float64(fg.Green()),
+		float64(fg.Blue()),
+		float64(fg.Alpha()))
+
+	cr.Rectangle(0, h-filled, w, filled)
+	cr.Fill()
+
+	cr.Rectangle(0, 0, w, h)
+	cr.SetLineWidth(1)
+	cr.Stroke()
+}
@@ -31,20 +31,44 @@ type Strike struct {
 	X1, Y1 uint
 }
 
+// Hint is a suggested move drawn over the board until the hints are cleared.
+type Hint struct {
+	X, Y  uint
+	Label string
+}
+
+type Outcome uint
+
+const (
+	OutcomeWin Outcome = iota
+	OutcomeDraw
+	OutcomeLoss
+)
+
+// CellOutcome is the result of the game with perfect play after a move on
+// the cell, for the player making it.
+type CellOutcome struct {
+	X, Y    uint
+	Outcome Outcome
+}
+
 type BoardArea struct {
 	*gtk.DrawingArea
 
 	clickHandler  func(x, y uint)
 	redrawHandler func()
 
-	sneaky  *gtk.Button
-	surface *cairo.Surface
-	press   *gtk.GestureClick
-	cells   uint
+	sneaky    *gtk.Button
+	surface   *cairo.Surface
+	press     *gtk.GestureClick
+	cells     uint
+	clickable bool
+	hints     []Hint
+	outcomes  []CellOutcome
 }
 
 func newBoardArea(builder *gtk.Builder) *BoardArea {
-	board := &BoardArea{}
+	board := &BoardArea{clickable: true}
 
 	board.sneaky = builder.GetObject("sneaky").Cast().(*gtk.Button)
 	board.sneaky.GrabFocus()
@@ -83,7 +107,7 @@ func (board *BoardArea) onResize(_ *gtk.DrawingArea, width, height int) {
 func (board *BoardArea) onPress(nPress int, x, y float64) {
 	board.sneaky.GrabFocus()
 
-	if board.clickHandler == nil {
+	if board.clickHandler == nil || !board.clickable {
 		return
 	}
 
@@ -123,6 +147,132 @@ func (board *BoardArea) onPress(nPress int, x, y float64) {
 func (board *BoardArea) drawFunc(_ *gtk.DrawingArea, cr *cairo.Context, width, height int) {
 	cr.SetSourceSurface(board.surface, 0, 0)
 	cr.Paint()
+
+	if board.cells != 0 && len(board.outcomes) != 0 {
+		cr.Save()
+		board.drawOutcomes(cr, width, height)
+		cr.Restore()
+	}
+
+	if board.cells != 0 && len(board.hints) != 0 {
+		board.drawHints(cr, width, height)
+	}
+}
+
+// drawOutcomes draws a W, D or L letter on every cell with an outcome, in
+// the color of success, warning or error.
+func (board *BoardArea) drawOutcomes(cr *cairo.Context, width, height int) {
+	min := math.Min(float64(width), float64(height))
+	size := (float64(min) * 2) / 3
+
+	fcells := float64(board.cells)
+	csize := size / fcells
+
+	sctx := board.StyleContext()
+	win, _ := sctx.LookupColor("success_color")
+	draw, _ := sctx.LookupColor("warning_color")
+	loss, _ := sctx.LookupColor("error_color")
+
+	cr.Translate(float64(width)/2-(size/2), float64(height)/2-(size/2))
+	cr.SelectFontFace("sans-serif", cairo.FONT_SLANT_NORMAL, cairo.FONT_WEIGHT_BOLD)
+	cr.SetFontSize(csize / 3)
+
+	for _, o := range board.outcomes {
+		color, label := draw, "D"
+
+		switch o.Outcome {
+		case OutcomeWin:
+			color, label = win, "W"
+		case OutcomeLoss:
+			color, label = loss, "L"
+		}
+
+		cx := csize*float64(o.X) + csize/2
+		cy := csize*float64(o.Y) + csize/2
+		ext := cr.TextExtents(label)
+
+		cr.SetSourceRGBA(float64(color.Red()),
+			float64(color.Green()),
+			float64(color.Blue()),
+			float64(color.Alpha()))
+
+		cr.MoveTo(cx-ext.Width/2-ext.XBearing, cy-ext.Height/2-ext.YBearing)
+		cr.ShowText(label)
+	}
+}
+
+// drawHints draws the hints as translucent discs with their labels, the
+// first hint being the most visible.
+func (board *BoardArea) drawHints(cr *cairo.Context, width, height int) {
+	min := math.Min(float64(width), float64(height))
+	size := (float64(min) * 2) / 3
+
+	fcells := float64(board.cells)
+	csize := size / fcells
+
+	sctx := board.StyleContext()
+	sbg, _ := sctx.LookupColor("theme_selected_bg_color")
+	fg, _ := sctx.LookupColor("theme_fg_color")
+
+	cr.Translate(float64(width)/2-(size/2), float64(height)/2-(size/2))
+	cr.SelectFontFace("sans-serif", cairo.FONT_SLANT_NORMAL, cairo.FONT_WEIGHT_BOLD)
+	cr.SetFontSize(csize / 4)
+
+	for i, h := range board.hints {
+		cx := csize*float64(h.X) + csize/2
+		cy := csize*float64(h.Y) + csize/2
+		alpha := 0.6 - 0.4*float64(i)/float64(len(board.hints))
+
+		cr.SetSourceRGBA(float64(sbg.Red()),
+			float64(sbg.Green()),
+			float64(sbg.Blue()),
+			alpha)
+
+		cr.Arc(cx, cy, csize*2/5, 0, 2*math.Pi)
+		cr.Fill()
+
+		if h.Label == "" {
+			continue
+		}
+
+		ext := cr.TextExtents(h.Label)
+
+		cr.SetSourceRGBA(float64(fg.Red()),
+			float64(fg.Green()),
+			float64(fg.Blue()),
+			float64(fg.Alpha()))
+
+		cr.MoveTo(cx-ext.Width/2-ext.XBearing, cy-ext.Height/2-ext.YBearing)
+		cr.ShowText(h.Label)
+	}
+}
+
+func (board *BoardArea) SetHints(hints []Hint) {
+	board.hints = append([]Hint(nil), hints...)
+	board.QueueDraw()
+}
+
+func (board *BoardArea) ClearHints() {
+	if len(board.hints) == 0 {
+		return
+	}
+
+	board.hints = nil
+	board.QueueDraw()
+}
+
+func (board *BoardArea) SetOutcomes(outcomes []CellOutcome) {
+	board.outcomes = append([]CellOutcome(nil), outcomes...)
+	board.QueueDraw()
+}
+
+func (board *BoardArea) ClearOutcomes() {
+	if len(board.outcomes) == 0 {
+		return
+	}
+
+	board.outcomes = nil
+	board.QueueDraw()
 }
 
 func (board *BoardArea) paintBackground() {
@@ -390,6 +540,8 @@ func (board *BoardArea) Clear() {
 
 	board.RemoveController(board.press)
 	board.cells = 0
+	board.hints = nil
+	board.outcomes = nil
 
 	sctx := board.StyleContext()
 	bg, _ := sctx.LookupColor("theme_bg_color")
@@ -404,6 +556,10 @@ func (board *BoardArea) Clear() {
 	board.QueueDraw()
 }
 
+func (board *BoardArea) SetClickable(clickable bool) {
+	board.clickable = clickable
+}
+
 func (board *BoardArea) ConnectClick(handler func(x, y uint)) error {
 	if board.cells == 0 {
 		return fmt.Errorf("boardArea hasn't been initialized")
@@ -0,0 +1,72 @@
+package view
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+type ChatPane struct {
+	*gtk.Box
+
+	sendHandler func(text string)
+
+	view  *gtk.TextView
+	entry *gtk.Entry
+	send  *gtk.Button
+}
+
+func newChatPane(builder *gtk.Builder) *ChatPane {
+	chat := &ChatPane{}
+
+	chat.Box = builder.GetObject("chat_pane").Cast().(*gtk.Box)
+	chat.view = builder.GetObject("chat_view").Cast().(*gtk.TextView)
+	chat.entry = builder.GetObject("chat_entry").Cast().(*gtk.Entry)
+	chat.send = builder.GetObject("chat_send").Cast().(*gtk.Button)
+
+	chat.entry.ConnectActivate(chat.onSend)
+	chat.send.ConnectClicked(chat.onSend)
+
+	return chat
+}
+
+func (chat *ChatPane) onSend() {
+	text := strings.TrimSpace(chat.entry.Text())
+
+	if text == "" || chat.sendHandler == nil {
+		return
+	}
+
+	chat.sendHandler(text)
+}
+
+func (chat *ChatPane) Append(t time.Time, from, text string) {
+	buffer := chat.view.Buffer()
+	end := buffer.EndIter()
+
+	buffer.Insert(&end, fmt.Sprintf("[%s] %s: %s\n", t.Format("15:04"), from, text))
+
+	end = buffer.EndIter()
+	mark := buffer.CreateMark("", &end, false)
+	chat.view.ScrollMarkOnscreen(mark)
+}
+
+func (chat *ChatPane) Clear() {
+	chat.view.Buffer().SetText("", 0)
+	chat.entry.SetText("")
+}
+
+func (chat *ChatPane) ClearEntry() {
+	chat.entry.SetText("")
+}
+
+func (chat *ChatPane) SetSendable(sendable bool) {
+	chat.entry.SetSensitive(sendable)
+	chat.send.SetSensitive(sendable)
+}
+
+func (chat *ChatPane) ConnectSend(handler func(text string)) {
+	chat.sendHandler = handler
+}
@@ -0,0 +1,232 @@
+package view
+
+import (
+	_ "embed"
+	"math"
+
+	"github.com/diamondburned/gotk4/pkg/cairo"
+	"github.com/diamondburned/gotk4/pkg/gdk/v4"
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+//go:embed resources/graph.ui
+var graphui string
+
+const graphMargin = 12
+
+type GraphWindow struct {
+	*gtk.Window
+
+	graph   *EvalGraph
+	status  *gtk.Label
+	summary *gtk.Label
+}
+
+func NewGraphWindow(mwin *MainWindow) *GraphWindow {
+	win := &GraphWindow{}
+
+	builder := gtk.NewBuilderFromString(graphui, len(graphui))
+	win.Window = builder.GetObject("graph_window").Cast().(*gtk.Window)
+
+	win.SetTransientFor(&mwin.Window)
+
+	win.status = builder.GetObject("graph_status").Cast().(*gtk.Label)
+	win.summary = builder.GetObject("graph_summary").Cast().(*gtk.Label)
+	win.graph = newEvalGraph(builder)
+
+	return win
+}
+
+func (win *GraphWindow) Graph() *EvalGraph {
+	return win.graph
+}
+
+func (win *GraphWindow) StatusLabel() *gtk.Label {
+	return win.status
+}
+
+func (win *GraphWindow) SummaryLabel() *gtk.Label {
+	return win.summary
+}
+
+type GraphMarkKind uint
+
+const (
+	BlunderMark GraphMarkKind = iota
+	MissedWinMark
+)
+
+// GraphMark marks the position after a move.
+type GraphMark struct {
+	Move int
+	Kind GraphMarkKind
+}
+
+// EvalGraph plots the evaluation over the move number. Values are the shares
+// of the first player, from 0 to 1, of the positions after each number of
+// moves.
+type EvalGraph struct {
+	*gtk.DrawingArea
+
+	selectHandler func(n int)
+
+	press   *gtk.GestureClick
+	values  []float64
+	marks   []GraphMark
+	current int
+}
+
+func newEvalGraph(builder *gtk.Builder) *EvalGraph {
+	graph := &EvalGraph{current: -1}
+
+	graph.DrawingArea = builder.GetObject("graph").Cast().(*gtk.DrawingArea)
+	graph.SetDrawFunc(graph.drawFunc)
+
+	graph.press = gtk.NewGestureClick()
+	graph.press.SetButton(gdk.BUTTON_PRIMARY)
+	graph.press.ConnectPressed(graph.onPress)
+	graph.AddController(graph.press)
+
+	return graph
+}
+
+func (graph *EvalGraph) SetValues(values []float64) {
+	graph.values = append([]float64(nil), values...)
+	graph.QueueDraw()
+}
+
+func (graph *EvalGraph) SetMarks(marks []GraphMark) {
+	graph.marks = append([]GraphMark(nil), marks...)
+	graph.QueueDraw()
+}
+
+// SetCurrent highlights the position after n moves, or nothing if n is
+// negative.
+func (graph *EvalGraph) SetCurrent(n int) {
+	graph.current = n
+	graph.QueueDraw()
+}
+
+// ConnectSelect sets the handler called with the number of moves of the
+// position clicked on.
+func (graph *EvalGraph) ConnectSelect(handler func(n int)) {
+	graph.selectHandler = handler
+}
+
+func (graph *EvalGraph) point(i int, width, height float64) (x, y float64) {
+	w := width - 2*graphMargin
+	h := height - 2*graphMargin
+
+	x = graphMargin + w/2
+	if len(graph.values) > 1 {
+		x = graphMargin + w*float64(i)/float64(len(graph.values)-1)
+	}
+
+	y = graphMargin + h*(1-graph.values[i])
+
+	return x, y
+}
+
+func (graph *EvalGraph) onPress(nPress int, x, y float64) {
+	if graph.selectHandler == nil || len(graph.values) == 0 {
+		return
+	}
+
+	n := 0
+
+	if len(graph.values) > 1 {
+		w := float64(graph.Width()) - 2*graphMargin
+		step := w / float64(len(graph.values)-1)
+		n = int(math.Round((x - graphMargin) / step))
+	}
+
+	switch {
+	case n < 0:
+		n = 0
+	case n >= len(graph.values):
+		n = len(graph.values) - 1
+	}
+
+	graph.selectHandler(n)
+}
+
+func (graph *EvalGraph) drawFunc(_ *gtk.DrawingArea, cr *cairo.Context, width, height int) {
+	w := float64(width)
+	h := float64(height)
+
+	sctx := graph.StyleContext()
+	fg, _ := sctx.LookupColor("theme_fg_color")
+	sbg, _ := sctx.LookupColor("theme_selected_bg_color")
+	errc, _ := sctx.LookupColor("error_color")
+	warnc, _ := sctx.LookupColor("warning_color")
+
+	setColor := func(c gdk.RGBA, alpha float64) {
+		cr.SetSourceRGBA(float64(c.Red()), float64(c.Green()), float64(c.Blue()), alpha)
+	}
+
+	// The frame and the even line.
+	setColor(fg, 0.3)
+	cr.SetLineWidth(1)
+	cr.Rectangle(graphMargin, graphMargin, w-2*graphMargin, h-2*graphMargin)
+	cr.MoveTo(graphMargin, h/2)
+	cr.LineTo(w-graphMargin, h/2)
+	cr.Stroke()
+
+	if len(graph.values) == 0 {
+		return
+	}
+
+	if graph.current >= 0 && graph.current < len(graph.values) {
+		x, _ := graph.point(graph.current, w, h)
+
+		setColor(sbg, 1)
+		cr.SetLineWidth(2)
+		cr.MoveTo(x, graphMargin)
+		cr.LineTo(x, h-graphMargin)
+		cr.Stroke()
+	}
+
+	// The share of the first player is filled from the bottom, as on the
+	// evaluation bar.
+	x0, _ := graph.point(0, w, h)
+	cr.MoveTo(x0, h-graphMargin)
+
+	for i := range graph.values {
+		cr.LineTo(graph.point(i, w, h))
+	}
+
+	xn, _ := graph.point(len(graph.values)-1, w, h)
+	cr.LineTo(xn, h-graphMargin)
+	cr.ClosePath()
+
+	setColor(fg, 0.2)
+	cr.Fill()
+
+	cr.MoveTo(graph.point(0, w, h))
+
+	for i := range graph.values {
+		cr.LineTo(graph.point(i, w, h))
+	}
+
+	setColor(fg, 1)
+	cr.SetLineWidth(2)
+	cr.Stroke()
+
+	for _, m := range graph.marks {
+		if m.Move < 0 || m.Move >= len(graph.values) {
+			continue
+		}
+
+		x, y := graph.point(m.Move, w, h)
+
+		switch m.Kind {
+		case BlunderMark:
+			setColor(errc, 1)
+		case MissedWinMark:
+			setColor(warnc, 1)
+		}
+
+		cr.Arc(x, y, 5, 0, 2*math.Pi)
+		cr.Fill()
+	}
+}
@@ -0,0 +1,180 @@
+package view
+
+import (
+	_ "embed"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+//go:embed resources/host.ui
+var hostui string
+
+//go:embed resources/join.ui
+var joinui string
+
+//go:embed resources/lan.ui
+var lanui string
+
+type HostDialog struct {
+	*gtk.Dialog
+
+	cancel  *gtk.Button
+	confirm *gtk.Button
+	error   *gtk.Label
+	port    *gtk.SpinButton
+}
+
+func NewHostDialog(mwin *MainWindow) *HostDialog {
+	host := &HostDialog{}
+
+	builder := gtk.NewBuilderFromString(hostui, len(hostui))
+	host.Dialog = builder.GetObject("host").Cast().(*gtk.Dialog)
+
+	host.SetTransientFor(&mwin.Window)
+
+	host.cancel = builder.GetObject("cancel").Cast().(*gtk.Button)
+	host.confirm = builder.GetObject("confirm").Cast().(*gtk.Button)
+	host.error = builder.GetObject("error_label").Cast().(*gtk.Label)
+	host.port = builder.GetObject("port_sb").Cast().(*gtk.SpinButton)
+
+	return host
+}
+
+func (h *HostDialog) CancelButton() *gtk.Button {
+	return h.cancel
+}
+
+func (h *HostDialog) ConfirmButton() *gtk.Button {
+	return h.confirm
+}
+
+func (h *HostDialog) ErrorLabel() *gtk.Label {
+	return h.error
+}
+
+func (h *HostDialog) PortSpinButton() *gtk.SpinButton {
+	return h.port
+}
+
+type JoinDialog struct {
+	*gtk.Dialog
+
+	cancel  *gtk.Button
+	confirm *gtk.Button
+	error   *gtk.Label
+	address *gtk.Entry
+}
+
+func NewJoinDialog(mwin *MainWindow) *JoinDialog {
+	join := &JoinDialog{}
+
+	builder := gtk.NewBuilderFromString(joinui, len(joinui))
+	join.Dialog = builder.GetObject("join").Cast().(*gtk.Dialog)
+
+	join.SetTransientFor(&mwin.Window)
+
+	join.cancel = builder.GetObject("cancel").Cast().(*gtk.Button)
+	join.confirm = builder.GetObject("confirm").Cast().(*gtk.Button)
+	join.error = builder.GetObject("error_label").Cast().(*gtk.Label)
+	join.address = builder.GetObject("address_entry").Cast().(*gtk.Entry)
+
+	return join
+}
+
+func (j *JoinDialog) CancelButton() *gtk.Button {
+	return j.cancel
+}
+
+func (j *JoinDialog) ConfirmButton() *gtk.Button {
+	return j.confirm
+}
+
+func (j *JoinDialog) ErrorLabel() *gtk.Label {
+	return j.error
+}
+
+func (j *JoinDialog) AddressEntry() *gtk.Entry {
+	return j.address
+}
+
+type LANDialog struct {
+	*gtk.Dialog
+
+	cancel  *gtk.Button
+	watch   *gtk.Button
+	confirm *gtk.Button
+	error   *gtk.Label
+	games   *gtk.ListBox
+	rows    []*gtk.ListBoxRow
+}
+
+func NewLANDialog(mwin *MainWindow) *LANDialog {
+	lan := &LANDialog{}
+
+	builder := gtk.NewBuilderFromString(lanui, len(lanui))
+	lan.Dialog = builder.GetObject("lan").Cast().(*gtk.Dialog)
+
+	lan.SetTransientFor(&mwin.Window)
+
+	lan.cancel = builder.GetObject("cancel").Cast().(*gtk.Button)
+	lan.watch = builder.GetObject("watch").Cast().(*gtk.Button)
+	lan.confirm = builder.GetObject("confirm").Cast().(*gtk.Button)
+	lan.error = builder.GetObject("error_label").Cast().(*gtk.Label)
+	lan.games = builder.GetObject("games_list").Cast().(*gtk.ListBox)
+
+	return lan
+}
+
+func (l *LANDialog) CancelButton() *gtk.Button {
+	return l.cancel
+}
+
+func (l *LANDialog) WatchButton() *gtk.Button {
+	return l.watch
+}
+
+func (l *LANDialog) ConfirmButton() *gtk.Button {
+	return l.confirm
+}
+
+func (l *LANDialog) ErrorLabel() *gtk.Label {
+	return l.error
+}
+
+func (l *LANDialog) SetGames(games []string) {
+	selected := l.SelectedIndex()
+
+	for _, row := range l.rows {
+		l.games.Remove(row)
+	}
+
+	l.rows = l.rows[:0]
+
+	for _, g := range games {
+		label := gtk.NewLabel(g)
+		label.SetXAlign(0)
+		label.SetMarginStart(4)
+		label.SetMarginEnd(4)
+		label.SetMarginTop(4)
+		label.SetMarginBottom(4)
+
+		row := gtk.NewListBoxRow()
+		row.SetChild(label)
+
+		l.games.Append(row)
+		l.rows = append(l.rows, row)
+	}
+
+	if selected >= 0 && selected < len(l.rows) {
+		l.games.SelectRow(l.rows[selected])
+	}
+}
+
+func (l *LANDialog) SelectedIndex() int {
+	row := l.games.SelectedRow()
+	if row == nil {
+		return -1
+	}
+
+	return row.Index()
+}
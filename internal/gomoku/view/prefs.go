@@ -19,6 +19,12 @@ type PrefsDialog struct {
 	boardsize *gtk.SpinButton
 	p1        *gtk.Entry
 	p2        *gtk.Entry
+	p1level   *gtk.DropDown
+	p2level   *gtk.DropDown
+	usebook   *gtk.CheckButton
+	time      *gtk.SpinButton
+	increment *gtk.SpinButton
+	ponder    *gtk.CheckButton
 }
 
 func NewPrefsDialog(mwin *MainWindow) *PrefsDialog {
@@ -36,6 +42,12 @@ func NewPrefsDialog(mwin *MainWindow) *PrefsDialog {
 	prefs.boardsize = builder.GetObject("boardsize_sb").Cast().(*gtk.SpinButton)
 	prefs.p1 = builder.GetObject("player1_entry").Cast().(*gtk.Entry)
 	prefs.p2 = builder.GetObject("player2_entry").Cast().(*gtk.Entry)
+	prefs.p1level = builder.GetObject("player1_level").Cast().(*gtk.DropDown)
+	prefs.p2level = builder.GetObject("player2_level").Cast().(*gtk.DropDown)
+	prefs.usebook = builder.GetObject("use_book").Cast().(*gtk.CheckButton)
+	prefs.time = builder.GetObject("time_sb").Cast().(*gtk.SpinButton)
+	prefs.increment = builder.GetObject("increment_sb").Cast().(*gtk.SpinButton)
+	prefs.ponder = builder.GetObject("ponder").Cast().(*gtk.CheckButton)
 
 	return prefs
 }
@@ -67,3 +79,30 @@ func (p *PrefsDialog) FirstPlayerEntry() *gtk.Entry {
 func (p *PrefsDialog) SecondPlayerEntry() *gtk.Entry {
 	return p.p2
 }
+
+// FirstPlayerLevel selects between a human, the first item, and the
+// difficulty levels of the computer.
+func (p *PrefsDialog) FirstPlayerLevel() *gtk.DropDown {
+	return p.p1level
+}
+
+func (p *PrefsDialog) SecondPlayerLevel() *gtk.DropDown {
+	return p.p2level
+}
+
+func (p *PrefsDialog) UseBookCheckButton() *gtk.CheckButton {
+	return p.usebook
+}
+
+// TimeSpinButton sets the minutes each player has for a game.
+func (p *PrefsDialog) TimeSpinButton() *gtk.SpinButton {
+	return p.time
+}
+
+func (p *PrefsDialog) IncrementSpinButton() *gtk.SpinButton {
+	return p.increment
+}
+
+func (p *PrefsDialog) PonderCheckButton() *gtk.CheckButton {
+	return p.ponder
+}
@@ -0,0 +1,100 @@
+package view
+
+import (
+	_ "embed"
+
+	"github.com/diamondburned/gotk4/pkg/gtk/v4"
+)
+
+//go:embed resources/puzzles.ui
+var puzzlesui string
+
+type PuzzlesDialog struct {
+	*gtk.Dialog
+
+	cancel   *gtk.Button
+	confirm  *gtk.Button
+	error    *gtk.Label
+	progress *gtk.Label
+	puzzles  *gtk.ListBox
+	rows     []*gtk.ListBoxRow
+}
+
+func NewPuzzlesDialog(mwin *MainWindow) *PuzzlesDialog {
+	dialog := &PuzzlesDialog{}
+
+	builder := gtk.NewBuilderFromString(puzzlesui, len(puzzlesui))
+	dialog.Dialog = builder.GetObject("puzzles").Cast().(*gtk.Dialog)
+
+	dialog.SetTransientFor(&mwin.Window)
+
+	dialog.cancel = builder.GetObject("cancel").Cast().(*gtk.Button)
+	dialog.confirm = builder.GetObject("confirm").Cast().(*gtk.Button)
+	dialog.error = builder.GetObject("error_label").Cast().(*gtk.Label)
+	dialog.progress = builder.GetObject("progress_label").Cast().(*gtk.Label)
+	dialog.puzzles = builder.GetObject("puzzles_list").Cast().(*gtk.ListBox)
+
+	return dialog
+}
+
+func (d *PuzzlesDialog) CancelButton() *gtk.Button {
+	return d.cancel
+}
+
+func (d *PuzzlesDialog) ConfirmButton() *gtk.Button {
+	return d.confirm
+}
+
+func (d *PuzzlesDialog) ErrorLabel() *gtk.Label {
+	return d.error
+}
+
+func (d *PuzzlesDialog) ProgressLabel() *gtk.Label {
+	return d.progress
+}
+
+// SetPuzzles fills the list with the puzzles and selects the first one not
+// solved yet.
+func (d *PuzzlesDialog) SetPuzzles(puzzles []string, solved []bool) {
+	for _, row := range d.rows {
+		d.puzzles.Remove(row)
+	}
+
+	d.rows = d.rows[:0]
+	selected := -1
+
+	for i, p := range puzzles {
+		mark := "  "
+		if solved[i] {
+			mark = "✓ "
+		} else if selected < 0 {
+			selected = i
+		}
+
+		label := gtk.NewLabel(mark + p)
+		label.SetXAlign(0)
+		label.SetMarginStart(4)
+		label.SetMarginEnd(4)
+		label.SetMarginTop(4)
+		label.SetMarginBottom(4)
+
+		row := gtk.NewListBoxRow()
+		row.SetChild(label)
+
+		d.puzzles.Append(row)
+		d.rows = append(d.rows, row)
+	}
+
+	if selected >= 0 {
+		d.puzzles.SelectRow(d.rows[selected])
+	}
+}
+
+func (d *PuzzlesDialog) SelectedIndex() int {
+	row := d.puzzles.SelectedRow()
+	if row == nil {
+		return -1
+	}
+
+	return row.Index()
+}
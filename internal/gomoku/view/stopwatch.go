@@ -71,6 +71,12 @@ func (s *Stopwatch) Reset() {
 	s.SetText(fmt.Sprint(s))
 }
 
+func (s *Stopwatch) Set(elapsed time.Duration) {
+	s.Stop()
+	s.seconds = time.Unix(0, 0).Add(elapsed.Truncate(time.Second))
+	s.SetText(fmt.Sprint(s))
+}
+
 func (s *Stopwatch) Stop() {
 	if s.state == running {
 		s.state = stopped
@@ -26,6 +26,9 @@ type MainWindow struct {
 	startGame      *gtk.Button
 	curPlayerLabel *gtk.Label
 	stopwatch      *Stopwatch
+	playerClocks   *gtk.Label
+	chat           *ChatPane
+	analysis       *AnalysisPane
 }
 
 type NeedRedraw struct{}
@@ -51,8 +54,11 @@ func NewMainWindow(app *gtk.Application) *MainWindow {
 	mwin.curPlayerLabel = builder.GetObject("current_player").Cast().(*gtk.Label)
 	stopwatch := builder.GetObject("stopwatch").Cast().(*gtk.Label)
 	mwin.stopwatch = NewStopwatch(stopwatch)
+	mwin.playerClocks = builder.GetObject("player_clocks").Cast().(*gtk.Label)
 
 	mwin.board = newBoardArea(builder)
+	mwin.chat = newChatPane(builder)
+	mwin.analysis = newAnalysisPane(builder)
 
 	css := gtk.NewCSSProvider()
 	css.LoadFromData(style)
@@ -77,6 +83,19 @@ func (mwin *MainWindow) Stopwatch() *Stopwatch {
 	return mwin.stopwatch
 }
 
+// PlayerClocksLabel shows the time left to the players of a timed game.
+func (mwin *MainWindow) PlayerClocksLabel() *gtk.Label {
+	return mwin.playerClocks
+}
+
 func (mwin *MainWindow) StartGameBtn() *gtk.Button {
 	return mwin.startGame
 }
+
+func (mwin *MainWindow) Chat() *ChatPane {
+	return mwin.chat
+}
+
+func (mwin *MainWindow) Analysis() *AnalysisPane {
+	return mwin.analysis
+}
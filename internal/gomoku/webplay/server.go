@@ -0,0 +1,136 @@
+// Package webplay serves a browser client for gomoku2go games.
+//
+// The server hands out a single page at "/" and accepts WebSocket
+// connections at "/ws". Every WebSocket connection is passed to a netplay
+// host as is, so the page speaks the regular netplay protocol: one JSON
+// message per text frame, each terminated by a newline. Opening the page
+// with "?watch" joins the game as a spectator.
+package webplay
+
+import (
+	_ "embed"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/infastin/gomoku2go/internal/gomoku/netplay"
+)
+
+const (
+	DefaultPort = 8080
+)
+
+//go:embed resources/index.html
+var indexPage []byte
+
+type Server struct {
+	addr string
+	peer *netplay.Peer
+
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+}
+
+func NewServer(addr string, peer *netplay.Peer) *Server {
+	return &Server{
+		addr: addr,
+		peer: peer,
+	}
+}
+
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+
+	return s.listener.Addr()
+}
+
+func (s *Server) Start() error {
+	if s.peer.Role() != netplay.HostRole {
+		return fmt.Errorf("the web server needs a hosted game")
+	}
+
+	ln, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.serveIndex)
+	mux.Handle("/ws", websocket.Server{
+		Handshake: checkOrigin,
+		Handler:   s.serveWebSocket,
+	})
+
+	server := &http.Server{Handler: mux}
+
+	s.mu.Lock()
+	s.server = server
+	s.listener = ln
+	s.mu.Unlock()
+
+	go server.Serve(ln)
+
+	return nil
+}
+
+func (s *Server) Stop() {
+	s.mu.Lock()
+	server := s.server
+	s.server = nil
+	s.mu.Unlock()
+
+	if server == nil {
+		return
+	}
+
+	// Hijacked WebSocket connections are not tracked by the HTTP server,
+	// they are closed by the peer.
+	s.peer.Close()
+	server.Close()
+}
+
+func (s *Server) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Write(indexPage)
+}
+
+func (s *Server) serveWebSocket(ws *websocket.Conn) {
+	ws.PayloadType = websocket.TextFrame
+	s.peer.Accept(ws)
+}
+
+func checkOrigin(config *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil {
+		return err
+	}
+
+	if u.Host != r.Host {
+		return fmt.Errorf("cross-origin connections are not allowed")
+	}
+
+	config.Origin = u
+
+	return nil
+}